@@ -24,3 +24,10 @@ func TestBenchmarkCollector(t *testing.T) {
 	}
 	suite.Run(t, new(suites.BenchmarkCollectorTestSuite))
 }
+
+func TestSoak(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Not running Benchmarks in short mode")
+	}
+	suite.Run(t, new(suites.SoakTestSuite))
+}
@@ -0,0 +1,33 @@
+// Starts the program given as second argument, with the remaining arguments,
+// using the mechanism given as first argument: "fork" (fork+exec) or "spawn"
+// (posix_spawn). Exits with the status of the program.
+#include <spawn.h>
+#include <string.h>
+#include <sys/wait.h>
+#include <unistd.h>
+
+extern char** environ;
+
+int main(int argc, char** argv) {
+  pid_t pid;
+  int status;
+
+  if (argc < 3) {
+    return 2;
+  }
+
+  if (strcmp(argv[1], "spawn") == 0) {
+    if (posix_spawn(&pid, argv[2], NULL, NULL, &argv[2], environ) != 0) {
+      return 1;
+    }
+  } else {
+    pid = fork();
+    if (pid == 0) {
+      execv(argv[2], &argv[2]);
+      _exit(127);
+    }
+  }
+
+  waitpid(pid, &status, 0);
+  return WEXITSTATUS(status);
+}
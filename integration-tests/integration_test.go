@@ -26,6 +26,14 @@ func TestMissingProcScrape(t *testing.T) {
 	}
 }
 
+// TestProcSnapshotScrape generates its snapshot locally, so it only works
+// when the containers run on the local host
+func TestProcSnapshotScrape(t *testing.T) {
+	if !config.HostInfo().IsK8s() {
+		suite.Run(t, new(suites.ProcSnapshotScrapeTestSuite))
+	}
+}
+
 func TestRepeatedNetworkFlow(t *testing.T) {
 	// Perform 11 curl commands with a 2 second sleep between each curl command.
 	// The scrapeInterval is increased to 4 seconds to reduce the chance that jiter will effect the results.
@@ -356,17 +364,15 @@ func TestConnectionsAndEndpointsUDPNormal(t *testing.T) {
 		Server: suites.Container{
 			Name: "socat-server-udp",
 			Cmd:  "socat UDP-LISTEN:53,reuseaddr,fork - &",
-			// TODO UDP connections are not always reported on the server side
-			ExpectedNetwork: nil,
-			// ExpectedNetwork: []types.NetworkInfo{
-			// 	{
-			// 		LocalAddress:   ":53",
-			// 		RemoteAddress:  "CLIENT_IP",
-			// 		Role:           "ROLE_SERVER",
-			// 		SocketFamily:   "SOCKET_FAMILY_UNKNOWN",
-			// 		CloseTimestamp: types.NilTimestamp,
-			// 	},
-			// },
+			ExpectedNetwork: []types.NetworkInfo{
+				{
+					LocalAddress:   ":53",
+					RemoteAddress:  "CLIENT_IP",
+					Role:           "ROLE_SERVER",
+					SocketFamily:   "SOCKET_FAMILY_UNKNOWN",
+					CloseTimestamp: types.NilTimestamp,
+				},
+			},
 			// TODO UDP listening endpoints should be reported
 			ExpectedEndpoints: nil,
 		},
@@ -394,17 +400,15 @@ func TestConnectionsAndEndpointsUDPNoReuseaddr(t *testing.T) {
 		Server: suites.Container{
 			Name: "socat-server-udp",
 			Cmd:  "socat UDP-LISTEN:53,fork - &",
-			// TODO UDP connections are not always reported on the server side
-			ExpectedNetwork: nil,
-			// ExpectedNetwork: []types.NetworkInfo{
-			// 	{
-			// 		LocalAddress:   ":53",
-			// 		RemoteAddress:  "CLIENT_IP",
-			// 		Role:           "ROLE_SERVER",
-			// 		SocketFamily:   "SOCKET_FAMILY_UNKNOWN",
-			// 		CloseTimestamp: types.NilTimestamp,
-			// 	},
-			// },
+			ExpectedNetwork: []types.NetworkInfo{
+				{
+					LocalAddress:   ":53",
+					RemoteAddress:  "CLIENT_IP",
+					Role:           "ROLE_SERVER",
+					SocketFamily:   "SOCKET_FAMILY_UNKNOWN",
+					CloseTimestamp: types.NilTimestamp,
+				},
+			},
 			// TODO UDP listening endpoints should be reported
 			ExpectedEndpoints: nil,
 		},
@@ -432,17 +436,15 @@ func TestConnectionsAndEndpointsUDPNoFork(t *testing.T) {
 		Server: suites.Container{
 			Name: "socat-server-udp",
 			Cmd:  "socat UDP-LISTEN:53 - &",
-			// TODO UDP connections are not always reported on the server side
-			ExpectedNetwork: nil,
-			// ExpectedNetwork: []types.NetworkInfo{
-			// 	{
-			// 		LocalAddress:   ":53",
-			// 		RemoteAddress:  "CLIENT_IP",
-			// 		Role:           "ROLE_SERVER",
-			// 		SocketFamily:   "SOCKET_FAMILY_UNKNOWN",
-			// 		CloseTimestamp: types.NilTimestamp,
-			// 	},
-			// },
+			ExpectedNetwork: []types.NetworkInfo{
+				{
+					LocalAddress:   ":53",
+					RemoteAddress:  "CLIENT_IP",
+					Role:           "ROLE_SERVER",
+					SocketFamily:   "SOCKET_FAMILY_UNKNOWN",
+					CloseTimestamp: types.NilTimestamp,
+				},
+			},
 			// TODO UDP listening endpoints should be reported
 			ExpectedEndpoints: nil,
 		},
@@ -530,3 +532,180 @@ func TestPerfEvent(t *testing.T) {
 func TestGperftools(t *testing.T) {
 	suite.Run(t, new(suites.GperftoolsTestSuite))
 }
+
+func TestPidReuse(t *testing.T) {
+	suite.Run(t, new(suites.PidReuseTestSuite))
+}
+
+func TestSensorFaults(t *testing.T) {
+	suite.Run(t, new(suites.SensorFaultsTestSuite))
+}
+
+func TestDualStackEndpoints(t *testing.T) {
+	suite.Run(t, new(suites.DualStackEndpointsTestSuite))
+}
+
+func TestSeccomp(t *testing.T) {
+	suite.Run(t, new(suites.SeccompTestSuite))
+}
+
+func TestUdpNetworkFlow(t *testing.T) {
+	suite.Run(t, new(suites.UdpNetworkFlow))
+}
+
+func TestScrapeInterval(t *testing.T) {
+	suite.Run(t, new(suites.ScrapeIntervalTestSuite))
+}
+
+func TestLongCommandLine(t *testing.T) {
+	suite.Run(t, new(suites.LongCommandLineTestSuite))
+}
+
+func TestEndpointRestart(t *testing.T) {
+	suite.Run(t, new(suites.EndpointRestartTestSuite))
+}
+
+func TestEndpointRebind(t *testing.T) {
+	suite.Run(t, &suites.EndpointRebindTestSuite{Rebinds: 10})
+}
+
+func TestExecMechanisms(t *testing.T) {
+	suite.Run(t, new(suites.ExecMechanismsTestSuite))
+}
+
+func TestHostEtcMissingHostname(t *testing.T) {
+	if !config.HostInfo().IsK8s() {
+		suite.Run(t, &suites.HostEtcTestSuite{
+			Remove: []string{"hostname"},
+			ExpectedLogs: []string{
+				"/host/etc/hostname file not found",
+				"Found hostname in /proc/sys/kernel/hostname",
+			},
+		})
+	}
+}
+
+func TestHostEtcCorruptOsRelease(t *testing.T) {
+	if !config.HostInfo().IsK8s() {
+		// an unparsable os-release is read as empty, which collector
+		// does not log, unlike a missing one
+		suite.Run(t, &suites.HostEtcTestSuite{
+			Replace: map[string]string{"os-release": "\x00\x01not=an os-release\n\"\n"},
+		})
+	}
+}
+
+func TestHostEtcMissingOsRelease(t *testing.T) {
+	if !config.HostInfo().IsK8s() {
+		suite.Run(t, &suites.HostEtcTestSuite{
+			Remove:     []string{"os-release"},
+			HideUsrLib: true,
+			ExpectedLogs: []string{
+				"Failed to open os-release file, unable to resolve OS information.",
+			},
+		})
+	}
+}
+
+func TestProxyHop(t *testing.T) {
+	suite.Run(t, new(suites.ProxyHopTestSuite))
+}
+
+func TestLoopback(t *testing.T) {
+	suite.Run(t, new(suites.LoopbackTestSuite))
+}
+
+func TestStaticIP(t *testing.T) {
+	if !config.HostInfo().IsK8s() {
+		suite.Run(t, new(suites.StaticIPTestSuite))
+	}
+}
+
+func TestDNS(t *testing.T) {
+	suite.Run(t, new(suites.DNSTestSuite))
+}
+
+func TestHostNetwork(t *testing.T) {
+	if !config.HostInfo().IsK8s() {
+		suite.Run(t, new(suites.HostNetworkTestSuite))
+	}
+}
+
+func TestReplicaScale(t *testing.T) {
+	suite.Run(t, &suites.ReplicaScaleTestSuite{Replicas: 20})
+}
+
+func TestEffectiveConfig(t *testing.T) {
+	suite.Run(t, new(suites.EffectiveConfigTestSuite))
+}
+
+func TestCollectorFlags(t *testing.T) {
+	suite.Run(t, new(suites.CollectorFlagsTestSuite))
+}
+
+func TestBurstConnections(t *testing.T) {
+	burstTestSuite := &suites.BurstConnectionsTestSuite{
+		Connections:      100,
+		MinReportedRatio: 0.9,
+		AfterglowPeriod:  10,
+	}
+	suite.Run(t, burstTestSuite)
+}
+
+func TestSelfExclusion(t *testing.T) {
+	suite.Run(t, new(suites.SelfExclusionTestSuite))
+}
+
+func TestStopSignal(t *testing.T) {
+	suite.Run(t, new(suites.StopSignalTestSuite))
+}
+
+func TestExecutorReconnect(t *testing.T) {
+	suite.Run(t, new(suites.ExecutorReconnectTestSuite))
+}
+
+func TestCgroupParent(t *testing.T) {
+	if !config.HostInfo().IsK8s() {
+		suite.Run(t, &suites.CgroupParentTestSuite{
+			CgroupParent: "collector-tests.slice",
+		})
+	}
+}
+
+func TestProcessStartTime(t *testing.T) {
+	suite.Run(t, new(suites.ProcessStartTimeTestSuite))
+}
+
+func TestAfterglowDedup(t *testing.T) {
+	suite.Run(t, &suites.AfterglowDedupTestSuite{
+		AfterglowPeriod: 20,
+		Window:          10,
+	})
+}
+
+func TestCollectionMethodEquivalence(t *testing.T) {
+	if !config.HostInfo().IsK8s() {
+		suite.Run(t, &suites.CollectionMethodEquivalenceTestSuite{
+			Methods: []string{config.CollectionMethodEBPF, config.CollectionMethodCoreBPF},
+		})
+	}
+}
+
+func TestContainerChurn(t *testing.T) {
+	if !config.HostInfo().IsK8s() {
+		suite.Run(t, &suites.ContainerChurnTestSuite{
+			Count:    100,
+			Lifetime: 5,
+		})
+	}
+}
+
+func TestExecChainOriginator(t *testing.T) {
+	suite.Run(t, new(suites.ExecChainOriginatorTestSuite))
+}
+
+func TestDebugfsUnavailable(t *testing.T) {
+	if !config.HostInfo().IsK8s() {
+		suite.Run(t, new(suites.DebugfsUnavailableTestSuite))
+	}
+}
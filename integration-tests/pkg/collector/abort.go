@@ -0,0 +1,43 @@
+package collector
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AssertionFailureError is returned on teardown when collector aborted
+// (SIGABRT), which is how its assertions fail. It is distinct from other
+// non-zero exits, as it means collector caught a bug in itself, rather than
+// crashed on one.
+type AssertionFailureError struct {
+	ExitCode int
+	// LogWindow is the tail of collector's logs leading to the abort, which
+	// should include the failed assertion
+	LogWindow string
+	// CoreDump is where the information about the core dump was saved, or
+	// empty if none was found
+	CoreDump string
+}
+
+func (e *AssertionFailureError) Error() string {
+	msg := fmt.Sprintf("Collector aborted on an assertion failure (exit code %d)", e.ExitCode)
+	if e.CoreDump != "" {
+		msg += ", core dump information saved to " + e.CoreDump
+	}
+	return msg + "\nLast collector logs:\n" + e.LogWindow
+}
+
+// isAssertionFailure returns whether collector was terminated by the given
+// signal because of a failed assertion.
+func isAssertionFailure(signal string) bool {
+	return signal == "SIGABRT"
+}
+
+// logWindow returns the last n lines of the logs.
+func logWindow(logs string, n int) string {
+	lines := strings.Split(strings.TrimRight(logs, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,20 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogWindow(t *testing.T) {
+	logs := "starting\nloading probes\nAssertion `fd >= 0' failed.\n"
+
+	assert.Equal(t, "loading probes\nAssertion `fd >= 0' failed.", logWindow(logs, 2))
+	assert.Equal(t, "starting\nloading probes\nAssertion `fd >= 0' failed.", logWindow(logs, 10))
+}
+
+func TestIsAssertionFailure(t *testing.T) {
+	assert.True(t, isAssertionFailure("SIGABRT"))
+	assert.False(t, isAssertionFailure("SIGSEGV"))
+	assert.False(t, isAssertionFailure(""))
+}
@@ -0,0 +1,29 @@
+package collector
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// collector logs its configuration on startup, including
+// e.g. "collection_method:CORE_BPF"
+var configuredMethodPattern = regexp.MustCompile(`collection_method:\s*([A-Za-z_-]+)`)
+
+// parseCollectionMethod returns the collection method collector logged in
+// its configuration, in the same format as config.CollectionMethod()
+// (e.g. core-bpf). This is the configured method, unless collector did not
+// recognize it and used its default instead.
+func parseCollectionMethod(logs string) (string, error) {
+	method := ""
+	for _, line := range strings.Split(logs, "\n") {
+		if match := configuredMethodPattern.FindStringSubmatch(line); match != nil {
+			method = match[1]
+		}
+	}
+
+	if method == "" {
+		return "", fmt.Errorf("collection method not found in collector logs")
+	}
+	return strings.ReplaceAll(strings.ToLower(method), "_", "-"), nil
+}
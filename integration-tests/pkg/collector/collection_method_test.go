@@ -0,0 +1,38 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCollectionMethod(t *testing.T) {
+	tests := []struct {
+		name     string
+		logs     string
+		expected string
+	}{
+		{
+			name:     "configured method",
+			logs:     "[INFO] Starting\n[INFO] Collector config: collection_method:CORE_BPF, scrape_interval:2\n",
+			expected: "core-bpf",
+		},
+		{
+			name: "invalid method",
+			logs: "[WARNING] Invalid collection-method (kernel-module), using CO-RE BPF\n" +
+				"[INFO] Collector config: collection_method:CORE_BPF, scrape_interval:2\n",
+			expected: "core-bpf",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method, err := parseCollectionMethod(tt.logs)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, method)
+		})
+	}
+
+	_, err := parseCollectionMethod("[INFO] Starting\n")
+	assert.Error(t, err)
+}
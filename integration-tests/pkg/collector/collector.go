@@ -1,7 +1,21 @@
 package collector
 
 import (
+	"time"
+
 	"github.com/stackrox/collector/integration-tests/pkg/executor"
+	"github.com/stackrox/collector/integration-tests/pkg/log"
+)
+
+var logger = log.New("collector")
+
+const (
+	// HostMountPrefix is where the host filesystems are mounted in the
+	// collector container.
+	HostMountPrefix = "/host/"
+	// HostMountPropagation is the propagation mode of the host mounts,
+	// the equivalent of K8s' MountPropagationHostToContainer.
+	HostMountPropagation = "rslave"
 )
 
 type StartupOptions struct {
@@ -9,6 +23,40 @@ type StartupOptions struct {
 	Env           map[string]string
 	Config        map[string]any
 	BootstrapOnly bool
+	// ArtifactMount is a path in the collector container (e.g. /var/log/collector)
+	// backed by a writable temporary directory on the host. Anything collector
+	// writes there is copied into the test's artifacts on teardown.
+	ArtifactMount string
+	// CollectorWrapper runs collector under a diagnostic tool (WrapperGDB or
+	// WrapperStrace) to capture a backtrace or syscall trace on crash. The
+	// output is written to the artifact mount, which defaults to
+	// /var/log/collector-wrapper if not set. The wrapper runs any
+	// configured COLLECTOR_PRE_ARGUMENTS along with collector.
+	CollectorWrapper string
+	// PullPolicy governs the pull of the collector image. It defaults to
+	// the configured policy, which also applies to the workload images.
+	PullPolicy executor.PullPolicy
+	// WithoutDebugfs launches collector without /sys/kernel/debug mounted,
+	// as on locked-down hosts where debugfs is unavailable.
+	WithoutDebugfs bool
+	// CollectorEnvFile is a file of KEY=VALUE lines (see loadEnvFile) with
+	// collector environment variables, e.g. captured from a failing run to
+	// replay it. Variables set in Env take precedence over the file.
+	CollectorEnvFile string
+	// HostEtcRemove and HostEtcReplace alter collector's view of the
+	// host's /etc, by removing files from it, or replacing their content,
+	// e.g. to check how collector handles missing or corrupt host files.
+	// Paths are relative to /etc.
+	HostEtcRemove  []string
+	HostEtcReplace map[string]string
+	// CollectorFlags are passed to collector as --key=value flags on its
+	// command line, e.g. "collector-config". Any flag replaces the image's
+	// default command, so collector no longer reads COLLECTOR_CONFIG,
+	// COLLECTION_METHOD and GRPC_SERVER from the environment: the command
+	// line is built from them instead (see collectorCommand), and a flag
+	// replaces the whole value it is built from. A "collector-config" flag
+	// is not merged with Config, whose keys are dropped.
+	CollectorFlags map[string]string
 }
 
 type Manager interface {
@@ -18,6 +66,14 @@ type Manager interface {
 	IsRunning() (bool, error)
 	ContainerID() string
 	TestName() string
+	Config() map[string]any
+	CollectorProcessStats() (ProcStats, error)
+	ValidateCollectorConfig() error
+	ActualCollectionMethod() (string, error)
+	CollectorProbeInfo() (ProbeInfo, error)
+	EffectiveCollectorConfig() (map[string]any, error)
+	WaitForHealthy(timeout time.Duration) error
+	WaitForInitialScrape(reported func() bool, timeout time.Duration) error
 }
 
 func New(e executor.Executor, name string) Manager {
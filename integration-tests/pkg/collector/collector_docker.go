@@ -3,7 +3,10 @@ package collector
 import (
 	"encoding/json"
 	"fmt"
+	"os"
+	"path"
 	"strings"
+	"time"
 
 	"golang.org/x/exp/maps"
 
@@ -13,16 +16,28 @@ import (
 	"github.com/stackrox/collector/integration-tests/pkg/executor"
 )
 
+// debugfsMount is the mount of the host's debugfs in the collector
+// container, used by eBPF.
+const debugfsMount = "/host/sys/kernel/debug:ro"
+
 type DockerCollectorManager struct {
 	executor      executor.Executor
 	mounts        map[string]string
 	env           map[string]string
 	config        map[string]any
+	flags         map[string]string
 	bootstrapOnly bool
 	testName      string
+	artifactDir   string
+	// hostEtcDir is the altered copy of the host's /etc, if any
+	hostEtcDir string
+	// hostPropagation is whether the host mounts can be given
+	// HostMountPropagation, see PropagationReachesContainers
+	hostPropagation bool
 
 	CollectorOutput string
 	containerID     string
+	startTime       time.Time
 }
 
 func newDockerManager(e executor.Executor, name string) *DockerCollectorManager {
@@ -44,11 +59,11 @@ func newDockerManager(e executor.Executor, name string) *DockerCollectorManager
 	}
 
 	mounts := map[string]string{
-		"/host/proc:ro":             "/proc",
-		"/host/etc:ro":              "/etc",
-		"/host/usr/lib:ro":          "/usr/lib",
-		"/host/sys/kernel/debug:ro": "/sys/kernel/debug",
-		"/tmp":                      "/tmp",
+		"/host/proc:ro":    "/proc",
+		hostEtcMount:       "/etc",
+		"/host/usr/lib:ro": "/usr/lib",
+		debugfsMount:       "/sys/kernel/debug",
+		"/tmp":             "/tmp",
 	}
 
 	return &DockerCollectorManager{
@@ -68,19 +83,77 @@ func (c *DockerCollectorManager) Setup(options *StartupOptions) error {
 		options = &StartupOptions{}
 	}
 
-	if options.Env != nil {
-		maps.Copy(c.env, options.Env)
+	env, err := options.environment()
+	if err != nil {
+		return err
 	}
+	maps.Copy(c.env, env)
 
 	if options.Mounts != nil {
 		maps.Copy(c.mounts, options.Mounts)
 	}
 
+	if options.WithoutDebugfs {
+		delete(c.mounts, debugfsMount)
+	}
+
+	if len(options.HostEtcRemove) > 0 || len(options.HostEtcReplace) > 0 {
+		c.hostEtcDir, err = fakeHostEtc(c.executor, options.HostEtcRemove, options.HostEtcReplace)
+		if err != nil {
+			return fmt.Errorf("failed to prepare host /etc: %w", err)
+		}
+		c.mounts[hostEtcMount] = c.hostEtcDir
+	}
+
 	if options.Config != nil {
 		maps.Copy(c.config, options.Config)
 	}
 
-	return c.executor.PullImage(config.Images().CollectorImage())
+	c.flags = options.CollectorFlags
+	c.bootstrapOnly = options.BootstrapOnly
+
+	rootPropagation, err := HostRootMountPropagation(c.executor)
+	if err != nil {
+		return err
+	}
+	c.hostPropagation = PropagationReachesContainers(rootPropagation)
+	if !c.hostPropagation {
+		logger.Info("Host root mount is not shared, host mounts will not propagate", "propagation", rootPropagation)
+	}
+
+	artifactMount := options.ArtifactMount
+	if options.CollectorWrapper != "" {
+		if artifactMount == "" {
+			artifactMount = wrapperOutputDir
+		}
+
+		preArguments, err := wrapperPreArguments(options.CollectorWrapper, artifactMount)
+		if err != nil {
+			return err
+		}
+		c.env["COLLECTOR_PRE_ARGUMENTS"] = withWrapper(preArguments, c.env["COLLECTOR_PRE_ARGUMENTS"])
+	}
+
+	logger.Info("Collector environment", "env", c.env)
+
+	if artifactMount != "" {
+		// the directory is mounted from the host running the containers
+		output, err := c.executor.Exec("mktemp", "-d", "/tmp/collector-artifacts-XXXXXX")
+		if err != nil {
+			return err
+		}
+		c.artifactDir = strings.TrimSpace(output)
+		c.mounts[artifactMount] = c.artifactDir
+
+		for name, content := range wrapperFiles(options.CollectorWrapper, artifactMount) {
+			_, err := c.executor.ExecWithStdin(content, "sh", "-c", "cat > "+path.Join(c.artifactDir, name))
+			if err != nil {
+				return fmt.Errorf("failed to write %s for the collector wrapper: %w", name, err)
+			}
+		}
+	}
+
+	return c.executor.PullImageWithPolicy(config.Images().CollectorImage(), options.PullPolicy)
 }
 
 func (c *DockerCollectorManager) Launch() error {
@@ -93,17 +166,28 @@ func (c *DockerCollectorManager) TearDown() error {
 		return fmt.Errorf("Unable to check if container is running: %s", err)
 	}
 
+	defer c.captureArtifacts()
+	defer c.removeHostEtc()
+
 	if !isRunning {
-		c.captureLogs("collector")
+		logs, _ := c.captureLogs("collector")
 		// Check if collector container segfaulted or exited with error
-		exitCode, err := c.executor.ExitCode(executor.ContainerFilter{
-			Name: "collector",
-		})
+		exitCode, signal, oomKilled, err := c.executor.GetContainerExitReason("collector")
 		if err != nil {
 			return fmt.Errorf("Failed to get container exit code: %s", err)
 		}
-		if exitCode != 0 {
-			return fmt.Errorf("Collector container has non-zero exit code (%d)", exitCode)
+		if isAssertionFailure(signal) {
+			c.captureDmesg()
+			return &AssertionFailureError{
+				ExitCode:  exitCode,
+				LogWindow: logWindow(logs, config.AbortLogLines()),
+				CoreDump:  c.captureCoreDump(),
+			}
+		}
+		if exitCode != 0 || oomKilled {
+			c.captureDmesg()
+			return fmt.Errorf("Collector container has non-zero exit code (%s)",
+				executor.DescribeExit(exitCode, signal, oomKilled))
 		}
 	} else {
 		c.stopContainer("collector")
@@ -118,6 +202,17 @@ func (c *DockerCollectorManager) IsRunning() (bool, error) {
 	return c.executor.IsContainerRunning("collector")
 }
 
+// CollectorProcessStats samples the resource usage of the collector process,
+// by reading its /proc entries from within the collector container.
+func (c *DockerCollectorManager) CollectorProcessStats() (ProcStats, error) {
+	output, err := c.executor.ExecWithoutRetry(executor.RuntimeCommand, "exec", "collector", "sh", "-c", procStatsScript)
+	if err != nil {
+		return ProcStats{}, err
+	}
+
+	return parseProcStats(output)
+}
+
 // These two methods might be useful in the future. I used them for debugging
 func (c *DockerCollectorManager) getContainers() (string, error) {
 	cmd := []string{executor.RuntimeCommand, "container", "ps"}
@@ -133,18 +228,158 @@ func (c *DockerCollectorManager) getAllContainers() (string, error) {
 	return containers, err
 }
 
+// ValidateCollectorConfig runs a bootstrap-only collector with the configured
+// arguments, environment and configuration, and checks that it exits cleanly.
+// This surfaces invalid settings (e.g. COLLECTOR_PRE_ARGUMENTS) as a clear
+// error before the real collector is launched. It must be called after Setup.
+func (c *DockerCollectorManager) ValidateCollectorConfig() error {
+	cmd, err := c.runCommand("collector-validate", "--rm")
+	if err != nil {
+		return err
+	}
+	cmd = append(cmd, "exit", "0")
+
+	output, err := c.executor.ExecWithoutRetry(cmd...)
+	if err != nil {
+		return fmt.Errorf("collector failed to start with the provided configuration: %w\n%s", err, output)
+	}
+	return nil
+}
+
+// ActualCollectionMethod returns the collection method collector logged in
+// its configuration on startup, see parseCollectionMethod.
+func (c *DockerCollectorManager) ActualCollectionMethod() (string, error) {
+	logs, err := c.executor.Exec(executor.RuntimeCommand, "logs", "collector")
+	if err != nil {
+		return "", err
+	}
+	return parseCollectionMethod(logs)
+}
+
+// CollectorProbeInfo returns the eBPF programs collector loaded, and any
+// failures to load them, based on its logs.
+func (c *DockerCollectorManager) CollectorProbeInfo() (ProbeInfo, error) {
+	logs, err := c.executor.Exec(executor.RuntimeCommand, "logs", "collector")
+	if err != nil {
+		return ProbeInfo{}, err
+	}
+	return parseProbeInfo(logs), nil
+}
+
+// WaitForHealthy polls the health status of the collector container until
+// it is healthy, or the timeout expires. Unlike log based checks, it does not
+// depend on the format of collector's output. Images without the health
+// check script cannot report healthy, so for those it waits for collector to
+// log that it started instead.
+func (c *DockerCollectorManager) WaitForHealthy(timeout time.Duration) error {
+	args := append([]string{executor.RuntimeCommand, "exec", "collector"}, hasHealthCheckArgs()...)
+	if _, err := c.executor.ExecWithoutRetry(args...); err != nil {
+		logger.Info("No health check script in the collector image, waiting for its logs", "err", err)
+		return waitForLogMatch(c.logs, startedPattern, timeout)
+	}
+
+	timer := time.After(timeout)
+	ticker := time.NewTicker(healthPollInterval)
+	defer ticker.Stop()
+
+	status := ""
+	for {
+		state, err := c.executor.GetContainerState("collector")
+		if err != nil {
+			logger.Info("Retrying WaitForHealthy", "err", err)
+		} else {
+			status = state.Health
+			if status == "healthy" {
+				return nil
+			}
+		}
+
+		select {
+		case <-timer:
+			return fmt.Errorf("Timed out waiting for collector to become healthy (last status: %q)", status)
+		case <-ticker.C:
+		}
+	}
+}
+
+// WaitForInitialScrape waits until collector has reported the connections
+// and endpoints found by its first scrape, see waitForInitialScrape.
+func (c *DockerCollectorManager) WaitForInitialScrape(reported func() bool, timeout time.Duration) error {
+	return waitForInitialScrape(c.logs, reported, c.config, timeout)
+}
+
+// logs returns the logs of the collector container so far.
+func (c *DockerCollectorManager) logs() (string, error) {
+	return c.executor.ExecWithoutRetry(executor.RuntimeCommand, "logs", "collector")
+}
+
+// EffectiveCollectorConfig returns the configuration collector logged on
+// startup, i.e. as it actually parsed it.
+func (c *DockerCollectorManager) EffectiveCollectorConfig() (map[string]any, error) {
+	logs, err := c.executor.Exec(executor.RuntimeCommand, "logs", "collector")
+	if err != nil {
+		return nil, err
+	}
+	return parseEffectiveConfig(logs)
+}
+
 func (c *DockerCollectorManager) launchCollector() error {
+	runArgs := []string{}
+	if !c.bootstrapOnly {
+		runArgs = append(runArgs, "-d")
+		runArgs = append(runArgs, healthCheckArgs()...)
+	}
+
+	cmd, err := c.runCommand("collector", runArgs...)
+	if err != nil {
+		return err
+	}
+
+	c.startTime = time.Now()
+
+	if c.bootstrapOnly {
+		cmd = append(cmd, "exit", "0")
+	} else if len(c.flags) > 0 {
+		defaults, err := defaultFlags(c.env, c.config)
+		if err != nil {
+			return err
+		}
+		cmd = append(cmd, collectorCommand(defaults, c.flags)...)
+	}
+
+	output, err := c.executor.Exec(cmd...)
+	c.CollectorOutput = output
+
+	outLines := strings.Split(output, "\n")
+	c.containerID = common.ContainerShortID(string(outLines[len(outLines)-1]))
+	return err
+}
+
+// runCommand builds the command to run a collector container with the given
+// name, up to and including the image, with the mounts, environment and
+// configuration of this manager.
+func (c *DockerCollectorManager) runCommand(name string, runArgs ...string) ([]string, error) {
+	return c.buildRunCommand(name, config.Images().CollectorImage(), runArgs)
+}
+
+// buildRunCommand translates the mounts, environment and configuration of
+// this manager into the command running the image, without side effects so
+// that the translation can be tested without a runtime. Mounts and
+// environment variables are sorted, for the command to be reproducible.
+func (c *DockerCollectorManager) buildRunCommand(name string, image string, runArgs []string) ([]string, error) {
 	cmd := []string{executor.RuntimeCommand, "run",
-		"--name", "collector",
+		"--name", name,
 		"--privileged",
 		"--network=host"}
 
-	if !c.bootstrapOnly {
-		cmd = append(cmd, "-d")
-	}
+	cmd = append(cmd, runArgs...)
 
-	for dst, src := range c.mounts {
+	for _, dst := range sortedKeys(c.mounts) {
+		src := c.mounts[dst]
 		mount := src + ":" + dst
+		if c.hostPropagation {
+			mount = src + ":" + withHostPropagation(dst)
+		}
 		if src == "" {
 			// allows specification of anonymous volumes
 			mount = dst
@@ -152,34 +387,87 @@ func (c *DockerCollectorManager) launchCollector() error {
 		cmd = append(cmd, "-v", mount)
 	}
 
-	for k, v := range c.env {
-		cmd = append(cmd, "--env", k+"="+v)
+	for _, k := range sortedKeys(c.env) {
+		cmd = append(cmd, "--env", k+"="+c.env[k])
 	}
 
 	configJson, err := json.Marshal(c.config)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	cmd = append(cmd, "--env", "COLLECTOR_CONFIG="+string(configJson))
-	cmd = append(cmd, config.Images().CollectorImage())
+	cmd = append(cmd, image)
+	return cmd, nil
+}
 
-	if c.bootstrapOnly {
-		cmd = append(cmd, "exit", "0")
+// withHostPropagation adds the propagation mode of the host mounts to a
+// mount destination, which may already carry options (e.g. /host/proc:ro),
+// so that mounts made on the host after collector started are visible.
+func withHostPropagation(dst string) string {
+	if !strings.HasPrefix(dst, HostMountPrefix) {
+		return dst
 	}
 
-	output, err := c.executor.Exec(cmd...)
-	c.CollectorOutput = output
+	if strings.Contains(dst, ":") {
+		return dst + "," + HostMountPropagation
+	}
+	return dst + ":" + HostMountPropagation
+}
 
-	outLines := strings.Split(output, "\n")
-	c.containerID = common.ContainerShortID(string(outLines[len(outLines)-1]))
-	return err
+// HostRootMountPropagation returns the propagation mode of the root mount
+// of the host running the containers, see rootMountPropagation.
+func HostRootMountPropagation(e executor.Executor) (string, error) {
+	mountinfo, err := e.Exec("cat", "/proc/self/mountinfo")
+	if err != nil {
+		return "", fmt.Errorf("failed to read host mounts: %w", err)
+	}
+	return rootMountPropagation(mountinfo), nil
+}
+
+// PropagationReachesContainers reports whether a root mount with the given
+// propagation mode is shared, or a slave of a shared mount. Otherwise, the
+// runtime refuses to start containers with HostMountPropagation on host
+// paths, so they must be mounted with the default (private) propagation.
+func PropagationReachesContainers(propagation string) bool {
+	return propagation == "shared" || propagation == "slave"
+}
+
+// rootMountPropagation returns the propagation mode of the root mount, as
+// listed in the given /proc/<pid>/mountinfo: "shared" or "slave" if it has
+// a shared or master optional field, "private" otherwise, or "unknown" if
+// there is no root mount. When several mounts are stacked on the root, the
+// last one listed is the visible one.
+func rootMountPropagation(mountinfo string) string {
+	propagation := "unknown"
+	for _, line := range strings.Split(mountinfo, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 7 || fields[4] != "/" {
+			continue
+		}
+
+		propagation = "private"
+		// optional fields run from the 7th field to the "-" separator
+		for _, field := range fields[6:] {
+			if field == "-" {
+				break
+			}
+			if strings.HasPrefix(field, "shared:") {
+				propagation = "shared"
+				break
+			}
+			if strings.HasPrefix(field, "master:") {
+				propagation = "slave"
+			}
+		}
+	}
+	return propagation
 }
 
 func (c *DockerCollectorManager) captureLogs(containerName string) (string, error) {
 	logs, err := c.executor.Exec(executor.RuntimeCommand, "logs", containerName)
 	if err != nil {
-		fmt.Printf(executor.RuntimeCommand+" logs error (%v) for container %s\n", err, containerName)
+		logger.Error("Failed to get container logs", "container", containerName, "err", err)
 		return "", err
 	}
 
@@ -193,6 +481,115 @@ func (c *DockerCollectorManager) captureLogs(containerName string) (string, erro
 	return logs, nil
 }
 
+// captureArtifacts copies the files collector wrote to the artifact mount
+// into the test's artifacts, and removes the temporary host directory. The
+// files are archived on the host, so that they can be copied back in one go.
+func (c *DockerCollectorManager) captureArtifacts() {
+	if c.artifactDir == "" {
+		return
+	}
+
+	tarPath := c.artifactDir + ".tar"
+	defer func() {
+		c.executor.Exec("rm", "-rf", c.artifactDir, tarPath)
+		os.Remove(tarPath)
+		c.artifactDir = ""
+	}()
+
+	if _, err := c.executor.Exec("tar", "-cf", tarPath, "-C", c.artifactDir, "."); err != nil {
+		logger.Error("Failed to archive collector artifacts", "err", err)
+		return
+	}
+
+	if _, err := c.executor.CopyFromHost(tarPath, tarPath); err != nil {
+		logger.Error("Failed to copy collector artifacts", "err", err)
+		return
+	}
+
+	if err := common.ExtractArtifacts(c.testName, tarPath); err != nil {
+		logger.Error("Failed to extract collector artifacts", "err", err)
+	}
+}
+
+// removeHostEtc removes the altered copy of the host's /etc, if any.
+func (c *DockerCollectorManager) removeHostEtc() {
+	if c.hostEtcDir == "" {
+		return
+	}
+
+	if _, err := c.executor.Exec("rm", "-rf", c.hostEtcDir); err != nil {
+		logger.Error("Failed to remove host /etc copy", "dir", c.hostEtcDir, "err", err)
+	}
+	c.hostEtcDir = ""
+}
+
+// captureDmesg writes the host kernel messages that are relevant to collector,
+// and were logged since it was launched, into the test's log directory.
+// Probe loading failures often only leave a trace there.
+func (c *DockerCollectorManager) captureDmesg() error {
+	dmesg, err := c.executor.GetHostDmesg(c.startTime)
+	if err != nil {
+		logger.Error("Failed to get host dmesg", "err", err)
+		return err
+	}
+
+	logFile, err := common.PrepareLog(c.testName, "dmesg.log")
+	if err != nil {
+		return err
+	}
+	defer logFile.Close()
+
+	_, err = logFile.WriteString(filterRelevantDmesg(dmesg))
+	return err
+}
+
+// captureCoreDump writes the information systemd-coredump recorded about
+// the collector crash, including its backtrace, into the test's log
+// directory, and returns the path of the log. An empty path is returned if
+// there is no such information, e.g. on hosts without systemd-coredump.
+func (c *DockerCollectorManager) captureCoreDump() string {
+	info, err := c.executor.Exec("coredumpctl", "--no-pager", "info",
+		"--since", c.startTime.Format("2006-01-02 15:04:05"), "collector")
+	if err != nil {
+		logger.Info("No collector core dump found", "err", err)
+		return ""
+	}
+
+	logFile, err := common.PrepareLog(c.testName, "coredump.log")
+	if err != nil {
+		return ""
+	}
+	defer logFile.Close()
+
+	if _, err := logFile.WriteString(info); err != nil {
+		return ""
+	}
+	return logFile.Name()
+}
+
+// filterRelevantDmesg keeps only the kernel messages that are likely related
+// to collector, i.e. BPF program loading, crashes and OOM kills. If nothing
+// matches, everything is kept to avoid losing a possible cause.
+func filterRelevantDmesg(dmesg string) string {
+	keywords := []string{"bpf", "collector", "segfault", "oom", "call trace", "bug:"}
+
+	relevant := []string{}
+	for _, line := range strings.Split(dmesg, "\n") {
+		lower := strings.ToLower(line)
+		for _, keyword := range keywords {
+			if strings.Contains(lower, keyword) {
+				relevant = append(relevant, line)
+				break
+			}
+		}
+	}
+
+	if len(relevant) == 0 {
+		return dmesg
+	}
+	return strings.Join(relevant, "\n")
+}
+
 func (c *DockerCollectorManager) killContainer(name string) error {
 	_, err1 := c.executor.KillContainer(name)
 	_, err2 := c.executor.RemoveContainer(executor.ContainerFilter{
@@ -219,6 +616,12 @@ func (c *DockerCollectorManager) ContainerID() string {
 	return c.containerID
 }
 
+// Config returns the collector configuration, as it is passed to
+// the collector container.
+func (c *DockerCollectorManager) Config() map[string]any {
+	return c.config
+}
+
 func (c *DockerCollectorManager) TestName() string {
 	return c.testName
 }
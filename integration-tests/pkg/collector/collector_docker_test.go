@@ -0,0 +1,106 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/stackrox/collector/integration-tests/pkg/executor"
+)
+
+func TestWithHostPropagation(t *testing.T) {
+	assert.Equal(t, "/host/proc:ro,rslave", withHostPropagation("/host/proc:ro"))
+	assert.Equal(t, "/host/var:rslave", withHostPropagation("/host/var"))
+	assert.Equal(t, "/tmp", withHostPropagation("/tmp"))
+}
+
+func TestRootMountPropagation(t *testing.T) {
+	shared := "22 1 253:0 / / rw,relatime shared:1 - xfs /dev/vda1 rw\n" +
+		"23 22 0:21 / /proc rw,nosuid shared:12 - proc proc rw\n"
+	assert.Equal(t, "shared", rootMountPropagation(shared))
+
+	slave := "22 1 253:0 / / rw,relatime master:1 - xfs /dev/vda1 rw\n"
+	assert.Equal(t, "slave", rootMountPropagation(slave))
+
+	private := "22 1 253:0 / / rw,relatime - xfs /dev/vda1 rw\n" +
+		"23 22 0:21 / /proc rw,nosuid shared:12 - proc proc rw\n"
+	assert.Equal(t, "private", rootMountPropagation(private))
+
+	stacked := "22 1 253:0 / / rw,relatime shared:1 - xfs /dev/vda1 rw\n" +
+		"30 22 0:40 / / rw,relatime - overlay overlay rw\n"
+	assert.Equal(t, "private", rootMountPropagation(stacked))
+
+	assert.Equal(t, "unknown", rootMountPropagation(""))
+
+	assert.True(t, PropagationReachesContainers("shared"))
+	assert.True(t, PropagationReachesContainers("slave"))
+	assert.False(t, PropagationReachesContainers("private"))
+	assert.False(t, PropagationReachesContainers("unknown"))
+}
+
+func TestBuildRunCommand(t *testing.T) {
+	base := []string{executor.RuntimeCommand, "run", "--name", "collector", "--privileged", "--network=host"}
+
+	tests := []struct {
+		name    string
+		manager DockerCollectorManager
+		runArgs []string
+		want    []string
+	}{
+		{
+			name: "minimal",
+			want: append(append([]string{}, base...),
+				"--env", "COLLECTOR_CONFIG=null", "collector:test"),
+		},
+		{
+			name:    "run arguments",
+			runArgs: []string{"-d", "--rm"},
+			want: append(append([]string{}, base...),
+				"-d", "--rm", "--env", "COLLECTOR_CONFIG=null", "collector:test"),
+		},
+		{
+			name: "sorted mounts",
+			manager: DockerCollectorManager{mounts: map[string]string{
+				"/host/proc:ro": "/proc",
+				"/host/etc:ro":  "/etc",
+				"/tmp":          "",
+			}},
+			want: append(append([]string{}, base...),
+				"-v", "/etc:/host/etc:ro",
+				"-v", "/proc:/host/proc:ro",
+				"-v", "/tmp",
+				"--env", "COLLECTOR_CONFIG=null", "collector:test"),
+		},
+		{
+			name: "host propagation",
+			manager: DockerCollectorManager{
+				mounts:          map[string]string{"/host/proc:ro": "/proc", "/tmp": "/tmp"},
+				hostPropagation: true,
+			},
+			want: append(append([]string{}, base...),
+				"-v", "/proc:/host/proc:ro,rslave",
+				"-v", "/tmp:/tmp",
+				"--env", "COLLECTOR_CONFIG=null", "collector:test"),
+		},
+		{
+			name: "sorted environment and config",
+			manager: DockerCollectorManager{
+				env:    map[string]string{"ROX_B": "2", "ROX_A": "1"},
+				config: map[string]any{"logLevel": "debug"},
+			},
+			want: append(append([]string{}, base...),
+				"--env", "ROX_A=1",
+				"--env", "ROX_B=2",
+				"--env", `COLLECTOR_CONFIG={"logLevel":"debug"}`, "collector:test"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd, err := tt.manager.buildRunCommand("collector", "collector:test", tt.runArgs)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, cmd)
+		})
+	}
+}
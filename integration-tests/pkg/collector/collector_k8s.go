@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/stackrox/collector/integration-tests/pkg/common"
 	"github.com/stackrox/collector/integration-tests/pkg/config"
@@ -14,10 +15,18 @@ import (
 	coreV1 "k8s.io/api/core/v1"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/rest"
 )
 
 const (
 	TEST_NAMESPACE = "collector-tests"
+
+	// bootstrapTimeout is how long a bootstrap-only collector may take to
+	// download or build its driver and exit
+	bootstrapTimeout = 5 * time.Minute
+
+	// debugfsVolume is the volume of the host's debugfs, used by eBPF
+	debugfsVolume = "sys-ro"
 )
 
 type K8sCollectorManager struct {
@@ -26,6 +35,10 @@ type K8sCollectorManager struct {
 	volumes      []coreV1.Volume
 	env          []coreV1.EnvVar
 	config       map[string]any
+	flags        map[string]string
+
+	bootstrapOnly bool
+	pullPolicy    executor.PullPolicy
 
 	testName string
 
@@ -54,7 +67,7 @@ func newK8sManager(e executor.K8sExecutor, name string) *K8sCollectorManager {
 		{Name: "proc-ro", ReadOnly: true, MountPath: "/host/proc", MountPropagation: &propagationHostToContainer},
 		{Name: "etc-ro", ReadOnly: true, MountPath: "/host/etc", MountPropagation: &propagationHostToContainer},
 		{Name: "usr-ro", ReadOnly: true, MountPath: "/host/usr/lib", MountPropagation: &propagationHostToContainer},
-		{Name: "sys-ro", ReadOnly: true, MountPath: "/host/sys/kernel/debug", MountPropagation: &propagationHostToContainer},
+		{Name: debugfsVolume, ReadOnly: true, MountPath: "/host/sys/kernel/debug", MountPropagation: &propagationHostToContainer},
 		{Name: "var-rw", ReadOnly: false, MountPath: "/host/var", MountPropagation: &propagationHostToContainer},
 		{Name: "run-rw", ReadOnly: false, MountPath: "/host/run", MountPropagation: &propagationHostToContainer},
 		{Name: "tmp", ReadOnly: false, MountPath: "/tmp", MountPropagation: &propagationHostToContainer},
@@ -64,7 +77,7 @@ func newK8sManager(e executor.K8sExecutor, name string) *K8sCollectorManager {
 		{Name: "proc-ro", VolumeSource: coreV1.VolumeSource{HostPath: &coreV1.HostPathVolumeSource{Path: "/proc"}}},
 		{Name: "etc-ro", VolumeSource: coreV1.VolumeSource{HostPath: &coreV1.HostPathVolumeSource{Path: "/etc"}}},
 		{Name: "usr-ro", VolumeSource: coreV1.VolumeSource{HostPath: &coreV1.HostPathVolumeSource{Path: "/usr/lib"}}},
-		{Name: "sys-ro", VolumeSource: coreV1.VolumeSource{HostPath: &coreV1.HostPathVolumeSource{Path: "/sys/kernel/debug"}}},
+		{Name: debugfsVolume, VolumeSource: coreV1.VolumeSource{HostPath: &coreV1.HostPathVolumeSource{Path: "/sys/kernel/debug"}}},
 		{Name: "var-rw", VolumeSource: coreV1.VolumeSource{HostPath: &coreV1.HostPathVolumeSource{Path: "/var"}}},
 		{Name: "run-rw", VolumeSource: coreV1.VolumeSource{HostPath: &coreV1.HostPathVolumeSource{Path: "/run"}}},
 		{Name: "tmp", VolumeSource: coreV1.VolumeSource{HostPath: &coreV1.HostPathVolumeSource{Path: "/tmp"}}},
@@ -87,7 +100,11 @@ func (k *K8sCollectorManager) Setup(options *StartupOptions) error {
 		options = &StartupOptions{}
 	}
 
-	for name, value := range options.Env {
+	env, err := options.environment()
+	if err != nil {
+		return err
+	}
+	for name, value := range env {
 		k.env = replaceOrAppendEnvVar(k.env, coreV1.EnvVar{Name: name, Value: value})
 	}
 
@@ -96,14 +113,58 @@ func (k *K8sCollectorManager) Setup(options *StartupOptions) error {
 		return err
 	}
 	k.env = replaceOrAppendEnvVar(k.env, coreV1.EnvVar{Name: "COLLECTOR_CONFIG", Value: string(configJson)})
+	logger.Info("Collector environment", "env", k.env)
 
 	if options.Config != nil {
 		maps.Copy(k.config, options.Config)
 	}
 
+	k.flags = options.CollectorFlags
+	k.bootstrapOnly = options.BootstrapOnly
+
+	if options.WithoutDebugfs {
+		k.removeVolume(debugfsVolume)
+	}
+
+	k.pullPolicy, err = executor.ResolvePullPolicy(options.PullPolicy)
+	if err != nil {
+		return err
+	}
+
+	if options.ArtifactMount != "" {
+		return fmt.Errorf("ArtifactMount is not supported on K8s")
+	}
+
+	if options.CollectorWrapper != "" {
+		return fmt.Errorf("CollectorWrapper is not supported on K8s")
+	}
+
+	if len(options.HostEtcRemove) > 0 || len(options.HostEtcReplace) > 0 {
+		return fmt.Errorf("HostEtcRemove and HostEtcReplace are not supported on K8s")
+	}
+
 	return nil
 }
 
+// removeVolume removes a volume, and its mount, from the collector pod.
+func (k *K8sCollectorManager) removeVolume(name string) {
+	mounts := []coreV1.VolumeMount{}
+	for _, mount := range k.volumeMounts {
+		if mount.Name != name {
+			mounts = append(mounts, mount)
+		}
+	}
+	k.volumeMounts = mounts
+
+	volumes := []coreV1.Volume{}
+	for _, volume := range k.volumes {
+		if volume.Name != name {
+			volumes = append(volumes, volume)
+		}
+	}
+	k.volumes = volumes
+}
+
 func (k *K8sCollectorManager) Launch() error {
 	// Start an events watcher before spawning collector
 	err := k.startNamespaceEventWatcher()
@@ -111,33 +172,100 @@ func (k *K8sCollectorManager) Launch() error {
 		return err
 	}
 
+	pod, err := k.collectorPod("collector", k.bootstrapOnly)
+	if err != nil {
+		return err
+	}
+
+	_, err = k.executor.CreatePod(TEST_NAMESPACE, pod)
+	if err != nil || !k.bootstrapOnly {
+		return err
+	}
+
+	// same as the docker manager, a bootstrap-only collector must exit
+	// cleanly, which is checked right away
+	return k.waitForBootstrapExit("collector", bootstrapTimeout)
+}
+
+// collectorPod returns the pod running collector with the configured
+// environment, volumes and flags. A bootstrap-only collector exits once
+// its driver is set up.
+func (k *K8sCollectorManager) collectorPod(name string, bootstrapOnly bool) (*coreV1.Pod, error) {
 	objectMeta := metaV1.ObjectMeta{
-		Name:      "collector",
+		Name:      name,
 		Namespace: TEST_NAMESPACE,
-		Labels:    map[string]string{"app": "collector"},
+		Labels:    map[string]string{"app": name},
 	}
 
 	privileged := true
 	container := coreV1.Container{
 		Name:            "collector",
 		Image:           config.Images().CollectorImage(),
+		ImagePullPolicy: coreV1.PullPolicy(k.pullPolicy),
 		Ports:           []coreV1.ContainerPort{{ContainerPort: 8080}},
 		Env:             k.env,
 		VolumeMounts:    k.volumeMounts,
 		SecurityContext: &coreV1.SecurityContext{Privileged: &privileged},
 	}
 
-	pod := &coreV1.Pod{
+	if bootstrapOnly {
+		// Run the bootstrap and exit cleanly, same as the docker manager
+		container.Args = []string{"exit", "0"}
+	} else {
+		container.ReadinessProbe = healthCheckProbe()
+
+		if len(k.flags) > 0 {
+			env := map[string]string{}
+			for _, envVar := range k.env {
+				env[envVar.Name] = envVar.Value
+			}
+			defaults, err := defaultFlags(env, k.config)
+			if err != nil {
+				return nil, err
+			}
+			container.Args = collectorCommand(defaults, k.flags)
+		}
+	}
+
+	return &coreV1.Pod{
 		ObjectMeta: objectMeta,
 		Spec: coreV1.PodSpec{
 			Containers:    []coreV1.Container{container},
 			Volumes:       k.volumes,
 			RestartPolicy: coreV1.RestartPolicyNever, // if the pod fails, it fails
 		},
-	}
+	}, nil
+}
 
-	_, err = k.executor.CreatePod(TEST_NAMESPACE, pod)
-	return err
+// waitForBootstrapExit polls a collector pod until its container has
+// terminated, and checks it exited cleanly.
+func (k *K8sCollectorManager) waitForBootstrapExit(podName string, timeout time.Duration) error {
+	timer := time.After(timeout)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-timer:
+			return fmt.Errorf("Timed out waiting for the collector bootstrap to exit")
+		case <-ticker.C:
+			pod, err := k.executor.ClientSet().CoreV1().Pods(TEST_NAMESPACE).Get(context.Background(), podName, metaV1.GetOptions{})
+			if err != nil {
+				logger.Info("Retrying waitForBootstrapExit", "err", err)
+				continue
+			}
+
+			exitCode, terminated := podExitCode(pod)
+			if !terminated {
+				continue
+			}
+			if exitCode != 0 {
+				return fmt.Errorf("Collector bootstrap has non-zero exit code (%s)",
+					executor.DescribeExit(exitCode, executor.SignalFromExitCode(exitCode), false))
+			}
+			return nil
+		}
+	}
 }
 
 func (k *K8sCollectorManager) TearDown() error {
@@ -152,16 +280,22 @@ func (k *K8sCollectorManager) TearDown() error {
 	}
 
 	if !isRunning {
-		exitCode, err := k.executor.ExitCode(executor.ContainerFilter{
-			Name:      "collector",
-			Namespace: TEST_NAMESPACE,
-		})
+		exitCode, signal, oomKilled, err := k.executor.GetContainerExitReason("collector")
 		if err != nil {
 			return fmt.Errorf("Failed to get container exit code: %s", err)
 		}
 
-		if exitCode != 0 {
-			return fmt.Errorf("Collector container has non-zero exit code (%d)", exitCode)
+		if isAssertionFailure(signal) {
+			logs, _ := k.logs()
+			return &AssertionFailureError{
+				ExitCode:  exitCode,
+				LogWindow: logWindow(logs, config.AbortLogLines()),
+			}
+		}
+
+		if exitCode != 0 || oomKilled {
+			return fmt.Errorf("Collector container has non-zero exit code (%s)",
+				executor.DescribeExit(exitCode, signal, oomKilled))
 		}
 	}
 
@@ -176,7 +310,175 @@ func (k *K8sCollectorManager) IsRunning() (bool, error) {
 		return false, err
 	}
 
-	return *pod.Status.ContainerStatuses[0].Started, nil
+	return isPodStarted(pod), nil
+}
+
+// WaitForRunning polls the collector pod until its container has started,
+// or the timeout expires.
+func (k *K8sCollectorManager) WaitForRunning(timeout time.Duration) error {
+	timer := time.After(timeout)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-timer:
+			return fmt.Errorf("Timed out waiting for collector pod to be running")
+		case <-ticker.C:
+			running, err := k.IsRunning()
+			if err != nil {
+				logger.Info("Retrying WaitForRunning", "err", err)
+				continue
+			}
+			if running {
+				return nil
+			}
+		}
+	}
+}
+
+// WaitForHealthy polls the collector pod until it reports ready, based on
+// its readiness probe, or the timeout expires. Same as the docker manager,
+// it waits for collector's logs instead if the image has no health check
+// script.
+func (k *K8sCollectorManager) WaitForHealthy(timeout time.Duration) error {
+	// the script can only be looked up once the container has started
+	start := time.Now()
+	if err := k.WaitForRunning(timeout); err != nil {
+		return err
+	}
+	timeout -= time.Since(start)
+
+	if _, stderr, err := k.executor.ExecInPod(TEST_NAMESPACE, "collector", "collector", hasHealthCheckArgs()); err != nil {
+		logger.Info("No health check script in the collector image, waiting for its logs", "err", err, "stderr", stderr)
+		return waitForLogMatch(k.logs, startedPattern, timeout)
+	}
+
+	timer := time.After(timeout)
+	ticker := time.NewTicker(healthPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-timer:
+			return fmt.Errorf("Timed out waiting for collector pod to be ready")
+		case <-ticker.C:
+			pod, err := k.executor.ClientSet().CoreV1().Pods(TEST_NAMESPACE).Get(context.Background(), "collector", metaV1.GetOptions{})
+			if err != nil {
+				logger.Info("Retrying WaitForHealthy", "err", err)
+				continue
+			}
+			if isPodReady(pod) {
+				return nil
+			}
+		}
+	}
+}
+
+// podExitCode returns the exit code of the first container of the pod, and
+// whether it has terminated at all.
+func podExitCode(pod *coreV1.Pod) (int, bool) {
+	if pod == nil || len(pod.Status.ContainerStatuses) == 0 {
+		return -1, false
+	}
+
+	terminated := pod.Status.ContainerStatuses[0].State.Terminated
+	if terminated == nil {
+		return -1, false
+	}
+	return int(terminated.ExitCode), true
+}
+
+// isPodStarted returns whether the first container of the pod has started.
+// Right after creation, the pod may not be scheduled yet and its container
+// statuses may be missing or incomplete, in which case it is not started.
+func isPodStarted(pod *coreV1.Pod) bool {
+	if pod == nil || len(pod.Status.ContainerStatuses) == 0 {
+		return false
+	}
+
+	started := pod.Status.ContainerStatuses[0].Started
+	return started != nil && *started
+}
+
+// CollectorProcessStats samples the resource usage of the collector process,
+// by reading its /proc entries from within the collector pod.
+func (k *K8sCollectorManager) CollectorProcessStats() (ProcStats, error) {
+	stdout, stderr, err := k.executor.ExecInPod(TEST_NAMESPACE, "collector", "collector", []string{"sh", "-c", procStatsScript})
+	if err != nil {
+		return ProcStats{}, fmt.Errorf("%w: %s", err, stderr)
+	}
+
+	return parseProcStats(stdout)
+}
+
+// ValidateCollectorConfig runs a bootstrap-only collector pod with the
+// configured environment and configuration, and checks that it exits
+// cleanly, same as the docker manager. The pod is removed afterwards. It
+// must be called after Setup.
+func (k *K8sCollectorManager) ValidateCollectorConfig() error {
+	pod, err := k.collectorPod("collector-validate", true)
+	if err != nil {
+		return err
+	}
+
+	pods := k.executor.ClientSet().CoreV1().Pods(TEST_NAMESPACE)
+	if _, err := k.executor.CreatePod(TEST_NAMESPACE, pod); err != nil {
+		return err
+	}
+	defer pods.Delete(context.Background(), pod.Name, metaV1.DeleteOptions{})
+
+	if err := k.waitForBootstrapExit(pod.Name, bootstrapTimeout); err != nil {
+		logs, _ := pods.GetLogs(pod.Name, &coreV1.PodLogOptions{}).DoRaw(context.Background())
+		return fmt.Errorf("collector failed to start with the provided configuration: %w\n%s", err, logs)
+	}
+	return nil
+}
+
+// ActualCollectionMethod returns the collection method collector logged in
+// its configuration on startup, see parseCollectionMethod.
+func (k *K8sCollectorManager) ActualCollectionMethod() (string, error) {
+	logs, err := k.logs()
+	if err != nil {
+		return "", err
+	}
+	return parseCollectionMethod(logs)
+}
+
+// CollectorProbeInfo returns the eBPF programs collector loaded, and any
+// failures to load them, based on its logs.
+func (k *K8sCollectorManager) CollectorProbeInfo() (ProbeInfo, error) {
+	logs, err := k.logs()
+	if err != nil {
+		return ProbeInfo{}, err
+	}
+	return parseProbeInfo(logs), nil
+}
+
+// WaitForInitialScrape waits until collector has reported the connections
+// and endpoints found by its first scrape, see waitForInitialScrape.
+func (k *K8sCollectorManager) WaitForInitialScrape(reported func() bool, timeout time.Duration) error {
+	return waitForInitialScrape(k.logs, reported, k.config, timeout)
+}
+
+// EffectiveCollectorConfig returns the configuration collector logged on
+// startup, i.e. as it actually parsed it.
+func (k *K8sCollectorManager) EffectiveCollectorConfig() (map[string]any, error) {
+	logs, err := k.logs()
+	if err != nil {
+		return nil, err
+	}
+	return parseEffectiveConfig(logs)
+}
+
+// logs returns the logs of the collector container so far.
+func (k *K8sCollectorManager) logs() (string, error) {
+	logs, err := k.logsRequest().DoRaw(context.Background())
+	return string(logs), err
+}
+
+func (k *K8sCollectorManager) logsRequest() *rest.Request {
+	return k.executor.ClientSet().CoreV1().Pods(TEST_NAMESPACE).GetLogs("collector", &coreV1.PodLogOptions{})
 }
 
 func (k *K8sCollectorManager) ContainerID() string {
@@ -188,6 +490,12 @@ func (k *K8sCollectorManager) ContainerID() string {
 	return k.executor.ContainerID(cf)
 }
 
+// Config returns the collector configuration, as it is passed to
+// the collector container.
+func (k *K8sCollectorManager) Config() map[string]any {
+	return k.config
+}
+
 func (k *K8sCollectorManager) TestName() string {
 	return k.testName
 }
@@ -213,8 +521,7 @@ func (k *K8sCollectorManager) captureLogs() error {
 }
 
 func (k *K8sCollectorManager) capturePodLogs() error {
-	req := k.executor.ClientSet().CoreV1().Pods(TEST_NAMESPACE).GetLogs("collector", &coreV1.PodLogOptions{})
-	podLogs, err := req.Stream(context.Background())
+	podLogs, err := k.logsRequest().Stream(context.Background())
 	if err != nil {
 		return err
 	}
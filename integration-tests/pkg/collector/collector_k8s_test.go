@@ -0,0 +1,68 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	coreV1 "k8s.io/api/core/v1"
+)
+
+func TestIsPodStarted(t *testing.T) {
+	started := true
+	notStarted := false
+
+	tests := []struct {
+		name     string
+		pod      *coreV1.Pod
+		expected bool
+	}{
+		{
+			name:     "nil pod",
+			pod:      nil,
+			expected: false,
+		},
+		{
+			name: "pending pod without container statuses",
+			pod: &coreV1.Pod{
+				Status: coreV1.PodStatus{Phase: coreV1.PodPending},
+			},
+			expected: false,
+		},
+		{
+			name: "pending pod with nil started",
+			pod: &coreV1.Pod{
+				Status: coreV1.PodStatus{
+					Phase:             coreV1.PodPending,
+					ContainerStatuses: []coreV1.ContainerStatus{{Name: "collector"}},
+				},
+			},
+			expected: false,
+		},
+		{
+			name: "container not started",
+			pod: &coreV1.Pod{
+				Status: coreV1.PodStatus{
+					Phase:             coreV1.PodRunning,
+					ContainerStatuses: []coreV1.ContainerStatus{{Name: "collector", Started: &notStarted}},
+				},
+			},
+			expected: false,
+		},
+		{
+			name: "container started",
+			pod: &coreV1.Pod{
+				Status: coreV1.PodStatus{
+					Phase:             coreV1.PodRunning,
+					ContainerStatuses: []coreV1.ContainerStatus{{Name: "collector", Started: &started}},
+				},
+			},
+			expected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, isPodStarted(tt.pod))
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package collector
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// afterglowPattern matches the lines collector logs once it has handled the
+// afterglow settings, which are not part of its logged configuration.
+var afterglowPattern = regexp.MustCompile(`Afterglow is (enabled|disabled)|(Disabling) afterglow`)
+
+// parseEffectiveConfig returns the configuration collector logged on
+// startup, e.g. "collection_method:CORE_BPF, scrape_interval:30,
+// turn_off_scrape:0", keyed as collector logs it. Integer values, including
+// booleans logged as 0 or 1, are returned as int, true and false as bool,
+// and anything else as string. If the configuration was logged several
+// times, the last one wins. The collection method is the one collector
+// initialized, as returned by parseCollectionMethod (e.g. core-bpf).
+// Whether afterglow is enabled is added as enable_afterglow, if logged.
+func parseEffectiveConfig(logs string) (map[string]any, error) {
+	configLine := ""
+	var afterglow *bool
+	for _, line := range strings.Split(logs, "\n") {
+		if i := strings.Index(line, "collection_method:"); i >= 0 {
+			configLine = line[i:]
+		}
+		if match := afterglowPattern.FindStringSubmatch(line); match != nil {
+			enabled := match[1] == "enabled"
+			afterglow = &enabled
+		}
+	}
+
+	if configLine == "" {
+		return nil, fmt.Errorf("configuration not found in collector logs")
+	}
+
+	config := map[string]any{}
+	for _, field := range strings.Split(configLine, ",") {
+		key, value, found := strings.Cut(strings.TrimSpace(field), ":")
+		if !found {
+			continue
+		}
+		config[key] = parseConfigValue(value)
+	}
+
+	method, err := parseCollectionMethod(logs)
+	if err != nil {
+		return nil, err
+	}
+	config["collection_method"] = method
+
+	if afterglow != nil {
+		config["enable_afterglow"] = *afterglow
+	}
+	return config, nil
+}
+
+func parseConfigValue(value string) any {
+	if n, err := strconv.Atoi(value); err == nil {
+		return n
+	}
+	if b, err := strconv.ParseBool(value); err == nil {
+		return b
+	}
+	return value
+}
@@ -0,0 +1,37 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEffectiveConfig(t *testing.T) {
+	logs := `[INFO    2024/05/02 10:00:00] Starting StackRox Collector...
+[INFO    2024/05/02 10:00:00] Afterglow is disabled
+[INFO    2024/05/02 10:00:00] Config: collection_method:CORE_BPF, scrape_interval:2, turn_off_scrape:1, hostname:node-1, logLevel:DEBUG
+[INFO    2024/05/02 10:00:01] Initializing...`
+
+	config, err := parseEffectiveConfig(logs)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"collection_method": "core-bpf",
+		"scrape_interval":   2,
+		"turn_off_scrape":   1,
+		"hostname":          "node-1",
+		"logLevel":          "DEBUG",
+		"enable_afterglow":  false,
+	}, config)
+
+	config, err = parseEffectiveConfig("[ERROR] Afterglow period set to 0\n[INFO] Disabling afterglow\n" +
+		"[INFO] Config: collection_method:CORE_BPF, scrape_interval:2\n")
+	assert.NoError(t, err)
+	assert.Equal(t, false, config["enable_afterglow"])
+
+	config, err = parseEffectiveConfig("[INFO] Afterglow is enabled\n[INFO] Config: collection_method:CORE_BPF\n")
+	assert.NoError(t, err)
+	assert.Equal(t, true, config["enable_afterglow"])
+
+	_, err = parseEffectiveConfig("[INFO] Starting StackRox Collector...")
+	assert.Error(t, err)
+}
@@ -0,0 +1,56 @@
+package collector
+
+import (
+	"bufio"
+	"fmt"
+	"maps"
+	"os"
+	"strings"
+)
+
+// loadEnvFile reads collector environment variables from a file of
+// KEY=VALUE lines, in the format of docker's --env-file. Blank lines and
+// lines starting with # are ignored, and values are taken verbatim.
+func loadEnvFile(path string) (map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	env := map[string]string{}
+	scanner := bufio.NewScanner(file)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		name, value, found := strings.Cut(line, "=")
+		if !found || name == "" {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE, got %q", path, lineNo, line)
+		}
+		env[name] = value
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return env, nil
+}
+
+// environment returns the collector environment requested by the options:
+// the variables of the env file, if any, overridden by those set in Env.
+func (o *StartupOptions) environment() (map[string]string, error) {
+	env := map[string]string{}
+	if o.CollectorEnvFile != "" {
+		fileEnv, err := loadEnvFile(o.CollectorEnvFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load collector env file: %w", err)
+		}
+		maps.Copy(env, fileEnv)
+	}
+
+	maps.Copy(env, o.Env)
+	return env, nil
+}
@@ -0,0 +1,37 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartupOptionsEnvironment(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "collector.env")
+	err := os.WriteFile(path, []byte(`# captured from a failing run
+ROX_PROCESSES_LISTENING_ON_PORT=true
+
+ROX_ENABLE_AFTERGLOW=false
+COLLECTOR_PRE_ARGUMENTS=strace -f -o /tmp/out=1
+`), 0644)
+	assert.NoError(t, err)
+
+	options := StartupOptions{
+		CollectorEnvFile: path,
+		Env:              map[string]string{"ROX_ENABLE_AFTERGLOW": "true"},
+	}
+	env, err := options.environment()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"ROX_PROCESSES_LISTENING_ON_PORT": "true",
+		"ROX_ENABLE_AFTERGLOW":            "true",
+		"COLLECTOR_PRE_ARGUMENTS":         "strace -f -o /tmp/out=1",
+	}, env)
+
+	err = os.WriteFile(path, []byte("ROX_ENABLE_AFTERGLOW\n"), 0644)
+	assert.NoError(t, err)
+	_, err = options.environment()
+	assert.ErrorContains(t, err, "collector.env:1")
+}
@@ -0,0 +1,52 @@
+package collector
+
+import (
+	"encoding/json"
+	"sort"
+)
+
+// collectorWrapperCommand is the command the collector image runs by
+// default, with the flags built from COLLECTOR_CONFIG, COLLECTION_METHOD
+// and GRPC_SERVER.
+const collectorWrapperCommand = "collector-wrapper.sh"
+
+// defaultFlags returns the flags the image's default command passes to
+// collector, as built from the given environment and configuration.
+func defaultFlags(env map[string]string, collectorConfig map[string]any) (map[string]string, error) {
+	configJson, err := json.Marshal(collectorConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]string{
+		"collector-config":  string(configJson),
+		"collection-method": env["COLLECTION_METHOD"],
+		"grpc-server":       env["GRPC_SERVER"],
+	}, nil
+}
+
+// collectorCommand returns the command to run collector with the given
+// flags, which replaces the image's default command. The flags are added
+// to the defaults, overriding them if set in both, and are rendered as
+// --key=value, sorted by key.
+func collectorCommand(defaults map[string]string, flags map[string]string) []string {
+	merged := map[string]string{}
+	for k, v := range defaults {
+		merged[k] = v
+	}
+	for k, v := range flags {
+		merged[k] = v
+	}
+
+	keys := make([]string, 0, len(merged))
+	for k := range merged {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	cmd := []string{collectorWrapperCommand}
+	for _, k := range keys {
+		cmd = append(cmd, "--"+k+"="+merged[k])
+	}
+	return cmd
+}
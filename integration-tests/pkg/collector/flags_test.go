@@ -0,0 +1,29 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollectorCommand(t *testing.T) {
+	defaults, err := defaultFlags(
+		map[string]string{"COLLECTION_METHOD": "core-bpf", "GRPC_SERVER": "localhost:9999"},
+		map[string]any{"scrapeInterval": 2},
+	)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"collector-wrapper.sh",
+		`--collection-method=core-bpf`,
+		`--collector-config={"scrapeInterval":2}`,
+		`--grpc-server=localhost:9999`,
+	}, collectorCommand(defaults, nil))
+
+	assert.Equal(t, []string{
+		"collector-wrapper.sh",
+		`--collection-method=core-bpf`,
+		`--collector-config={"scrapeInterval":6}`,
+		`--grpc-server=localhost:9999`,
+	}, collectorCommand(defaults, map[string]string{"collector-config": `{"scrapeInterval":6}`}))
+}
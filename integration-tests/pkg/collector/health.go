@@ -0,0 +1,99 @@
+package collector
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	coreV1 "k8s.io/api/core/v1"
+)
+
+const (
+	healthCheckScript = "/usr/local/bin/status-check.sh"
+
+	// healthCheckCommand queries collector's readiness endpoint via the
+	// status check script shipped in the image. Some images are built
+	// without it, in which case the health check fails, and WaitForHealthy
+	// relies on collector's logs instead.
+	healthCheckCommand = healthCheckScript
+
+	healthCheckInterval = 5 * time.Second
+	healthCheckTimeout  = 5 * time.Second
+	healthCheckRetries  = 6
+)
+
+// healthPollInterval is how often the health status is checked while
+// waiting for collector to become healthy.
+var healthPollInterval = time.Second
+
+// startedPattern matches the line collector logs once its driver is set up
+// and it starts its services, which tells it started when the image has no
+// health check script.
+var startedPattern = regexp.MustCompile(`Network scrape interval set to`)
+
+// hasHealthCheckArgs returns the command checking that the health check
+// script is available in a running collector container.
+func hasHealthCheckArgs() []string {
+	return []string{"test", "-x", healthCheckScript}
+}
+
+// waitForLogMatch polls collector's logs until they match the pattern, or
+// the timeout expires.
+func waitForLogMatch(logs func() (string, error), pattern *regexp.Regexp, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		output, err := logs()
+		if err == nil && pattern.MatchString(output) {
+			return nil
+		}
+		if err != nil {
+			logger.Info("Retrying waitForLogMatch", "err", err)
+		}
+
+		if time.Now().Add(healthPollInterval).After(deadline) {
+			return fmt.Errorf("Timed out waiting for collector to log %q", pattern)
+		}
+		time.Sleep(healthPollInterval)
+	}
+}
+
+// healthCheckArgs returns the container runtime arguments defining the
+// collector health check.
+func healthCheckArgs() []string {
+	return []string{
+		"--health-cmd", healthCheckCommand,
+		"--health-interval", healthCheckInterval.String(),
+		"--health-start-period", healthCheckInterval.String(),
+		"--health-timeout", healthCheckTimeout.String(),
+		"--health-retries", strconv.Itoa(healthCheckRetries),
+	}
+}
+
+// healthCheckProbe returns the readiness probe of the collector pod, the
+// K8s equivalent of healthCheckArgs.
+func healthCheckProbe() *coreV1.Probe {
+	return &coreV1.Probe{
+		ProbeHandler: coreV1.ProbeHandler{
+			Exec: &coreV1.ExecAction{Command: []string{"sh", "-c", healthCheckCommand}},
+		},
+		InitialDelaySeconds: int32(healthCheckInterval.Seconds()),
+		PeriodSeconds:       int32(healthCheckInterval.Seconds()),
+		TimeoutSeconds:      int32(healthCheckTimeout.Seconds()),
+		FailureThreshold:    healthCheckRetries,
+	}
+}
+
+// isPodReady returns whether the pod reports the Ready condition.
+func isPodReady(pod *coreV1.Pod) bool {
+	if pod == nil {
+		return false
+	}
+
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == coreV1.PodReady {
+			return condition.Status == coreV1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package collector
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	coreV1 "k8s.io/api/core/v1"
+
+	"github.com/stackrox/collector/integration-tests/pkg/executor"
+)
+
+// fakeHealthExecutor is a collector container which turns from unhealthy
+// to healthy, and logs that it started, at the given times.
+type fakeHealthExecutor struct {
+	executor.Executor
+	healthyAt time.Time
+	startedAt time.Time
+	noScript  bool
+}
+
+func (f *fakeHealthExecutor) GetContainerState(containerID string) (executor.ContainerState, error) {
+	health := "unhealthy"
+	if !f.healthyAt.IsZero() && time.Now().After(f.healthyAt) {
+		health = "healthy"
+	}
+	return executor.ContainerState{Status: "running", Running: true, Health: health}, nil
+}
+
+func (f *fakeHealthExecutor) ExecWithoutRetry(args ...string) (string, error) {
+	switch args[1] {
+	case "exec":
+		if f.noScript {
+			return "", errors.New("exit status 1")
+		}
+		return "", nil
+	case "logs":
+		logs := "[INFO    2024/05/02 10:00:00] Config: collection_method:core_bpf\n"
+		if !f.startedAt.IsZero() && time.Now().After(f.startedAt) {
+			logs += "[INFO    2024/05/02 10:00:01] Network scrape interval set to 2 seconds\n"
+		}
+		return logs, nil
+	}
+	return "", fmt.Errorf("unexpected command: %v", args)
+}
+
+func withFastHealthPolls(t *testing.T) {
+	healthPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { healthPollInterval = time.Second })
+}
+
+func TestWaitForHealthy(t *testing.T) {
+	withFastHealthPolls(t)
+
+	transition := 200 * time.Millisecond
+	start := time.Now()
+	fake := &fakeHealthExecutor{healthyAt: start.Add(transition)}
+	manager := &DockerCollectorManager{executor: fake}
+
+	assert.NoError(t, manager.WaitForHealthy(5*time.Second))
+	elapsed := time.Since(start)
+	assert.GreaterOrEqual(t, elapsed, transition)
+	assert.Less(t, elapsed, transition+10*healthPollInterval,
+		"readiness was not detected promptly after the transition")
+
+	manager = &DockerCollectorManager{executor: &fakeHealthExecutor{}}
+	err := manager.WaitForHealthy(50 * time.Millisecond)
+	assert.ErrorContains(t, err, "unhealthy")
+}
+
+func TestWaitForHealthyWithoutScript(t *testing.T) {
+	withFastHealthPolls(t)
+
+	// the health check of an image without the script never passes, so
+	// the logs are used instead
+	transition := 200 * time.Millisecond
+	start := time.Now()
+	fake := &fakeHealthExecutor{noScript: true, startedAt: start.Add(transition)}
+	manager := &DockerCollectorManager{executor: fake}
+
+	assert.NoError(t, manager.WaitForHealthy(5*time.Second))
+	elapsed := time.Since(start)
+	assert.GreaterOrEqual(t, elapsed, transition)
+	assert.Less(t, elapsed, transition+10*healthPollInterval)
+
+	manager = &DockerCollectorManager{executor: &fakeHealthExecutor{noScript: true}}
+	err := manager.WaitForHealthy(50 * time.Millisecond)
+	assert.ErrorContains(t, err, "Timed out")
+}
+
+func TestIsPodReady(t *testing.T) {
+	assert.False(t, isPodReady(nil))
+	assert.False(t, isPodReady(&coreV1.Pod{}))
+	assert.False(t, isPodReady(&coreV1.Pod{Status: coreV1.PodStatus{
+		Conditions: []coreV1.PodCondition{{Type: coreV1.PodReady, Status: coreV1.ConditionFalse}},
+	}}))
+	assert.True(t, isPodReady(&coreV1.Pod{Status: coreV1.PodStatus{
+		Conditions: []coreV1.PodCondition{
+			{Type: coreV1.PodScheduled, Status: coreV1.ConditionTrue},
+			{Type: coreV1.PodReady, Status: coreV1.ConditionTrue},
+		},
+	}}))
+}
+
+func TestPodExitCode(t *testing.T) {
+	_, terminated := podExitCode(&coreV1.Pod{})
+	assert.False(t, terminated)
+
+	_, terminated = podExitCode(&coreV1.Pod{Status: coreV1.PodStatus{
+		ContainerStatuses: []coreV1.ContainerStatus{{State: coreV1.ContainerState{Running: &coreV1.ContainerStateRunning{}}}},
+	}})
+	assert.False(t, terminated)
+
+	exitCode, terminated := podExitCode(&coreV1.Pod{Status: coreV1.PodStatus{
+		ContainerStatuses: []coreV1.ContainerStatus{{State: coreV1.ContainerState{Terminated: &coreV1.ContainerStateTerminated{ExitCode: 1}}}},
+	}})
+	assert.True(t, terminated)
+	assert.Equal(t, 1, exitCode)
+}
@@ -0,0 +1,65 @@
+package collector
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/stackrox/collector/integration-tests/pkg/executor"
+)
+
+const hostEtcMount = "/host/etc:ro"
+
+// fakeHostEtc creates a copy of the host's /etc on the host, with files
+// removed or replaced as requested, to be mounted as collector's /host/etc
+// instead of the real one. The paths are relative to /etc. The caller must
+// remove the returned directory.
+func fakeHostEtc(e executor.Executor, remove []string, replace map[string]string) (string, error) {
+	for _, files := range [][]string{remove, sortedKeys(replace)} {
+		for _, file := range files {
+			if !filepath.IsLocal(file) {
+				return "", fmt.Errorf("invalid path under /etc: %q", file)
+			}
+		}
+	}
+
+	output, err := e.Exec("mktemp", "-d", "/tmp/collector-etc-XXXXXX")
+	if err != nil {
+		return "", err
+	}
+	dir := strings.TrimSpace(output)
+
+	// unreadable files do not matter for the files collector reads
+	_, err = e.Exec("sh", "-c", fmt.Sprintf("cp -a /etc/. %s/ 2>/dev/null; true", dir))
+	if err != nil {
+		return dir, err
+	}
+
+	for _, file := range remove {
+		if _, err := e.Exec("rm", "-rf", path.Join(dir, file)); err != nil {
+			return dir, err
+		}
+	}
+
+	for _, file := range sortedKeys(replace) {
+		// the file may be a symlink in /etc, which must not be followed
+		target := path.Join(dir, file)
+		_, err := e.ExecWithStdin(replace[file], "sh", "-c", fmt.Sprintf("rm -f %s && cat > %s", target, target))
+		if err != nil {
+			return dir, err
+		}
+	}
+
+	return dir, nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
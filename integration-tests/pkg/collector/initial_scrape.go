@@ -0,0 +1,63 @@
+package collector
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// networkStreamPattern matches the line collector logs once it has
+// connected its network stream to Sensor, after which the connections and
+// endpoints found by each scrape are reported.
+var networkStreamPattern = regexp.MustCompile(`(?i)established network connection info stream`)
+
+// waitForInitialScrape waits for collector to connect its network stream,
+// based on its logs, and then for the connections and endpoints found by the
+// first scrape to be reported, which the reported function checks. This fails
+// early if scraping is turned off in the configuration.
+func waitForInitialScrape(logs func() (string, error), reported func() bool, collectorConfig map[string]any, timeout time.Duration) error {
+	if off, _ := collectorConfig["turnOffScrape"].(bool); off {
+		return fmt.Errorf("scraping is turned off")
+	}
+
+	interval, err := scrapeInterval(collectorConfig)
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		output, err := logs()
+		if err == nil && networkStreamPattern.MatchString(output) {
+			break
+		}
+		if err != nil {
+			logger.Info("Retrying WaitForInitialScrape", "err", err)
+		}
+
+		if time.Now().Add(healthPollInterval).After(deadline) {
+			return fmt.Errorf("Timed out waiting for collector to connect its network stream")
+		}
+		time.Sleep(healthPollInterval)
+	}
+
+	for !reported() {
+		if time.Now().Add(healthPollInterval).After(deadline) {
+			return fmt.Errorf("Timed out waiting for the initial scrape (scrape interval: %s)", interval)
+		}
+		time.Sleep(healthPollInterval)
+	}
+	return nil
+}
+
+// scrapeInterval returns the scrape interval of the collector
+// configuration, which is a number of seconds.
+func scrapeInterval(collectorConfig map[string]any) (time.Duration, error) {
+	switch interval := collectorConfig["scrapeInterval"].(type) {
+	case int:
+		return time.Duration(interval) * time.Second, nil
+	case float64:
+		return time.Duration(interval * float64(time.Second)), nil
+	}
+	return 0, fmt.Errorf("invalid scrapeInterval in collector configuration: %v", collectorConfig["scrapeInterval"])
+}
@@ -0,0 +1,45 @@
+package collector
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWaitForInitialScrape(t *testing.T) {
+	healthPollInterval = 10 * time.Millisecond
+	defer func() { healthPollInterval = time.Second }()
+
+	calls := 0
+	logs := func() (string, error) {
+		calls++
+		if calls < 3 {
+			return "Starting collector\n", nil
+		}
+		return "Starting collector\n[I] Established network connection info stream.\n", nil
+	}
+
+	checks := 0
+	reported := func() bool {
+		checks++
+		return checks >= 2
+	}
+
+	// returns once the scrape is reported, well before the interval ends
+	scraping := map[string]any{"turnOffScrape": false, "scrapeInterval": 30}
+	start := time.Now()
+	assert.NoError(t, waitForInitialScrape(logs, reported, scraping, 5*time.Second))
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, 2, checks)
+
+	noStream := func() (string, error) { return "Starting collector\n", nil }
+	assert.ErrorContains(t, waitForInitialScrape(noStream, reported, scraping, 50*time.Millisecond), "network stream")
+
+	never := func() bool { return false }
+	assert.ErrorContains(t, waitForInitialScrape(logs, never, scraping, 50*time.Millisecond), "initial scrape")
+
+	off := map[string]any{"turnOffScrape": true, "scrapeInterval": 2}
+	assert.ErrorContains(t, waitForInitialScrape(logs, reported, off, time.Second), "turned off")
+}
@@ -0,0 +1,79 @@
+package collector
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"golang.org/x/exp/slices"
+)
+
+var (
+	// libbpf names the program in its messages, e.g.
+	// "libbpf: prog 'sys_enter': BPF program load failed: Permission denied",
+	// and libpman e.g. "libpman: failed to attach the 'sys_enter' prog"
+	probeProgramPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`prog '([^']+)'`),
+		regexp.MustCompile(`the '([^']+)' prog`),
+	}
+	probeFailurePattern = regexp.MustCompile(`(?i)fail|error|errno`)
+	// logged with the startup diagnostics
+	loadedDriverPattern = regexp.MustCompile(`Driver loaded into kernel:\s*(.+?)\s*$`)
+)
+
+// ProbeInfo describes the eBPF programs collector loaded, as far as its
+// logs tell. libbpf only names the programs it loads at trace level, while
+// failures are logged at any level.
+type ProbeInfo struct {
+	Driver   string
+	Programs []string
+	// Failures maps a program to the first log line reporting a
+	// failure to load or attach it
+	Failures map[string]string `json:",omitempty"`
+}
+
+// parseProbeInfo extracts the programs named in the logs of collector's
+// startup, and any failures to load them.
+func parseProbeInfo(logs string) ProbeInfo {
+	info := ProbeInfo{Programs: []string{}, Failures: map[string]string{}}
+
+	seen := map[string]bool{}
+	for _, line := range strings.Split(logs, "\n") {
+		if match := loadedDriverPattern.FindStringSubmatch(line); match != nil {
+			info.Driver = match[1]
+		}
+
+		for _, pattern := range probeProgramPatterns {
+			match := pattern.FindStringSubmatch(line)
+			if match == nil {
+				continue
+			}
+
+			program := match[1]
+			if !seen[program] {
+				seen[program] = true
+				info.Programs = append(info.Programs, program)
+			}
+			if _, failed := info.Failures[program]; !failed && probeFailurePattern.MatchString(line) {
+				info.Failures[program] = strings.TrimSpace(line)
+			}
+			break
+		}
+	}
+
+	sort.Strings(info.Programs)
+	return info
+}
+
+// Missing returns the expected programs that are not known to be loaded,
+// either because they failed or because they were never mentioned.
+func (p ProbeInfo) Missing(expected []string) []string {
+	missing := []string{}
+	for _, program := range expected {
+		_, failed := p.Failures[program]
+		if failed || !slices.Contains(p.Programs, program) {
+			missing = append(missing, program)
+		}
+	}
+	return missing
+}
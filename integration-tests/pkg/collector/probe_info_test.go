@@ -0,0 +1,30 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const probeLogs = `[INFO    2024/05/02 10:00:00] Driver loaded into kernel: CO-RE eBPF probe
+[TRACE   2024/05/02 10:00:00] libbpf: prog 'sys_enter': relo #0: kind <byte_off> (0)
+[TRACE   2024/05/02 10:00:00] libbpf: prog 'sys_exit': relo #0: kind <byte_off> (0)
+[TRACE   2024/05/02 10:00:00] libbpf: prog 'sched_proc_exec': relo #0: kind <byte_off> (0)
+[WARNING 2024/05/02 10:00:01] libbpf: prog 'sched_proc_exec': failed to attach: Invalid argument
+[ERROR   2024/05/02 10:00:01] libpman: failed to attach the 'sched_proc_exec' prog (errno: 22)
+[INFO    2024/05/02 10:00:02] Collector config: collection_method:CORE_BPF
+`
+
+func TestParseProbeInfo(t *testing.T) {
+	info := parseProbeInfo(probeLogs)
+
+	assert.Equal(t, "CO-RE eBPF probe", info.Driver)
+	assert.Equal(t, []string{"sched_proc_exec", "sys_enter", "sys_exit"}, info.Programs)
+	assert.Equal(t, map[string]string{
+		"sched_proc_exec": "[WARNING 2024/05/02 10:00:01] libbpf: prog 'sched_proc_exec': failed to attach: Invalid argument",
+	}, info.Failures)
+
+	assert.Equal(t, []string{"sched_proc_exec", "sched_switch"},
+		info.Missing([]string{"sys_enter", "sched_proc_exec", "sched_switch"}))
+	assert.Empty(t, info.Missing([]string{"sys_enter", "sys_exit"}))
+}
@@ -0,0 +1,102 @@
+package collector
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gonum/stat"
+)
+
+const procStatsTimeFormat = "2006-01-02 15:04:05"
+
+// ProcStats contains resource usage of the collector process itself, as
+// opposed to the whole container.
+type ProcStats struct {
+	Timestamp string
+	// Resident set size, in KiB
+	RSS     int
+	Threads int
+	OpenFDs int
+}
+
+// procStatsScript finds the collector process inside its container and prints
+// its VmRSS and Threads lines from /proc/<pid>/status, followed by the number
+// of open file descriptors.
+const procStatsScript = `
+for p in /proc/[0-9]*; do
+	if [ "$(cat $p/comm 2>/dev/null)" = "collector" ]; then
+		grep -E '^(VmRSS|Threads):' $p/status
+		echo "FDs: $(ls $p/fd | wc -l)"
+		exit 0
+	fi
+done
+exit 1
+`
+
+// parseProcStats parses the output of procStatsScript.
+func parseProcStats(output string) (ProcStats, error) {
+	stats := ProcStats{
+		Timestamp: time.Now().Format(procStatsTimeFormat),
+	}
+
+	found := 0
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		value, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return ProcStats{}, fmt.Errorf("invalid proc stats line %q: %s", line, err)
+		}
+
+		switch fields[0] {
+		case "VmRSS:":
+			stats.RSS = value
+		case "Threads:":
+			stats.Threads = value
+		case "FDs:":
+			stats.OpenFDs = value
+		default:
+			continue
+		}
+		found++
+	}
+
+	if found != 3 {
+		return ProcStats{}, fmt.Errorf("incomplete proc stats: %q", output)
+	}
+
+	return stats, nil
+}
+
+// RSSSlope returns the trend of the RSS over the samples, in KiB per minute,
+// as the slope of their linear regression. A steady upward trend over a long
+// run is the signature of a memory leak.
+func RSSSlope(samples []ProcStats) (float64, error) {
+	if len(samples) < 2 {
+		return 0, fmt.Errorf("not enough samples to compute a trend: %d", len(samples))
+	}
+
+	start, err := time.ParseInLocation(procStatsTimeFormat, samples[0].Timestamp, time.Local)
+	if err != nil {
+		return 0, err
+	}
+
+	minutes := make([]float64, len(samples))
+	rss := make([]float64, len(samples))
+	for i, sample := range samples {
+		ts, err := time.ParseInLocation(procStatsTimeFormat, sample.Timestamp, time.Local)
+		if err != nil {
+			return 0, err
+		}
+		minutes[i] = ts.Sub(start).Minutes()
+		rss[i] = float64(sample.RSS)
+	}
+
+	_, slope := stat.LinearRegression(minutes, rss, nil, false)
+	return slope, nil
+}
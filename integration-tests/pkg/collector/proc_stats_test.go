@@ -0,0 +1,29 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRSSSlope(t *testing.T) {
+	slope, err := RSSSlope([]ProcStats{
+		{Timestamp: "2024-01-01 10:00:00", RSS: 100000},
+		{Timestamp: "2024-01-01 10:01:00", RSS: 101000},
+		{Timestamp: "2024-01-01 10:02:00", RSS: 102000},
+		{Timestamp: "2024-01-01 10:03:00", RSS: 103000},
+	})
+	assert.NoError(t, err)
+	assert.InDelta(t, 1000, slope, 0.001)
+
+	slope, err = RSSSlope([]ProcStats{
+		{Timestamp: "2024-01-01 10:00:00", RSS: 100000},
+		{Timestamp: "2024-01-01 10:00:30", RSS: 100500},
+		{Timestamp: "2024-01-01 10:01:00", RSS: 100000},
+	})
+	assert.NoError(t, err)
+	assert.InDelta(t, 0, slope, 0.001)
+
+	_, err = RSSSlope([]ProcStats{{Timestamp: "2024-01-01 10:00:00"}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,62 @@
+package collector
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	WrapperGDB    = "gdb"
+	WrapperStrace = "strace"
+
+	// wrapperOutputDir is where the wrapper output is written in the
+	// collector container, if no ArtifactMount is requested.
+	wrapperOutputDir = "/var/log/collector-wrapper"
+
+	// gdbCommandsFile is the file in the output directory with the commands
+	// gdb runs, since COLLECTOR_PRE_ARGUMENTS is split on whitespace and
+	// cannot hold quoted commands.
+	gdbCommandsFile = "gdb.commands"
+)
+
+// wrapperPreArguments returns the command to prepend to the collector
+// command line to run it under the given wrapper, writing the wrapper
+// output into outputDir. The tool must be available in the collector image,
+// and the files returned by wrapperFiles must be written to outputDir.
+func wrapperPreArguments(wrapper string, outputDir string) (string, error) {
+	switch wrapper {
+	case WrapperGDB:
+		return fmt.Sprintf("gdb -batch -return-child-result -x %s --args",
+			filepath.Join(outputDir, gdbCommandsFile)), nil
+	case WrapperStrace:
+		return fmt.Sprintf("strace -f -tt -o %s", filepath.Join(outputDir, "strace.log")), nil
+	}
+	return "", fmt.Errorf("unsupported collector wrapper: %q", wrapper)
+}
+
+// withWrapper prepends the wrapper's pre-arguments to those already set,
+// so that the wrapper runs whatever collector would otherwise be run with,
+// e.g. "strace -f -tt -o /out/strace.log valgrind".
+func withWrapper(wrapperArgs string, preArguments string) string {
+	return strings.TrimSpace(wrapperArgs + " " + preArguments)
+}
+
+// wrapperFiles returns the files, by name, that the given wrapper reads
+// from outputDir.
+func wrapperFiles(wrapper string, outputDir string) map[string]string {
+	if wrapper != WrapperGDB {
+		return nil
+	}
+
+	// On a crash, the inferior stops and the remaining commands dump a
+	// backtrace of every thread before exiting with collector's status.
+	return map[string]string{
+		gdbCommandsFile: fmt.Sprintf("set logging file %s\n"+
+			"set logging overwrite on\n"+
+			"set logging on\n"+
+			"run\n"+
+			"thread apply all bt full\n",
+			filepath.Join(outputDir, "gdb.log")),
+	}
+}
@@ -0,0 +1,36 @@
+package collector
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWrapperPreArguments(t *testing.T) {
+	args, err := wrapperPreArguments(WrapperStrace, "/out")
+	assert.NoError(t, err)
+	assert.Equal(t, "strace -f -tt -o /out/strace.log", args)
+	assert.Empty(t, wrapperFiles(WrapperStrace, "/out"))
+
+	args, err = wrapperPreArguments(WrapperGDB, "/out")
+	assert.NoError(t, err)
+	assert.Equal(t, "gdb -batch -return-child-result -x /out/gdb.commands --args", args)
+	assert.NotContains(t, args, "'")
+
+	files := wrapperFiles(WrapperGDB, "/out")
+	assert.Contains(t, files, gdbCommandsFile)
+	assert.Contains(t, files[gdbCommandsFile], "set logging file /out/gdb.log\n")
+	assert.Contains(t, files[gdbCommandsFile], "run\nthread apply all bt full\n")
+
+	_, err = wrapperPreArguments("valgrind", "/out")
+	assert.Error(t, err)
+}
+
+func TestWithWrapper(t *testing.T) {
+	args, err := wrapperPreArguments(WrapperStrace, "/out")
+	assert.NoError(t, err)
+
+	assert.Equal(t, "strace -f -tt -o /out/strace.log", withWrapper(args, ""))
+	assert.Equal(t, "strace -f -tt -o /out/strace.log valgrind --leak-check=full",
+		withWrapper(args, "valgrind --leak-check=full"))
+}
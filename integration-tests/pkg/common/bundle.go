@@ -0,0 +1,140 @@
+package common
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// bundlesDir is where the artifact bundles are written, under the
+	// log directory.
+	bundlesDir = "bundles"
+
+	perfResultsFile = "perf.json"
+)
+
+// BundleArtifacts zips every artifact of the test found in the log
+// directory, i.e. the files and directories named after the test (collector
+// logs, dmesg, the mock sensor's events, perf data, and the artifacts copied
+// from collector, including core dumps), together with the test's entries of
+// the perf results. The bundle is written to a timestamped file in the
+// bundles directory under logDir, whose path is returned.
+func BundleArtifacts(logDir string, testName string, now time.Time) (string, error) {
+	name := strings.ReplaceAll(testName, "/", "_")
+
+	entries, err := os.ReadDir(logDir)
+	if err != nil {
+		return "", err
+	}
+
+	err = os.MkdirAll(filepath.Join(logDir, bundlesDir), os.ModePerm)
+	if err != nil {
+		return "", err
+	}
+
+	zipPath := filepath.Join(logDir, bundlesDir,
+		fmt.Sprintf("%s-%s.zip", name, now.Format("20060102-150405")))
+	zipFile, err := os.Create(zipPath)
+	if err != nil {
+		return "", err
+	}
+	defer zipFile.Close()
+
+	writer := zip.NewWriter(zipFile)
+
+	for _, entry := range entries {
+		if !strings.HasPrefix(entry.Name(), name+"-") && !strings.HasPrefix(entry.Name(), name+"_") {
+			continue
+		}
+
+		if err := addToZip(writer, logDir, filepath.Join(logDir, entry.Name())); err != nil {
+			return "", err
+		}
+	}
+
+	if err := addPerfResults(writer, filepath.Join(logDir, perfResultsFile), testName); err != nil {
+		return "", err
+	}
+
+	return zipPath, writer.Close()
+}
+
+// addToZip adds the file, or every regular file under the directory, to the
+// zip, named relative to baseDir.
+func addToZip(writer *zip.Writer, baseDir string, path string) error {
+	return filepath.WalkDir(path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(baseDir, path)
+		if err != nil {
+			return err
+		}
+
+		dst, err := writer.Create(filepath.ToSlash(rel))
+		if err != nil {
+			return err
+		}
+
+		src, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer src.Close()
+
+		_, err = io.Copy(dst, src)
+		return err
+	})
+}
+
+// addPerfResults adds the perf results of the test to the zip. The perf
+// results file is shared by all tests, as a stream of JSON objects.
+func addPerfResults(writer *zip.Writer, perfPath string, testName string) error {
+	perfFile, err := os.Open(perfPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer perfFile.Close()
+
+	results := []json.RawMessage{}
+	decoder := json.NewDecoder(perfFile)
+	for {
+		var result json.RawMessage
+		if err := decoder.Decode(&result); err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("failed to parse perf results: %w", err)
+		}
+
+		var header struct{ TestName string }
+		if json.Unmarshal(result, &header) == nil && header.TestName == testName {
+			results = append(results, result)
+		}
+	}
+
+	if len(results) == 0 {
+		return nil
+	}
+
+	dst, err := writer.Create(perfResultsFile)
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(dst).Encode(results)
+}
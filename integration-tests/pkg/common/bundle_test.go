@@ -0,0 +1,56 @@
+package common
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBundleArtifacts(t *testing.T) {
+	logDir := t.TempDir()
+
+	files := map[string]string{
+		"TestDNS-collector.log":            "collector",
+		"TestDNS-events.log":               "events",
+		"TestDNS-artifacts/core.1234":      "core",
+		"TestDNS_TestResolved-dmesg.log":   "dmesg",
+		"TestDNSOther-collector.log":       "other",
+		"TestProcessNetwork-collector.log": "other",
+		"perf.json":                        `{"TestName":"TestProcessNetwork"}{"TestName":"TestDNS","Metrics":{}}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(logDir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), os.ModePerm))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+
+	now := time.Date(2024, 3, 1, 12, 30, 0, 0, time.UTC)
+	zipPath, err := BundleArtifacts(logDir, "TestDNS", now)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(logDir, "bundles", "TestDNS-20240301-123000.zip"), zipPath)
+
+	reader, err := zip.OpenReader(zipPath)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer reader.Close()
+
+	names := []string{}
+	for _, f := range reader.File {
+		names = append(names, f.Name)
+	}
+	sort.Strings(names)
+
+	assert.Equal(t, []string{
+		"TestDNS-artifacts/core.1234",
+		"TestDNS-collector.log",
+		"TestDNS-events.log",
+		"TestDNS_TestResolved-dmesg.log",
+		"perf.json",
+	}, names)
+}
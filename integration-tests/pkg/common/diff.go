@@ -0,0 +1,69 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/stackrox/collector/integration-tests/pkg/types"
+)
+
+// fieldDiff accumulates mismatched fields between an expected and an
+// actual value, for concise reporting in assertion failures.
+type fieldDiff struct {
+	lines []string
+}
+
+func (d *fieldDiff) compare(field string, expected, actual any) {
+	if expected != actual {
+		d.lines = append(d.lines, fmt.Sprintf("%s: expected %s, actual %s", field, formatValue(expected), formatValue(actual)))
+	}
+}
+
+// formatValue quotes strings, so that empty values and surrounding spaces
+// stand out, and prints anything else as is.
+func formatValue(value any) string {
+	switch v := value.(type) {
+	case string:
+		return fmt.Sprintf("%q", v)
+	case int, int32, int64, uint32, uint64:
+		return fmt.Sprintf("%d", v)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+func (d *fieldDiff) String() string {
+	return strings.Join(d.lines, "\n")
+}
+
+// DiffProcess returns a field-by-field description of the differences
+// between two processes, or an empty string if they match.
+// The Pid is non-deterministic, so it is not compared.
+func DiffProcess(expected, actual types.ProcessInfo) string {
+	diff := fieldDiff{}
+	diff.compare("Name", expected.Name, actual.Name)
+	diff.compare("ExePath", expected.ExePath, actual.ExePath)
+	diff.compare("Uid", expected.Uid, actual.Uid)
+	diff.compare("Gid", expected.Gid, actual.Gid)
+	diff.compare("Args", expected.Args, actual.Args)
+	return diff.String()
+}
+
+// DiffEndpoint returns a field-by-field description of the differences
+// between two endpoints, or an empty string if they match.
+// Only the active state is compared, not the exact close timestamp, and
+// the socket family is only compared if it is expected.
+func DiffEndpoint(expected, actual types.EndpointInfo) string {
+	diff := fieldDiff{}
+	diff.compare("Protocol", expected.Protocol, actual.Protocol)
+	if !expected.SameFamily(actual) {
+		diff.compare("SocketFamily", expected.SocketFamily, actual.SocketFamily)
+	}
+	diff.compare("Address.AddressData", expected.Address.AddressData, actual.Address.AddressData)
+	diff.compare("Address.Port", expected.Address.Port, actual.Address.Port)
+	diff.compare("Address.IpNetwork", expected.Address.IpNetwork, actual.Address.IpNetwork)
+	diff.compare("IsActive", expected.IsActive(), actual.IsActive())
+	diff.compare("Originator.ProcessName", expected.Originator.ProcessName, actual.Originator.ProcessName)
+	diff.compare("Originator.ProcessExecFilePath", expected.Originator.ProcessExecFilePath, actual.Originator.ProcessExecFilePath)
+	diff.compare("Originator.ProcessArgs", expected.Originator.ProcessArgs, actual.Originator.ProcessArgs)
+	return diff.String()
+}
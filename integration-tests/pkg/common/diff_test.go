@@ -0,0 +1,38 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stackrox/collector/integration-tests/pkg/types"
+)
+
+func TestDiffProcess(t *testing.T) {
+	expected := types.ProcessInfo{Name: "ls", ExePath: "/bin/ls", Uid: 0, Gid: 0, Args: "-l"}
+
+	actual := expected
+	actual.Pid = 1234
+	assert.Empty(t, DiffProcess(expected, actual))
+
+	actual.ExePath = "/usr/bin/ls"
+	actual.Uid = 1000
+	assert.Equal(t, "ExePath: expected \"/bin/ls\", actual \"/usr/bin/ls\"\n"+
+		"Uid: expected 0, actual 1000", DiffProcess(expected, actual))
+}
+
+func TestDiffEndpoint(t *testing.T) {
+	expected := types.EndpointInfo{
+		Protocol:       "L4_PROTOCOL_TCP",
+		Address:        types.ListenAddress{Port: 80},
+		CloseTimestamp: types.NilTimestamp,
+	}
+
+	actual := expected
+	assert.Empty(t, DiffEndpoint(expected, actual))
+
+	actual.Address.Port = 8080
+	actual.CloseTimestamp = "2024-01-01 00:00:00 +0000 UTC"
+	assert.Equal(t, "Address.Port: expected 80, actual 8080\n"+
+		"IsActive: expected true, actual false", DiffEndpoint(expected, actual))
+}
@@ -0,0 +1,85 @@
+package common
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// perfSampleHeader matches the first line of a sample in perf script output,
+// e.g. "collector 1234/1240 [002] 123.456: 1 cycles:", capturing the command
+// name, which may contain spaces.
+var perfSampleHeader = regexp.MustCompile(`^(\S.*?)\s+\d+(?:/\d+)?\s`)
+
+// perfSymbolOffset matches the offset suffix of a resolved symbol.
+var perfSymbolOffset = regexp.MustCompile(`\+0x[0-9a-f]+$`)
+
+// CollapsePerfScript folds the call stacks in the output of perf script into
+// the collapsed stacks format used to render flamegraphs: one line per unique
+// stack, with the frames from the root to the leaf separated by semicolons,
+// followed by the number of samples. The lines are sorted.
+func CollapsePerfScript(r io.Reader) ([]string, error) {
+	counts := map[string]int{}
+
+	comm := ""
+	frames := []string{}
+	flush := func() {
+		if comm != "" && len(frames) > 0 {
+			stack := []string{comm}
+			for i := len(frames) - 1; i >= 0; i-- {
+				stack = append(stack, frames[i])
+			}
+			counts[strings.Join(stack, ";")]++
+		}
+		comm = ""
+		frames = frames[:0]
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case strings.TrimSpace(line) == "":
+			flush()
+		case line[0] != ' ' && line[0] != '\t':
+			flush()
+			if match := perfSampleHeader.FindStringSubmatch(line); match != nil {
+				comm = match[1]
+			}
+		case comm != "":
+			frames = append(frames, perfFrameSymbol(line))
+		}
+	}
+	flush()
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read perf script output: %w", err)
+	}
+
+	stacks := make([]string, 0, len(counts))
+	for stack, count := range counts {
+		stacks = append(stacks, fmt.Sprintf("%s %d", stack, count))
+	}
+	sort.Strings(stacks)
+	return stacks, nil
+}
+
+// perfFrameSymbol extracts the symbol from a stack frame line of perf
+// script, e.g. "\t ffffffff8108c3a5 do_syscall_64+0x35 ([kernel.kallsyms])".
+func perfFrameSymbol(line string) string {
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return "[unknown]"
+	}
+
+	symbol := strings.Join(fields[1:], " ")
+	if i := strings.LastIndex(symbol, " ("); i >= 0 {
+		symbol = symbol[:i]
+	}
+	return perfSymbolOffset.ReplaceAllString(symbol, "")
+}
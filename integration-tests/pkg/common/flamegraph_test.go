@@ -0,0 +1,33 @@
+package common
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const perfScriptOutput = `collector 1234/1240 [002] 100.000001:     250000 cycles:
+	ffffffff8108c3a5 do_syscall_64+0x35 ([kernel.kallsyms])
+	    7f0000001000 read+0x10 (/usr/lib64/libc.so.6)
+	          401000 main+0x20 (/usr/local/bin/collector)
+
+collector 1234/1240 [002] 100.000002:     250000 cycles:
+	ffffffff8108c3a5 do_syscall_64+0x35 ([kernel.kallsyms])
+	    7f0000001000 read+0x10 (/usr/lib64/libc.so.6)
+	          401000 main+0x20 (/usr/local/bin/collector)
+
+grpc worker 1234/1241 [001] 100.000003:     250000 cycles:
+	          402000 [unknown] (/usr/local/bin/collector)
+
+swapper     0 [000] 100.000004:     250000 cycles:
+`
+
+func TestCollapsePerfScript(t *testing.T) {
+	stacks, err := CollapsePerfScript(strings.NewReader(perfScriptOutput))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"collector;main;read;do_syscall_64 2",
+		"grpc worker;[unknown] 1",
+	}, stacks)
+}
@@ -0,0 +1,154 @@
+package common
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LogMatcher evaluates expected and forbidden patterns against a stream of
+// log lines as they are read. Patterns registered after some lines have
+// already been read are also matched against those lines.
+type LogMatcher struct {
+	logs io.ReadCloser
+
+	mutex     sync.Mutex
+	lines     []string
+	expected  []*regexp.Regexp
+	forbidden []*regexp.Regexp
+	failure   error
+	closed    bool
+
+	// updated is closed and replaced whenever the state changes, to wake up
+	// any waiters.
+	updated chan struct{}
+}
+
+// NewLogMatcher starts reading lines from logs. The matcher takes ownership
+// of logs, which is closed by Stop.
+func NewLogMatcher(logs io.ReadCloser) *LogMatcher {
+	m := &LogMatcher{
+		logs:    logs,
+		updated: make(chan struct{}),
+	}
+	go m.read()
+	return m
+}
+
+func (m *LogMatcher) read() {
+	scanner := bufio.NewScanner(m.logs)
+	for scanner.Scan() {
+		m.mutex.Lock()
+		m.lines = append(m.lines, scanner.Text())
+		m.evaluateForbidden(m.forbidden, m.lines[len(m.lines)-1:])
+		m.notify()
+		m.mutex.Unlock()
+	}
+
+	m.mutex.Lock()
+	m.closed = true
+	m.notify()
+	m.mutex.Unlock()
+}
+
+// notify must be called with the mutex held.
+func (m *LogMatcher) notify() {
+	close(m.updated)
+	m.updated = make(chan struct{})
+}
+
+// evaluateForbidden must be called with the mutex held.
+func (m *LogMatcher) evaluateForbidden(patterns []*regexp.Regexp, lines []string) {
+	if m.failure != nil {
+		return
+	}
+	for _, line := range lines {
+		for _, pattern := range patterns {
+			if pattern.MatchString(line) {
+				m.failure = fmt.Errorf("forbidden pattern %q found in log line: %s", pattern, line)
+				return
+			}
+		}
+	}
+}
+
+// Expect registers a pattern that must appear in the logs.
+func (m *LogMatcher) Expect(pattern string) *LogMatcher {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.expected = append(m.expected, regexp.MustCompile(pattern))
+	return m
+}
+
+// Forbid registers a pattern that must never appear in the logs.
+func (m *LogMatcher) Forbid(pattern string) *LogMatcher {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	re := regexp.MustCompile(pattern)
+	m.forbidden = append(m.forbidden, re)
+	m.evaluateForbidden([]*regexp.Regexp{re}, m.lines)
+	m.notify()
+	return m
+}
+
+// Err returns the first forbidden pattern match, if any.
+func (m *LogMatcher) Err() error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	return m.failure
+}
+
+// missing must be called with the mutex held.
+func (m *LogMatcher) missing() []string {
+	missing := []string{}
+	for _, pattern := range m.expected {
+		found := false
+		for _, line := range m.lines {
+			if pattern.MatchString(line) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, pattern.String())
+		}
+	}
+	return missing
+}
+
+// Wait blocks until all expected patterns have been seen. It returns an
+// error as soon as a forbidden pattern is seen, or if the timeout expires
+// or the logs end before all expected patterns are found.
+func (m *LogMatcher) Wait(timeout time.Duration) error {
+	timer := time.After(timeout)
+	for {
+		m.mutex.Lock()
+		failure, missing, closed, updated := m.failure, m.missing(), m.closed, m.updated
+		m.mutex.Unlock()
+
+		if failure != nil {
+			return failure
+		}
+		if len(missing) == 0 {
+			return nil
+		}
+		if closed {
+			return fmt.Errorf("logs ended before finding: %s", strings.Join(missing, ", "))
+		}
+
+		select {
+		case <-timer:
+			return fmt.Errorf("timed out waiting for: %s", strings.Join(missing, ", "))
+		case <-updated:
+		}
+	}
+}
+
+// Stop stops reading the logs.
+func (m *LogMatcher) Stop() error {
+	return m.logs.Close()
+}
@@ -0,0 +1,69 @@
+package common
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogMatcherExpected(t *testing.T) {
+	reader, writer := io.Pipe()
+	matcher := NewLogMatcher(reader)
+	defer matcher.Stop()
+
+	io.WriteString(writer, "starting\n")
+	matcher.Expect("^starting$").Expect("ready on port [0-9]+")
+
+	go io.WriteString(writer, "ready on port 8080\n")
+
+	assert.NoError(t, matcher.Wait(5*time.Second))
+}
+
+func TestLogMatcherForbidden(t *testing.T) {
+	reader, writer := io.Pipe()
+	matcher := NewLogMatcher(reader)
+	defer matcher.Stop()
+
+	matcher.Expect("ready").Forbid("(?i)error")
+
+	go io.WriteString(writer, "ERROR: failed to bind\n")
+
+	err := matcher.Wait(5 * time.Second)
+	assert.ErrorContains(t, err, "failed to bind")
+	assert.Equal(t, err, matcher.Err())
+}
+
+func TestLogMatcherForbiddenAlreadySeen(t *testing.T) {
+	reader, writer := io.Pipe()
+	matcher := NewLogMatcher(reader)
+	defer matcher.Stop()
+
+	go io.WriteString(writer, "panic: oops\n")
+	assert.NoError(t, matcher.Expect("oops").Wait(5*time.Second))
+
+	matcher.Forbid("panic")
+
+	assert.Error(t, matcher.Err())
+}
+
+func TestLogMatcherTimeout(t *testing.T) {
+	reader, _ := io.Pipe()
+	matcher := NewLogMatcher(reader)
+	defer matcher.Stop()
+
+	matcher.Expect("never")
+
+	assert.ErrorContains(t, matcher.Wait(10*time.Millisecond), "timed out")
+}
+
+func TestLogMatcherLogsEnded(t *testing.T) {
+	reader, writer := io.Pipe()
+	matcher := NewLogMatcher(reader)
+
+	matcher.Expect("never")
+	writer.Close()
+
+	assert.ErrorContains(t, matcher.Wait(5*time.Second), "logs ended")
+}
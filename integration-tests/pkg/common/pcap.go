@@ -0,0 +1,160 @@
+package common
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"os"
+)
+
+const (
+	pcapMagic      = 0xa1b2c3d4
+	pcapMagicNanos = 0xa1b23c4d
+
+	linkTypeEthernet = 1
+	linkTypeLinuxSLL = 113
+
+	etherTypeIPv4 = 0x0800
+	etherTypeIPv6 = 0x86dd
+
+	protocolTCP = 6
+
+	tcpFlagSYN = 0x02
+	tcpFlagACK = 0x10
+)
+
+// Conn is a TCP connection observed on the wire, identified by its 4-tuple.
+type Conn struct {
+	ClientAddr string
+	ClientPort int
+	ServerAddr string
+	ServerPort int
+}
+
+func (c Conn) String() string {
+	return fmt.Sprintf("%s -> %s",
+		net.JoinHostPort(c.ClientAddr, fmt.Sprint(c.ClientPort)),
+		net.JoinHostPort(c.ServerAddr, fmt.Sprint(c.ServerPort)))
+}
+
+// ParsePcapConnections reads a capture file in the classic pcap format (as
+// written by tcpdump -w) and returns the TCP connections that were opened
+// during the capture, i.e. those for which the initial SYN was observed.
+// Each connection is only reported once, in the order first seen.
+func ParsePcapConnections(path string) ([]Conn, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return parsePcapConnections(f)
+}
+
+func parsePcapConnections(r io.Reader) ([]Conn, error) {
+	header := make([]byte, 24)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("failed to read pcap header: %w", err)
+	}
+
+	var order binary.ByteOrder
+	switch magic := binary.LittleEndian.Uint32(header); magic {
+	case pcapMagic, pcapMagicNanos:
+		order = binary.LittleEndian
+	default:
+		if magic := binary.BigEndian.Uint32(header); magic != pcapMagic && magic != pcapMagicNanos {
+			return nil, fmt.Errorf("not a pcap file (magic %#x)", magic)
+		}
+		order = binary.BigEndian
+	}
+
+	linkType := order.Uint32(header[20:])
+	if linkType != linkTypeEthernet && linkType != linkTypeLinuxSLL {
+		return nil, fmt.Errorf("unsupported pcap link type %d", linkType)
+	}
+
+	conns := []Conn{}
+	seen := map[Conn]bool{}
+	record := make([]byte, 16)
+
+	for {
+		if _, err := io.ReadFull(r, record); err == io.EOF {
+			return conns, nil
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read pcap record: %w", err)
+		}
+
+		packet := make([]byte, order.Uint32(record[8:]))
+		if _, err := io.ReadFull(r, packet); err != nil {
+			return nil, fmt.Errorf("failed to read pcap packet: %w", err)
+		}
+
+		conn, ok := parseTCPOpen(linkType, packet)
+		if ok && !seen[conn] {
+			seen[conn] = true
+			conns = append(conns, conn)
+		}
+	}
+}
+
+// parseTCPOpen returns the connection a packet belongs to, if the packet is
+// the initial SYN of a TCP connection. Truncated or unrelated packets are
+// ignored.
+func parseTCPOpen(linkType uint32, packet []byte) (Conn, bool) {
+	var etherType uint16
+	switch linkType {
+	case linkTypeEthernet:
+		if len(packet) < 14 {
+			return Conn{}, false
+		}
+		etherType = binary.BigEndian.Uint16(packet[12:])
+		packet = packet[14:]
+	case linkTypeLinuxSLL:
+		if len(packet) < 16 {
+			return Conn{}, false
+		}
+		etherType = binary.BigEndian.Uint16(packet[14:])
+		packet = packet[16:]
+	}
+
+	var src, dst net.IP
+	switch etherType {
+	case etherTypeIPv4:
+		if len(packet) < 20 {
+			return Conn{}, false
+		}
+		headerLen := int(packet[0]&0x0f) * 4
+		if packet[9] != protocolTCP || len(packet) < headerLen {
+			return Conn{}, false
+		}
+		src, dst = net.IP(packet[12:16]), net.IP(packet[16:20])
+		packet = packet[headerLen:]
+	case etherTypeIPv6:
+		// extension headers are not handled, they are not expected
+		// for the traffic generated by the tests
+		if len(packet) < 40 || packet[6] != protocolTCP {
+			return Conn{}, false
+		}
+		src, dst = net.IP(packet[8:24]), net.IP(packet[24:40])
+		packet = packet[40:]
+	default:
+		return Conn{}, false
+	}
+
+	if len(packet) < 14 {
+		return Conn{}, false
+	}
+
+	flags := packet[13]
+	if flags&tcpFlagSYN == 0 || flags&tcpFlagACK != 0 {
+		return Conn{}, false
+	}
+
+	return Conn{
+		ClientAddr: src.String(),
+		ClientPort: int(binary.BigEndian.Uint16(packet[0:])),
+		ServerAddr: dst.String(),
+		ServerPort: int(binary.BigEndian.Uint16(packet[2:])),
+	}, true
+}
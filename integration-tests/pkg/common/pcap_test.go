@@ -0,0 +1,69 @@
+package common
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func tcpv4Packet(src, dst [4]byte, srcPort, dstPort uint16, flags byte) []byte {
+	packet := make([]byte, 14+20+20)
+	binary.BigEndian.PutUint16(packet[12:], etherTypeIPv4)
+
+	ip := packet[14:]
+	ip[0] = 0x45
+	ip[9] = protocolTCP
+	copy(ip[12:], src[:])
+	copy(ip[16:], dst[:])
+
+	tcp := ip[20:]
+	binary.BigEndian.PutUint16(tcp[0:], srcPort)
+	binary.BigEndian.PutUint16(tcp[2:], dstPort)
+	tcp[13] = flags
+	return packet
+}
+
+func pcapFile(packets ...[]byte) []byte {
+	var buf bytes.Buffer
+	header := make([]byte, 24)
+	binary.LittleEndian.PutUint32(header[0:], pcapMagic)
+	binary.LittleEndian.PutUint32(header[20:], linkTypeEthernet)
+	buf.Write(header)
+
+	for _, packet := range packets {
+		record := make([]byte, 16)
+		binary.LittleEndian.PutUint32(record[8:], uint32(len(packet)))
+		binary.LittleEndian.PutUint32(record[12:], uint32(len(packet)))
+		buf.Write(record)
+		buf.Write(packet)
+	}
+	return buf.Bytes()
+}
+
+func TestParsePcapConnections(t *testing.T) {
+	client := [4]byte{172, 17, 0, 3}
+	server := [4]byte{172, 17, 0, 2}
+
+	capture := pcapFile(
+		tcpv4Packet(client, server, 40000, 80, tcpFlagSYN),
+		tcpv4Packet(server, client, 80, 40000, tcpFlagSYN|tcpFlagACK),
+		tcpv4Packet(client, server, 40000, 80, tcpFlagACK),
+		// retransmitted SYN, reported once
+		tcpv4Packet(client, server, 40000, 80, tcpFlagSYN),
+		tcpv4Packet(client, server, 40002, 443, tcpFlagSYN),
+	)
+
+	conns, err := parsePcapConnections(bytes.NewReader(capture))
+	assert.NoError(t, err)
+	assert.Equal(t, []Conn{
+		{ClientAddr: "172.17.0.3", ClientPort: 40000, ServerAddr: "172.17.0.2", ServerPort: 80},
+		{ClientAddr: "172.17.0.3", ClientPort: 40002, ServerAddr: "172.17.0.2", ServerPort: 443},
+	}, conns)
+}
+
+func TestParsePcapConnectionsInvalid(t *testing.T) {
+	_, err := parsePcapConnections(bytes.NewReader(make([]byte, 24)))
+	assert.Error(t, err)
+}
@@ -1,8 +1,10 @@
 package common
 
 import (
+	"archive/tar"
 	"bytes"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -44,6 +46,16 @@ func Min(x int, y int) int {
 	return y
 }
 
+// RepeatString repeats s until the result is exactly n bytes long, cutting
+// the last repetition short if needed. It is useful to build long messages
+// and arguments of a precise size.
+func RepeatString(s string, n int) string {
+	if len(s) == 0 || n <= 0 {
+		return ""
+	}
+	return strings.Repeat(s, n/len(s)+1)[:n]
+}
+
 // Identifies if the current architecture is in the specified supported list.
 // Returns a boolean flag indicatind the result, and the actual architecture,
 // that was discovered.
@@ -68,6 +80,67 @@ func PrepareLog(testName string, logName string) (*os.File, error) {
 	return os.Create(logPath)
 }
 
+// ExtractArtifacts extracts a tarball of the files collector wrote into a
+// per-test artifacts directory next to the logs, preserving the directory
+// layout.
+func ExtractArtifacts(testName string, tarPath string) error {
+	dstDir := filepath.Join(config.LogPath(), strings.ReplaceAll(testName, "/", "_")+"-artifacts")
+
+	in, err := os.Open(tarPath)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	return extractTar(in, dstDir)
+}
+
+// extractTar extracts the directories and regular files of a tarball under
+// dstDir. Entries with paths outside of dstDir are rejected.
+func extractTar(r io.Reader, dstDir string) error {
+	reader := tar.NewReader(r)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		name := filepath.Clean(header.Name)
+		if !filepath.IsLocal(name) && name != "." {
+			return fmt.Errorf("invalid path in artifacts: %q", header.Name)
+		}
+		dst := filepath.Join(dstDir, name)
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(dst, os.ModePerm); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(dst), os.ModePerm); err != nil {
+				return err
+			}
+			if err := writeFile(dst, reader); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func writeFile(dst string, content io.Reader) error {
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	_, err = io.Copy(out, content)
+	return err
+}
+
 func Sleep(duration time.Duration) {
 	_, filename, line, _ := runtime.Caller(1)
 	fmt.Printf("%s:%d sleeping for %f s\n", filename, line, duration.Seconds())
@@ -0,0 +1,49 @@
+package common
+
+import (
+	"archive/tar"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepeatString(t *testing.T) {
+	assert.Equal(t, "abcab", RepeatString("abc", 5))
+	assert.Equal(t, "abcabc", RepeatString("abc", 6))
+	assert.Len(t, RepeatString("x ", 8192), 8192)
+	assert.Equal(t, "", RepeatString("", 10))
+	assert.Equal(t, "", RepeatString("abc", 0))
+}
+
+func TestExtractTar(t *testing.T) {
+	archive := func(entries map[string]string) *bytes.Buffer {
+		var buf bytes.Buffer
+		writer := tar.NewWriter(&buf)
+		writer.WriteHeader(&tar.Header{Name: "./", Typeflag: tar.TypeDir, Mode: 0755})
+		for name, content := range entries {
+			writer.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Mode: 0644, Size: int64(len(content))})
+			writer.Write([]byte(content))
+		}
+		writer.Close()
+		return &buf
+	}
+
+	dir := t.TempDir()
+	assert.NoError(t, extractTar(archive(map[string]string{
+		"./core.1234":     "core",
+		"./gdb/backtrace": "bt",
+	}), dir))
+
+	content, err := os.ReadFile(filepath.Join(dir, "core.1234"))
+	assert.NoError(t, err)
+	assert.Equal(t, "core", string(content))
+
+	content, err = os.ReadFile(filepath.Join(dir, "gdb", "backtrace"))
+	assert.NoError(t, err)
+	assert.Equal(t, "bt", string(content))
+
+	assert.Error(t, extractTar(archive(map[string]string{"../escape": "x"}), t.TempDir()))
+}
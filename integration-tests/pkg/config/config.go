@@ -3,6 +3,8 @@ package config
 import (
 	"os"
 	"path/filepath"
+	"sync"
+	"time"
 )
 
 const (
@@ -14,6 +16,14 @@ const (
 
 	imageStoreLocation = "images.yml"
 
+	defaultImagePullConcurrency = 3
+
+	defaultAbortLogLines = 100
+
+	// defaultSensorStartupTimeout bounds the wait for the mock Sensor to
+	// accept connections before collector is launched.
+	defaultSensorStartupTimeout = 10 * time.Second
+
 	// defaultStopTimeoutSeconds is the amount of time to wait for a container
 	// to stop before forcibly killing it. It needs to be a string because it
 	// is passed directly to the docker command via the executor.
@@ -21,12 +31,20 @@ const (
 	// 10 seconds is the default for docker stop when not providing a timeout
 	// argument. It is kept the same here to avoid changing behavior by default.
 	defaultStopTimeoutSeconds = "10"
+
+	// defaultRuntimeCommandTimeout bounds how long a runtime command may
+	// run, generous enough for pulls of large images.
+	defaultRuntimeCommandTimeout = 10 * time.Minute
+
+	defaultPollInitialInterval = 50 * time.Millisecond
+	defaultPollMaxInterval     = 2 * time.Second
 )
 
 var (
 	qa_tag            = ReadEnvVar(envQATag)
 	collection_method = ReadEnvVarWithDefault(envCollectionMethod, CollectionMethodCoreBPF)
 	stop_timeout      = ReadEnvVarWithDefault(envStopTimeout, defaultStopTimeoutSeconds)
+	log_format        = ReadEnvVarWithDefault(envLogFormat, "text")
 
 	image_store       *ImageStore
 	collector_options *CollectorOptions
@@ -34,6 +52,17 @@ var (
 	host_options      *Host
 	vm_options        *VM
 	benchmarks        *Benchmarks
+	polling_options   *Polling
+
+	// the options are loaded once, on first use, which may be from
+	// concurrent goroutines, e.g. parallel image pulls
+	image_store_once       sync.Once
+	collector_options_once sync.Once
+	runtime_options_once   sync.Once
+	host_options_once      sync.Once
+	vm_options_once        sync.Once
+	benchmarks_once        sync.Once
+	polling_options_once   sync.Once
 )
 
 func init() {
@@ -49,6 +78,12 @@ func init() {
 // which the tests are running
 type Host struct {
 	Kind string
+	// User and Address to connect to a remote host as
+	User    string
+	Address string
+	// Options are additional arguments for the connection to a remote
+	// host, e.g. "-i ~/.ssh/key -p 2222" for ssh
+	Options string
 }
 
 func (h *Host) IsLocal() bool {
@@ -59,6 +94,10 @@ func (h *Host) IsK8s() bool {
 	return h.Kind == "k8s"
 }
 
+func (h *Host) IsSSH() bool {
+	return h.Kind == "ssh"
+}
+
 // VM contains metadata about the machine upon which the tests are
 // running.
 type VM struct {
@@ -75,6 +114,8 @@ type Runtime struct {
 	// Whether or not interactions with this runtime should be run
 	// as root
 	RunAsRoot bool
+	// How long a runtime command may run before it is killed
+	CommandTimeout time.Duration
 }
 
 // CollectorOptions contains options related to running collector itself
@@ -91,10 +132,30 @@ type Benchmarks struct {
 	BpftraceCommand string
 	PerfCommand     string
 	SkipInit        bool
+	// How long to run the workload for before measuring,
+	// to let caches and the like settle
+	WarmupDuration time.Duration
+	// How much work the berserker workloads do, relative to their default
+	// configuration. Zero keeps the default.
+	WorkloadScale int
+	// How long the soak test runs the workload for
+	SoakDuration time.Duration
+	// The maximum growth of collector's RSS over the soak test, in KiB
+	// per minute, before it is considered a leak
+	SoakMaxRSSSlope int64
+}
+
+// Polling contains options controlling how often expectations on received
+// signals are checked while waiting for them
+type Polling struct {
+	// The first interval between checks, which doubles after each check
+	InitialInterval time.Duration
+	// The upper bound of the interval between checks
+	MaxInterval time.Duration
 }
 
 func Images() *ImageStore {
-	if image_store == nil {
+	image_store_once.Do(func() {
 		var err error
 		image_store, err = loadImageStore(imageStoreLocation)
 		if err != nil {
@@ -102,7 +163,7 @@ func Images() *ImageStore {
 			// load the image store, so simply panic
 			panic(err)
 		}
-	}
+	})
 	return image_store
 }
 
@@ -110,63 +171,123 @@ func CollectionMethod() string {
 	return collection_method
 }
 
+// AbortLogLines is how many of the last collector log lines are kept as
+// context when collector aborts on a failed assertion.
+func AbortLogLines() int {
+	if lines := ReadIntEnvVar(envAbortLogLines); lines > 0 {
+		return int(lines)
+	}
+	return defaultAbortLogLines
+}
+
+// SensorStartupTimeout is how long to wait for the mock Sensor to accept
+// connections before launching collector.
+func SensorStartupTimeout() time.Duration {
+	if timeout := ReadDurationEnvVar(envSensorStartupTimeout); timeout > 0 {
+		return timeout
+	}
+	return defaultSensorStartupTimeout
+}
+
+// VerifyCleanup is whether each suite fails if it leaves containers or
+// networks behind once torn down. It is off by default, as the resources
+// of other tests may coexist on shared hosts.
+func VerifyCleanup() bool {
+	return ReadBoolEnvVar(envVerifyCleanup)
+}
+
 func StopTimeout() string {
 	return stop_timeout
 }
 
+// LogFormat is the format of the harness' own log lines,
+// either text (the default) or json.
+func LogFormat() string {
+	return log_format
+}
+
 func HostInfo() *Host {
-	if host_options == nil {
+	host_options_once.Do(func() {
 		host_options = &Host{
-			Kind: ReadEnvVarWithDefault(envHostType, "local"),
+			Kind:    ReadEnvVarWithDefault(envHostType, "local"),
+			User:    ReadEnvVar(envHostUser),
+			Address: ReadEnvVar(envHostAddress),
+			Options: ReadEnvVar(envHostOptions),
 		}
-	}
+	})
 
 	return host_options
 }
 
 func VMInfo() *VM {
-	if vm_options == nil {
+	vm_options_once.Do(func() {
 		vm_options = &VM{
 			InstanceType: ReadEnvVarWithDefault(envVMInstanceType, "default"),
 			Config:       ReadEnvVar(envVMConfig),
 		}
-	}
+	})
 	return vm_options
 }
 
 func RuntimeInfo() *Runtime {
-	if runtime_options == nil {
+	runtime_options_once.Do(func() {
 		runtime_options = &Runtime{
 			Command:   ReadEnvVarWithDefault(envRuntimeCommand, runtimeDefaultCommand),
 			Socket:    ReadEnvVarWithDefault(envRuntimeSocket, runtimeDefaultSocket),
 			RunAsRoot: ReadBoolEnvVar(envRuntimeAsRoot),
 		}
-	}
+
+		runtime_options.CommandTimeout = ReadDurationEnvVar(envRuntimeCommandTimeout)
+		if runtime_options.CommandTimeout == 0 {
+			runtime_options.CommandTimeout = defaultRuntimeCommandTimeout
+		}
+	})
 	return runtime_options
 }
 
 func CollectorInfo() *CollectorOptions {
-	if collector_options == nil {
+	collector_options_once.Do(func() {
 		collector_options = &CollectorOptions{
 			LogLevel:     ReadEnvVarWithDefault(envCollectorLogLevel, "debug"),
 			PreArguments: ReadEnvVar(envCollectorPreArguments),
 		}
-	}
+	})
 	return collector_options
 }
 
 func BenchmarksInfo() *Benchmarks {
-	if benchmarks == nil {
+	benchmarks_once.Do(func() {
 		benchmarks = &Benchmarks{
 			BccCommand:      ReadEnvVar(envBccCommand),
 			BpftraceCommand: ReadEnvVar(envBpftraceCommand),
 			PerfCommand:     ReadEnvVar(envPerfCommand),
 			SkipInit:        ReadBoolEnvVar(envSkipHeadersInit),
+			WarmupDuration:  ReadDurationEnvVar(envWarmupDuration),
+			WorkloadScale:   int(ReadIntEnvVar(envWorkloadScale)),
+			SoakDuration:    ReadDurationEnvVar(envSoakDuration),
+			SoakMaxRSSSlope: ReadIntEnvVar(envSoakMaxRSSSlope),
 		}
-	}
+	})
 	return benchmarks
 }
 
+func PollingInfo() *Polling {
+	polling_options_once.Do(func() {
+		polling_options = &Polling{
+			InitialInterval: ReadDurationEnvVar(envPollInitialInterval),
+			MaxInterval:     ReadDurationEnvVar(envPollMaxInterval),
+		}
+
+		if polling_options.InitialInterval <= 0 {
+			polling_options.InitialInterval = defaultPollInitialInterval
+		}
+		if polling_options.MaxInterval <= 0 {
+			polling_options.MaxInterval = defaultPollMaxInterval
+		}
+	})
+	return polling_options
+}
+
 func LogPath() string {
 	return filepath.Join(".", "container-logs", VMInfo().Config, CollectionMethod())
 }
@@ -3,16 +3,30 @@ package config
 import (
 	"os"
 	"strconv"
+	"time"
 )
 
 const (
 	envCollectionMethod = "COLLECTION_METHOD"
 	envCollectorImage   = "COLLECTOR_IMAGE"
 
+	envImageRegistryMirror  = "IMAGE_REGISTRY_MIRROR"
+	envImagePullPolicy      = "IMAGE_PULL_POLICY"
+	envImagePullConcurrency = "IMAGE_PULL_CONCURRENCY"
+
+	envQAImageOverridePrefix = "QA_IMAGE_OVERRIDE_"
+
 	envCollectorLogLevel     = "COLLECTOR_LOG_LEVEL"
 	envCollectorPreArguments = "COLLECTOR_PRE_ARGUMENTS"
+	envAbortLogLines         = "COLLECTOR_ABORT_LOG_LINES"
 
-	envHostType = "REMOTE_HOST_TYPE"
+	envSensorStartupTimeout = "SENSOR_STARTUP_TIMEOUT"
+	envVerifyCleanup        = "VERIFY_CLEANUP"
+
+	envHostType    = "REMOTE_HOST_TYPE"
+	envHostUser    = "REMOTE_HOST_USER"
+	envHostAddress = "REMOTE_HOST_ADDRESS"
+	envHostOptions = "REMOTE_HOST_OPTIONS"
 
 	envVMInstanceType = "VM_INSTANCE_TYPE"
 	envVMConfig       = "VM_CONFIG"
@@ -21,14 +35,25 @@ const (
 	envRuntimeSocket  = "RUNTIME_SOCKET"
 	envRuntimeAsRoot  = "RUNTIME_AS_ROOT"
 
+	envRuntimeCommandTimeout = "RUNTIME_COMMAND_TIMEOUT"
+
 	envQATag = "COLLECTOR_QA_TAG"
 
 	envPerfCommand     = "COLLECTOR_PERF_COMMAND"
 	envBpftraceCommand = "COLLECTOR_BPFTRACE_COMMAND"
 	envBccCommand      = "COLLECTOR_BCC_COMMAND"
 	envSkipHeadersInit = "COLLECTOR_SKIP_HEADERS_INIT"
+	envWarmupDuration  = "COLLECTOR_BENCHMARK_WARMUP"
+	envWorkloadScale   = "COLLECTOR_BENCHMARK_SCALE"
+	envSoakDuration    = "COLLECTOR_SOAK_DURATION"
+	envSoakMaxRSSSlope = "COLLECTOR_SOAK_MAX_RSS_SLOPE"
 
 	envStopTimeout = "STOP_TIMEOUT"
+
+	envLogFormat = "LOG_FORMAT"
+
+	envPollInitialInterval = "POLL_INITIAL_INTERVAL"
+	envPollMaxInterval     = "POLL_MAX_INTERVAL"
 )
 
 // ReadEnvVar safely reads a variable from the environment.
@@ -58,3 +83,24 @@ func ReadBoolEnvVar(env string) bool {
 	}
 	return e
 }
+
+// ReadIntEnvVar safely reads an integer value from the environment.
+// If the variable does not exist or is invalid, the result is zero.
+func ReadIntEnvVar(env string) int64 {
+	i, err := strconv.ParseInt(ReadEnvVarWithDefault(env, "0"), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return i
+}
+
+// ReadDurationEnvVar safely reads a duration (e.g. "30s") from the environment,
+// parsed into a time.Duration. If the variable does not exist or is invalid,
+// the result is zero.
+func ReadDurationEnvVar(env string) time.Duration {
+	d, err := time.ParseDuration(ReadEnvVarWithDefault(env, "0"))
+	if err != nil {
+		return 0
+	}
+	return d
+}
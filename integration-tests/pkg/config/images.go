@@ -8,37 +8,94 @@ import (
 )
 
 type ImageStore struct {
-	qaTag string
-	Qa    map[string]string
-	NonQa map[string]string `yaml:"non_qa"`
+	qaTag  string
+	mirror string
+	Qa     map[string]string
+	NonQa  map[string]string `yaml:"non_qa"`
 }
 
 func (i *ImageStore) CollectorImage() string {
-	return ReadEnvVar(envCollectorImage)
+	return MirrorImage(ReadEnvVar(envCollectorImage), i.mirror)
 }
 
 // ImageByKey looks up an image from the store, and panics
 // if the image does not exist.
 func (i *ImageStore) ImageByKey(key string) string {
 	if img, ok := i.NonQa[key]; ok {
-		return img
+		return MirrorImage(img, i.mirror)
 	}
 	panic("failed to find image: " + key)
 }
 
+// RegistryMirror returns the registry mirror that all images are
+// rewritten to, or an empty string if no mirror is configured.
+func RegistryMirror() string {
+	return ReadEnvVar(envImageRegistryMirror)
+}
+
+// ImagePullPolicy returns the policy governing when images are pulled,
+// one of Always, IfNotPresent (the default) or Never.
+func ImagePullPolicy() string {
+	return ReadEnvVarWithDefault(envImagePullPolicy, "IfNotPresent")
+}
+
+// ImagePullConcurrency returns how many images may be pulled at once, kept
+// small by default to avoid being rate limited by registries.
+func ImagePullConcurrency() int {
+	if concurrency := ReadIntEnvVar(envImagePullConcurrency); concurrency > 0 {
+		return int(concurrency)
+	}
+	return defaultImagePullConcurrency
+}
+
+// MirrorImage rewrites an image reference to be pulled from the given
+// mirror, by prefixing it with the mirror and the fully qualified original
+// registry, e.g. quay.io/org/image:tag -> mirror.internal/quay.io/org/image:tag
+// Images without an explicit registry are assumed to come from docker.io.
+// The rewrite is idempotent, and a no-op if the mirror is empty.
+func MirrorImage(image string, mirror string) string {
+	mirror = strings.TrimSuffix(mirror, "/")
+	if mirror == "" || image == "" || strings.HasPrefix(image, mirror+"/") {
+		return image
+	}
+
+	registry, path, found := strings.Cut(image, "/")
+	if !found || (!strings.ContainsAny(registry, ".:") && registry != "localhost") {
+		// no registry in the reference, it is a docker hub image
+		registry, path = "docker.io", image
+		if !strings.Contains(path, "/") {
+			path = "library/" + path
+		}
+	}
+
+	return mirror + "/" + registry + "/" + path
+}
+
 // QaImageByKey looks up an image from the store, and appends
-// the QA tag. If the image does not exist in the store, this function
-// will panic.
+// the QA tag. An image can be overridden from the environment (see
+// qaImageOverrideEnv), in which case the override is used as is, e.g. for
+// a locally built image. If the image is neither overridden nor in the
+// store, this function will panic.
 func (i *ImageStore) QaImageByKey(key string) string {
+	if override := ReadEnvVar(qaImageOverrideEnv(key)); override != "" {
+		return override
+	}
+
 	img, ok := i.Qa[key]
 	if ok {
 		idx := strings.LastIndex(img, ":")
 		img = i.qaImage(img[:idx], img[idx+1:])
-		return img
+		return MirrorImage(img, i.mirror)
 	}
 	panic("failed to find qa image: " + key)
 }
 
+// qaImageOverrideEnv returns the environment variable overriding the QA
+// image of the given key, e.g. QA_IMAGE_OVERRIDE_QA_SOCAT for qa-socat.
+func qaImageOverrideEnv(key string) string {
+	return envQAImageOverridePrefix + strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+}
+
 func loadImageStore(location string) (*ImageStore, error) {
 	file, err := ioutil.ReadFile(location)
 	if err != nil {
@@ -52,6 +109,7 @@ func loadImageStore(location string) (*ImageStore, error) {
 	}
 
 	store.qaTag = ReadEnvVar(envQATag)
+	store.mirror = RegistryMirror()
 
 	if store.qaTag == "" {
 		bytes, err := ioutil.ReadFile("container/QA_TAG")
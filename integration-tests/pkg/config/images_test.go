@@ -0,0 +1,50 @@
+package config
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMirrorImage(t *testing.T) {
+	tests := []struct {
+		image    string
+		mirror   string
+		expected string
+	}{
+		{"quay.io/rhacs-eng/qa-multi-arch:socat", "", "quay.io/rhacs-eng/qa-multi-arch:socat"},
+		{"quay.io/rhacs-eng/qa-multi-arch:socat", "mirror.internal", "mirror.internal/quay.io/rhacs-eng/qa-multi-arch:socat"},
+		{"quay.io/rhacs-eng/qa-multi-arch:socat", "mirror.internal/", "mirror.internal/quay.io/rhacs-eng/qa-multi-arch:socat"},
+		{"nginx:1.14-alpine", "mirror.internal", "mirror.internal/docker.io/library/nginx:1.14-alpine"},
+		{"someorg/image:tag", "mirror.internal", "mirror.internal/docker.io/someorg/image:tag"},
+		{"localhost/image:tag", "mirror.internal", "mirror.internal/localhost/image:tag"},
+		{"registry:5000/image:tag", "mirror.internal", "mirror.internal/registry:5000/image:tag"},
+		{"mirror.internal/quay.io/org/image:tag", "mirror.internal", "mirror.internal/quay.io/org/image:tag"},
+		{"", "mirror.internal", ""},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, MirrorImage(tt.image, tt.mirror), "image %q, mirror %q", tt.image, tt.mirror)
+	}
+}
+
+func TestQaImageByKeyOverride(t *testing.T) {
+	store := &ImageStore{
+		qaTag: "1.0",
+		Qa: map[string]string{
+			"qa-socat":       "quay.io/rhacs-eng/qa-multi-arch:socat",
+			"qa-alpine-curl": "quay.io/rhacs-eng/qa-multi-arch:alpine-curl",
+		},
+	}
+
+	assert.Equal(t, "quay.io/rhacs-eng/qa-multi-arch:socat-1.0", store.QaImageByKey("qa-socat"))
+
+	t.Setenv("QA_IMAGE_OVERRIDE_QA_SOCAT", "localhost/socat:dev")
+	assert.Equal(t, "localhost/socat:dev", store.QaImageByKey("qa-socat"))
+	assert.Equal(t, "quay.io/rhacs-eng/qa-multi-arch:alpine-curl-1.0", store.QaImageByKey("qa-alpine-curl"))
+
+	// an override is enough for an image missing from the store
+	t.Setenv("QA_IMAGE_OVERRIDE_QA_REUSEPORT", "localhost/reuseport:dev")
+	assert.Equal(t, "localhost/reuseport:dev", store.QaImageByKey("qa-reuseport"))
+	assert.Panics(t, func() { store.QaImageByKey("qa-missing") })
+}
@@ -0,0 +1,98 @@
+package executor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// capabilityNames are the names of the Linux capabilities, indexed by their
+// bit in a capability set.
+var capabilityNames = []string{
+	"CAP_CHOWN",
+	"CAP_DAC_OVERRIDE",
+	"CAP_DAC_READ_SEARCH",
+	"CAP_FOWNER",
+	"CAP_FSETID",
+	"CAP_KILL",
+	"CAP_SETGID",
+	"CAP_SETUID",
+	"CAP_SETPCAP",
+	"CAP_LINUX_IMMUTABLE",
+	"CAP_NET_BIND_SERVICE",
+	"CAP_NET_BROADCAST",
+	"CAP_NET_ADMIN",
+	"CAP_NET_RAW",
+	"CAP_IPC_LOCK",
+	"CAP_IPC_OWNER",
+	"CAP_SYS_MODULE",
+	"CAP_SYS_RAWIO",
+	"CAP_SYS_CHROOT",
+	"CAP_SYS_PTRACE",
+	"CAP_SYS_PACCT",
+	"CAP_SYS_ADMIN",
+	"CAP_SYS_BOOT",
+	"CAP_SYS_NICE",
+	"CAP_SYS_RESOURCE",
+	"CAP_SYS_TIME",
+	"CAP_SYS_TTY_CONFIG",
+	"CAP_MKNOD",
+	"CAP_LEASE",
+	"CAP_AUDIT_WRITE",
+	"CAP_AUDIT_CONTROL",
+	"CAP_SETFCAP",
+	"CAP_MAC_OVERRIDE",
+	"CAP_MAC_ADMIN",
+	"CAP_SYSLOG",
+	"CAP_WAKE_ALARM",
+	"CAP_BLOCK_SUSPEND",
+	"CAP_AUDIT_READ",
+	"CAP_PERFMON",
+	"CAP_BPF",
+	"CAP_CHECKPOINT_RESTORE",
+}
+
+// parseEffectiveCapabilities returns the names of the capabilities in the
+// CapEff set of a /proc/<pid>/status file. Capabilities newer than the
+// known ones are named after their bit, e.g. CAP_41.
+func parseEffectiveCapabilities(status string) ([]string, error) {
+	for _, line := range strings.Split(status, "\n") {
+		mask, found := strings.CutPrefix(line, "CapEff:")
+		if !found {
+			continue
+		}
+
+		bits, err := strconv.ParseUint(strings.TrimSpace(mask), 16, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		caps := []string{}
+		for bit := 0; bit < 64; bit++ {
+			if bits&(1<<bit) == 0 {
+				continue
+			}
+			caps = append(caps, capabilityName(bit))
+		}
+		return caps, nil
+	}
+	return nil, fmt.Errorf("no effective capabilities found in process status")
+}
+
+// AllCapabilities returns the names of every capability up to lastCap, as
+// read from /proc/sys/kernel/cap_last_cap on the host, which make up the
+// effective set of a privileged container.
+func AllCapabilities(lastCap int) []string {
+	caps := []string{}
+	for bit := 0; bit <= lastCap; bit++ {
+		caps = append(caps, capabilityName(bit))
+	}
+	return caps
+}
+
+func capabilityName(bit int) string {
+	if bit < len(capabilityNames) {
+		return capabilityNames[bit]
+	}
+	return fmt.Sprintf("CAP_%d", bit)
+}
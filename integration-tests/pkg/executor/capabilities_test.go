@@ -0,0 +1,36 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseEffectiveCapabilities(t *testing.T) {
+	status := "Name:\tcollector\n" +
+		"CapInh:\t0000000000000000\n" +
+		"CapPrm:\t00000000a80425fb\n" +
+		"CapEff:\t0000020000200001\n" +
+		"CapBnd:\t00000000a80425fb\n"
+
+	caps, err := parseEffectiveCapabilities(status)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"CAP_CHOWN", "CAP_SYS_ADMIN", "CAP_41"}, caps)
+
+	caps, err = parseEffectiveCapabilities("CapEff:\t0000000000000000\n")
+	assert.NoError(t, err)
+	assert.Empty(t, caps)
+
+	_, err = parseEffectiveCapabilities("Name:\tcollector\n")
+	assert.Error(t, err)
+}
+
+func TestAllCapabilities(t *testing.T) {
+	caps := AllCapabilities(40)
+	assert.Len(t, caps, 41)
+	assert.Equal(t, "CAP_CHOWN", caps[0])
+	assert.Equal(t, "CAP_CHECKPOINT_RESTORE", caps[40])
+
+	assert.Equal(t, []string{"CAP_CHOWN", "CAP_DAC_OVERRIDE"}, AllCapabilities(1))
+	assert.Equal(t, "CAP_41", AllCapabilities(41)[41])
+}
@@ -0,0 +1,38 @@
+package executor
+
+import (
+	"strings"
+)
+
+type ChangeKind string
+
+const (
+	ChangeAdded    ChangeKind = "A"
+	ChangeModified ChangeKind = "C"
+	ChangeDeleted  ChangeKind = "D"
+)
+
+// FilesystemChange is a single change to a container's filesystem,
+// relative to its image.
+type FilesystemChange struct {
+	Kind ChangeKind
+	Path string
+}
+
+// parseContainerChanges parses the output of the runtime's 'diff' command,
+// which has one change per line in the form '<kind> <path>'
+func parseContainerChanges(output string) []FilesystemChange {
+	changes := []FilesystemChange{}
+	for _, line := range strings.Split(output, "\n") {
+		kind, path, found := strings.Cut(strings.TrimSpace(line), " ")
+		if !found {
+			continue
+		}
+
+		changes = append(changes, FilesystemChange{
+			Kind: ChangeKind(kind),
+			Path: path,
+		})
+	}
+	return changes
+}
@@ -0,0 +1,51 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunWithTimeout(t *testing.T) {
+	output, err := runWithTimeout(time.Second, func(ctx context.Context) *exec.Cmd {
+		return exec.CommandContext(ctx, "sh", "-c", "echo fast")
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "fast\n", string(output))
+
+	var cmd *exec.Cmd
+	start := time.Now()
+	output, err = runWithTimeout(200*time.Millisecond, func(ctx context.Context) *exec.Cmd {
+		cmd = exec.CommandContext(ctx, "sh", "-c", "echo slow; exec sleep 10")
+		return cmd
+	})
+
+	assert.True(t, errors.Is(err, ErrCommandTimeout))
+	assert.Less(t, time.Since(start), 5*time.Second)
+	assert.Equal(t, "slow\n", string(output))
+	assert.False(t, cmd.ProcessState.Success(), "the command was not killed")
+}
+
+func TestRunWithTimeoutHeldOutput(t *testing.T) {
+	// the background sleep keeps the output open after the shell is killed
+	start := time.Now()
+	_, err := runWithTimeout(200*time.Millisecond, func(ctx context.Context) *exec.Cmd {
+		return exec.CommandContext(ctx, "sh", "-c", "sleep 30 & sleep 10")
+	})
+
+	assert.True(t, errors.Is(err, ErrCommandTimeout))
+	assert.Less(t, time.Since(start), commandWaitDelay+5*time.Second)
+}
+
+func TestRunCommandTimeout(t *testing.T) {
+	defer func(timeout time.Duration) { CommandTimeout = timeout }(CommandTimeout)
+	CommandTimeout = 100 * time.Millisecond
+
+	e := &dockerExecutor{builder: newLocalCommandBuilder()}
+	_, err := e.runExecCommand("sleep", "10")
+	assert.True(t, errors.Is(err, ErrCommandTimeout))
+}
@@ -0,0 +1,216 @@
+package executor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const apparmorProfiles = "/sys/kernel/security/apparmor/profiles"
+
+// TestContainerLabel is applied to every container started by the tests,
+// so that containers leaked by failed tests can be found and removed.
+const TestContainerLabel = "io.stackrox.collector.integration-test"
+
+// ContainerStartConfig describes a container to be started with
+// StartContainer. Zero values leave the runtime defaults in place.
+type ContainerStartConfig struct {
+	Name        string
+	Image       string
+	Privileged  bool
+	NetworkMode string
+	// IPAddress is a static address for the container, which requires
+	// NetworkMode to be a user-defined network created with a subnet
+	// containing the address
+	IPAddress string
+	// Mounts maps paths in the container to paths on the host
+	Mounts map[string]string
+	Env    map[string]string
+	// Entrypoint overrides the image's entrypoint, with any
+	// further elements prepended to the Command
+	Entrypoint []string
+	Command    []string
+	// SecurityOpt are confinement options, e.g. seccomp=/path/profile.json
+	// or apparmor=profile-name
+	SecurityOpt []string
+	// DNS servers and search domains to use instead of the host's
+	DNS       []string
+	DNSSearch []string
+	Labels    map[string]string
+	// StopSignal is sent to stop the container instead of SIGTERM,
+	// for workloads that only shut down cleanly on another signal
+	StopSignal string
+	// CgroupParent nests the container's cgroup under the given parent,
+	// e.g. a systemd slice, instead of the runtime's default
+	CgroupParent string
+}
+
+// ExecOptions describes a command to run in a running container.
+type ExecOptions struct {
+	Cmd []string
+	Env map[string]string
+	// WorkingDir and User override the container's defaults if set
+	WorkingDir string
+	User       string
+	// Stdin is piped to the command if not empty
+	Stdin string
+}
+
+// buildExecArgs translates exec options into the arguments of the
+// runtime's exec command, including the container and command.
+func buildExecArgs(containerName string, opts ExecOptions) []string {
+	args := []string{"exec"}
+
+	if opts.Stdin != "" {
+		args = append(args, "-i")
+	}
+
+	if opts.WorkingDir != "" {
+		args = append(args, "-w", opts.WorkingDir)
+	}
+
+	if opts.User != "" {
+		args = append(args, "-u", opts.User)
+	}
+
+	for _, name := range sortedKeys(opts.Env) {
+		args = append(args, "-e", name+"="+opts.Env[name])
+	}
+
+	args = append(args, containerName)
+	return append(args, opts.Cmd...)
+}
+
+// buildRunArgs translates a container configuration into the arguments of
+// the runtime's run command, including the image and command.
+func buildRunArgs(config ContainerStartConfig) []string {
+	args := []string{"run", "-d", "--name", config.Name}
+
+	if config.Privileged {
+		args = append(args, "--privileged")
+	}
+
+	if config.NetworkMode != "" {
+		args = append(args, "--network", config.NetworkMode)
+	}
+
+	if config.IPAddress != "" {
+		args = append(args, "--ip", config.IPAddress)
+	}
+
+	for _, dst := range sortedKeys(config.Mounts) {
+		args = append(args, "-v", config.Mounts[dst]+":"+dst)
+	}
+
+	for _, name := range sortedKeys(config.Env) {
+		args = append(args, "--env", name+"="+config.Env[name])
+	}
+
+	for _, name := range sortedKeys(config.Labels) {
+		args = append(args, "--label", name+"="+config.Labels[name])
+	}
+
+	for _, opt := range config.SecurityOpt {
+		args = append(args, "--security-opt", opt)
+	}
+
+	for _, server := range config.DNS {
+		args = append(args, "--dns", server)
+	}
+
+	for _, domain := range config.DNSSearch {
+		args = append(args, "--dns-search", domain)
+	}
+
+	if config.StopSignal != "" {
+		args = append(args, "--stop-signal", config.StopSignal)
+	}
+
+	if config.CgroupParent != "" {
+		args = append(args, "--cgroup-parent", config.CgroupParent)
+	}
+
+	command := config.Command
+	if len(config.Entrypoint) > 0 {
+		args = append(args, "--entrypoint", config.Entrypoint[0])
+		command = append(append([]string{}, config.Entrypoint[1:]...), command...)
+	}
+
+	args = append(args, config.Image)
+	return append(args, command...)
+}
+
+// parseContainerNames parses the output of 'ps' formatted as
+// '{{.Names}}', with one container per line.
+func parseContainerNames(output string) []string {
+	names := []string{}
+	for _, line := range strings.Split(output, "\n") {
+		if name := strings.Trim(line, "\"' "); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// withTestLabel returns a copy of the labels, including the label that
+// identifies containers started by the tests.
+func withTestLabel(labels map[string]string) map[string]string {
+	result := map[string]string{TestContainerLabel: "true"}
+	for name, value := range labels {
+		result[name] = value
+	}
+	return result
+}
+
+// validateSecurityOpts checks that the seccomp and AppArmor profiles
+// referenced by the options are available on the host running the
+// containers, whose files are read with readHostFile, so that a missing
+// profile is reported clearly rather than as a runtime failure.
+func validateSecurityOpts(opts []string, readHostFile func(path string) (string, error)) error {
+	for _, opt := range opts {
+		key, value, found := strings.Cut(opt, "=")
+		if !found {
+			key, value, _ = strings.Cut(opt, ":")
+		}
+
+		if value == "" || value == "unconfined" {
+			continue
+		}
+
+		switch key {
+		case "seccomp":
+			if _, err := readHostFile(value); err != nil {
+				return fmt.Errorf("seccomp profile is unavailable: %w", err)
+			}
+		case "apparmor":
+			profiles, err := readHostFile(apparmorProfiles)
+			if err != nil {
+				return fmt.Errorf("AppArmor is unavailable on this host: %w", err)
+			}
+			if !hasApparmorProfile(profiles, value) {
+				return fmt.Errorf("AppArmor profile %q is not loaded", value)
+			}
+		}
+	}
+	return nil
+}
+
+// hasApparmorProfile checks for a profile in the list of loaded profiles,
+// which has one '<name> (<mode>)' entry per line.
+func hasApparmorProfile(profiles string, name string) bool {
+	for _, line := range strings.Split(profiles, "\n") {
+		if strings.HasPrefix(line, name+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,233 @@
+package executor
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildRunArgs(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   ContainerStartConfig
+		expected []string
+	}{
+		{
+			name:     "minimal",
+			config:   ContainerStartConfig{Name: "test", Image: "alpine"},
+			expected: []string{"run", "-d", "--name", "test", "alpine"},
+		},
+		{
+			name: "command",
+			config: ContainerStartConfig{
+				Name:    "test",
+				Image:   "alpine",
+				Command: []string{"sleep", "300"},
+			},
+			expected: []string{"run", "-d", "--name", "test", "alpine", "sleep", "300"},
+		},
+		{
+			name: "entrypoint",
+			config: ContainerStartConfig{
+				Name:       "test",
+				Image:      "alpine",
+				Entrypoint: []string{"sh", "-c"},
+				Command:    []string{"sleep 300"},
+			},
+			expected: []string{
+				"run", "-d", "--name", "test",
+				"--entrypoint", "sh",
+				"alpine", "-c", "sleep 300",
+			},
+		},
+		{
+			name: "privileged and network",
+			config: ContainerStartConfig{
+				Name:        "test",
+				Image:       "alpine",
+				Privileged:  true,
+				NetworkMode: "host",
+			},
+			expected: []string{
+				"run", "-d", "--name", "test",
+				"--privileged",
+				"--network", "host",
+				"alpine",
+			},
+		},
+		{
+			name: "static IP address",
+			config: ContainerStartConfig{
+				Name:        "test",
+				Image:       "alpine",
+				NetworkMode: "static-ip-tests",
+				IPAddress:   "172.30.0.10",
+			},
+			expected: []string{
+				"run", "-d", "--name", "test",
+				"--network", "static-ip-tests",
+				"--ip", "172.30.0.10",
+				"alpine",
+			},
+		},
+		{
+			name: "mounts and env",
+			config: ContainerStartConfig{
+				Name:   "test",
+				Image:  "alpine",
+				Mounts: map[string]string{"/host/proc": "/proc", "/data": "/tmp/data"},
+				Env:    map[string]string{"B": "2", "A": "1"},
+			},
+			expected: []string{
+				"run", "-d", "--name", "test",
+				"-v", "/tmp/data:/data",
+				"-v", "/proc:/host/proc",
+				"--env", "A=1",
+				"--env", "B=2",
+				"alpine",
+			},
+		},
+		{
+			name: "labels",
+			config: ContainerStartConfig{
+				Name:   "test",
+				Image:  "alpine",
+				Labels: withTestLabel(map[string]string{"app": "test"}),
+			},
+			expected: []string{
+				"run", "-d", "--name", "test",
+				"--label", "app=test",
+				"--label", TestContainerLabel + "=true",
+				"alpine",
+			},
+		},
+		{
+			name: "security options",
+			config: ContainerStartConfig{
+				Name:        "test",
+				Image:       "alpine",
+				Command:     []string{"sleep", "300"},
+				SecurityOpt: []string{"seccomp=/tmp/profile.json", "no-new-privileges"},
+			},
+			expected: []string{
+				"run", "-d", "--name", "test",
+				"--security-opt", "seccomp=/tmp/profile.json",
+				"--security-opt", "no-new-privileges",
+				"alpine", "sleep", "300",
+			},
+		},
+		{
+			name: "dns",
+			config: ContainerStartConfig{
+				Name:      "test",
+				Image:     "alpine",
+				DNS:       []string{"172.17.0.2", "172.17.0.3"},
+				DNSSearch: []string{"collector.test"},
+			},
+			expected: []string{
+				"run", "-d", "--name", "test",
+				"--dns", "172.17.0.2",
+				"--dns", "172.17.0.3",
+				"--dns-search", "collector.test",
+				"alpine",
+			},
+		},
+		{
+			name: "stop signal",
+			config: ContainerStartConfig{
+				Name:       "test",
+				Image:      "alpine",
+				StopSignal: "SIGUSR1",
+			},
+			expected: []string{
+				"run", "-d", "--name", "test",
+				"--stop-signal", "SIGUSR1",
+				"alpine",
+			},
+		},
+		{
+			name: "cgroup parent",
+			config: ContainerStartConfig{
+				Name:         "test",
+				Image:        "alpine",
+				CgroupParent: "collector-tests.slice",
+			},
+			expected: []string{
+				"run", "-d", "--name", "test",
+				"--cgroup-parent", "collector-tests.slice",
+				"alpine",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, buildRunArgs(tt.config))
+		})
+	}
+}
+
+func TestBuildRunArgsKeepsEntrypoint(t *testing.T) {
+	config := ContainerStartConfig{
+		Name:       "test",
+		Image:      "alpine",
+		Entrypoint: []string{"sh", "-c"},
+		Command:    []string{"true"},
+	}
+
+	buildRunArgs(config)
+	buildRunArgs(config)
+
+	assert.Equal(t, []string{"sh", "-c"}, config.Entrypoint)
+	assert.Equal(t, []string{"true"}, config.Command)
+}
+
+func TestBuildExecArgs(t *testing.T) {
+	assert.Equal(t, []string{"exec", "test", "ls", "-l"},
+		buildExecArgs("test", ExecOptions{Cmd: []string{"ls", "-l"}}))
+
+	args := buildExecArgs("test", ExecOptions{
+		Cmd:        []string{"sh", "-s"},
+		Env:        map[string]string{"B": "2", "A": "1"},
+		WorkingDir: "/tmp",
+		User:       "nobody",
+		Stdin:      "echo hello",
+	})
+
+	assert.Equal(t, []string{
+		"exec", "-i", "-w", "/tmp", "-u", "nobody",
+		"-e", "A=1", "-e", "B=2",
+		"test", "sh", "-s",
+	}, args)
+}
+
+func TestValidateSecurityOpts(t *testing.T) {
+	profiles := "docker-default (enforce)\nunconfined-ish (complain)\n"
+	hostFiles := map[string]string{
+		"/tmp/profile.json": "{}",
+		apparmorProfiles:    profiles,
+	}
+	readHostFile := func(path string) (string, error) {
+		content, ok := hostFiles[path]
+		if !ok {
+			return "", fmt.Errorf("cat: %s: No such file or directory", path)
+		}
+		return content, nil
+	}
+
+	assert.NoError(t, validateSecurityOpts(nil, readHostFile))
+	assert.NoError(t, validateSecurityOpts([]string{"seccomp=unconfined", "no-new-privileges"}, readHostFile))
+	assert.NoError(t, validateSecurityOpts([]string{"seccomp=/tmp/profile.json", "apparmor=docker-default"}, readHostFile))
+	assert.Error(t, validateSecurityOpts([]string{"seccomp=/does/not/exist.json"}, readHostFile))
+	assert.Error(t, validateSecurityOpts([]string{"apparmor=docker"}, readHostFile))
+
+	assert.True(t, hasApparmorProfile(profiles, "docker-default"))
+	assert.False(t, hasApparmorProfile(profiles, "docker"))
+}
+
+func TestParseContainerNames(t *testing.T) {
+	assert.Equal(t, []string{"collector", "proxy-hop-client"},
+		parseContainerNames("'collector'\n'proxy-hop-client'\n"))
+	assert.Empty(t, parseContainerNames(""))
+}
@@ -0,0 +1,71 @@
+package executor
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ExecSession is a process started in a container by an exec, rather than
+// by the container's main process, along with any processes it started.
+type ExecSession struct {
+	Pid     int
+	Command string
+}
+
+// containerProcessesScript lists the processes in the pid namespace of the
+// given process, one per line as "pid ppid command line". The parent pid
+// is read after the command name in stat, which may contain spaces.
+const containerProcessesScript = `ns=$(readlink /proc/%d/ns/pid)
+for p in /proc/[0-9]*; do
+	[ "$(readlink $p/ns/pid 2>/dev/null)" = "$ns" ] || continue
+	ppid=$(sed 's/.*) //' $p/stat 2>/dev/null | cut -d' ' -f2)
+	echo "${p#/proc/} $ppid $(tr '\0' ' ' < $p/cmdline 2>/dev/null)"
+done
+`
+
+// parseExecSessions finds the exec sessions among the processes of a
+// container, as listed by containerProcessesScript. The main process and
+// its descendants have their parent in the container, except for the main
+// process itself; any other process with a parent outside the container
+// was started by an exec. Sessions are sorted by pid.
+func parseExecSessions(output string, mainPid int) ([]ExecSession, error) {
+	type process struct {
+		pid, ppid int
+		command   string
+	}
+
+	processes := []process{}
+	inContainer := map[int]bool{}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid pid in %q", line)
+		}
+		ppid, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid parent pid in %q", line)
+		}
+
+		processes = append(processes, process{pid, ppid, strings.Join(fields[2:], " ")})
+		inContainer[pid] = true
+	}
+
+	sessions := []ExecSession{}
+	for _, p := range processes {
+		if p.pid != mainPid && !inContainer[p.ppid] {
+			sessions = append(sessions, ExecSession{Pid: p.pid, Command: p.command})
+		}
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].Pid < sessions[j].Pid
+	})
+	return sessions, nil
+}
@@ -0,0 +1,32 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseExecSessions(t *testing.T) {
+	// 100 is the main process, with a child; 200 is a running exec, with
+	// a child; 300 was backgrounded by an exec that already exited
+	output := `100 90 /bin/sleep 300
+101 100 /bin/sh -c true
+200 95 /bin/sh -c echo hello | socat - TCP4:10.0.0.2:40
+201 200 socat - TCP4:10.0.0.2:40
+300 95 socat TCP4-LISTEN:40,reuseaddr,fork -
+`
+
+	sessions, err := parseExecSessions(output, 100)
+	assert.NoError(t, err)
+	assert.Equal(t, []ExecSession{
+		{Pid: 200, Command: "/bin/sh -c echo hello | socat - TCP4:10.0.0.2:40"},
+		{Pid: 300, Command: "socat TCP4-LISTEN:40,reuseaddr,fork -"},
+	}, sessions)
+
+	sessions, err = parseExecSessions("100 90 /bin/sleep 300\n", 100)
+	assert.NoError(t, err)
+	assert.Empty(t, sessions)
+
+	_, err = parseExecSessions("abc 90 sleep\n", 100)
+	assert.Error(t, err)
+}
@@ -1,7 +1,10 @@
 package executor
 
 import (
+	"context"
+	"io"
 	"os/exec"
+	"time"
 
 	"github.com/stackrox/collector/integration-tests/pkg/config"
 )
@@ -14,6 +17,8 @@ type ContainerFilter struct {
 type Executor interface {
 	CopyFromHost(src string, dst string) (string, error)
 	PullImage(image string) error
+	PullImageWithPolicy(image string, policy PullPolicy) error
+	LoadImageFromArchive(path string) ([]string, error)
 	IsContainerRunning(container string) (bool, error)
 	ContainerExists(filter ContainerFilter) (bool, error)
 	ContainerID(filter ContainerFilter) string
@@ -25,11 +30,39 @@ type Executor interface {
 	KillContainer(name string) (string, error)
 	RemoveContainer(filter ContainerFilter) (string, error)
 	StopContainer(name string) (string, error)
+	StopContainerWithSignal(name string, signal string) (string, error)
+	RestartContainer(name string) (string, error)
+	GetHostDmesg(since time.Time) (string, error)
+	GetContainerState(containerID string) (ContainerState, error)
+	GetContainerPID(containerID string) (int, error)
+	GetContainerIPv6(containerID string) (string, error)
+	GetContainerExitReason(containerID string) (exitCode int, signal string, oomKilled bool, err error)
+	GetContainerUptime(containerID string) (time.Duration, error)
+	GetContainerTimes(containerID string) (started time.Time, finished time.Time, err error)
+	GetContainerCapabilities(containerID string) (effective []string, privileged bool, err error)
+	GetContainerChanges(containerID string) ([]FilesystemChange, error)
+	GetContainerMountPropagation(containerID string) (map[string]string, error)
+	GetContainerResolvConf(containerID string) (string, error)
+	GetContainerSockets(containerID string) ([]Socket, error)
+	GetContainerExecSessions(containerID string) ([]ExecSession, error)
+	ListContainers(filter string) ([]string, error)
+	CreateNetwork(name string) error
+	CreateNetworkWithOptions(name string, opts NetworkOptions) error
+	ListNetworks(filter string) ([]NetworkInfo, error)
+	RemoveNetwork(name string) error
+	StartContainer(config ContainerStartConfig) (string, error)
+	ExecContainer(containerName string, cmd []string) (string, error)
+	ExecContainerOpts(containerName string, opts ExecOptions) (string, error)
+	CleanupTracked() error
+	FollowContainerLogs(containerID string) (io.ReadCloser, error)
+	PingRuntime() error
+	Reconnect() error
+	ExposeToHost(port int) (stop func(), err error)
 }
 
 type CommandBuilder interface {
-	ExecCommand(args ...string) *exec.Cmd
-	RemoteCopyCommand(remoteSrc string, localDst string) *exec.Cmd
+	ExecCommand(ctx context.Context, args ...string) *exec.Cmd
+	RemoteCopyCommand(ctx context.Context, remoteSrc string, localDst string) *exec.Cmd
 }
 
 func New() (Executor, error) {
@@ -1,26 +1,54 @@
 package executor
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os/exec"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/hashicorp/go-multierror"
 	"github.com/pkg/errors"
 	"github.com/stackrox/collector/integration-tests/pkg/config"
+	"github.com/stackrox/collector/integration-tests/pkg/log"
+	"golang.org/x/exp/slices"
 )
 
 var (
 	debug = false
 
+	logger = log.New("executor")
+
 	RuntimeCommand = config.RuntimeInfo().Command
 	RuntimeSocket  = config.RuntimeInfo().Socket
 	RuntimeAsRoot  = config.RuntimeInfo().RunAsRoot
+
+	// CommandTimeout bounds the runtime commands, so that a hung
+	// subprocess cannot block the tests forever
+	CommandTimeout = config.RuntimeInfo().CommandTimeout
+
+	// ErrCommandTimeout is returned when a command is killed after
+	// running for longer than CommandTimeout
+	ErrCommandTimeout = errors.New("command timed out")
 )
 
+// commandWaitDelay is how long the output of a command is waited for after
+// it exited or was killed, in case a process it spawned holds on to it.
+const commandWaitDelay = 5 * time.Second
+
+// dockerExecutor manages containers through the CLI of the container
+// runtime (see RuntimeCommand), run by its CommandBuilder on the local host
+// or a remote one. It is the only container executor, as the tests do not
+// use the Docker API.
 type dockerExecutor struct {
 	builder CommandBuilder
+
+	// names of the containers started through StartContainer
+	tracked      []string
+	trackedMutex sync.Mutex
 }
 
 type localCommandBuilder struct {
@@ -35,7 +63,7 @@ func newDockerExecutor() (*dockerExecutor, error) {
 	// same construction API as other executors, we keep the
 	// error return value.
 	return &dockerExecutor{
-		builder: newLocalCommandBuilder(),
+		builder: newCommandBuilder(),
 	}, nil
 }
 
@@ -45,7 +73,7 @@ func (e *dockerExecutor) Exec(args ...string) (string, error) {
 		args = append([]string{"sudo"}, args...)
 	}
 	return Retry(func() (string, error) {
-		return e.RunCommand(e.builder.ExecCommand(args...))
+		return e.runExecCommand(args...)
 	})
 }
 
@@ -56,7 +84,7 @@ func (e *dockerExecutor) ExecWithErrorCheck(errCheckFn func(string, error) error
 		args = append([]string{"sudo"}, args...)
 	}
 	return RetryWithErrorCheck(errCheckFn, func() (string, error) {
-		return e.RunCommand(e.builder.ExecCommand(args...))
+		return e.runExecCommand(args...)
 	})
 }
 
@@ -65,21 +93,33 @@ func (e *dockerExecutor) ExecWithoutRetry(args ...string) (string, error) {
 	if args[0] == RuntimeCommand && RuntimeAsRoot {
 		args = append([]string{"sudo"}, args...)
 	}
-	return e.RunCommand(e.builder.ExecCommand(args...))
+	return e.runExecCommand(args...)
 }
 
-func (e *dockerExecutor) RunCommand(cmd *exec.Cmd) (string, error) {
-	if cmd == nil {
-		return "", nil
-	}
-	commandLine := strings.Join(cmd.Args, " ")
-	if debug {
-		fmt.Printf("Run: %s\n", commandLine)
-	}
-	stdoutStderr, err := cmd.CombinedOutput()
+// runExecCommand runs the command built for the given arguments.
+func (e *dockerExecutor) runExecCommand(args ...string) (string, error) {
+	return e.RunCommand(func(ctx context.Context) *exec.Cmd {
+		return e.builder.ExecCommand(ctx, args...)
+	})
+}
+
+// RunCommand runs the command returned by newCmd for a context bounded by
+// CommandTimeout, and returns its trimmed combined output.
+func (e *dockerExecutor) RunCommand(newCmd func(ctx context.Context) *exec.Cmd) (string, error) {
+	var commandLine string
+	stdoutStderr, err := runWithTimeout(CommandTimeout, func(ctx context.Context) *exec.Cmd {
+		cmd := newCmd(ctx)
+		if cmd != nil {
+			commandLine = strings.Join(cmd.Args, " ")
+			if debug {
+				logger.Debug("Run", "cmd", commandLine)
+			}
+		}
+		return cmd
+	})
 	trimmed := strings.Trim(string(stdoutStderr), "\"\n")
 	if debug {
-		fmt.Printf("Run Output: %s\n", trimmed)
+		logger.Debug("Run Output", "output", trimmed)
 	}
 	if err != nil {
 		err = errors.Wrapf(err, "Command Failed: %s\nOutput: %s\n", commandLine, trimmed)
@@ -87,37 +127,51 @@ func (e *dockerExecutor) RunCommand(cmd *exec.Cmd) (string, error) {
 	return trimmed, err
 }
 
-func (e *dockerExecutor) ExecWithStdin(pipedContent string, args ...string) (res string, err error) {
+// runWithTimeout runs the command returned by newCmd for a context, like
+// CombinedOutput, but kills it and returns ErrCommandTimeout if it runs for
+// longer than the timeout. The command must be created with
+// exec.CommandContext for that context, or be nil for nothing to run.
+func runWithTimeout(timeout time.Duration, newCmd func(ctx context.Context) *exec.Cmd) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
 
-	if args[0] == RuntimeCommand && RuntimeAsRoot {
-		args = append([]string{"sudo"}, args...)
+	cmd := newCmd(ctx)
+	if cmd == nil {
+		return nil, nil
 	}
+	cmd.WaitDelay = commandWaitDelay
 
-	cmd := e.builder.ExecCommand(args...)
-
-	stdin, err := cmd.StdinPipe()
-	if err != nil {
-		return "", err
+	output, err := cmd.CombinedOutput()
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return output, errors.Wrapf(ErrCommandTimeout, "killed after %s", timeout)
 	}
+	return output, err
+}
 
-	go func() {
-		defer stdin.Close()
-		io.WriteString(stdin, pipedContent)
-	}()
+func (e *dockerExecutor) ExecWithStdin(pipedContent string, args ...string) (res string, err error) {
+
+	if args[0] == RuntimeCommand && RuntimeAsRoot {
+		args = append([]string{"sudo"}, args...)
+	}
 
-	return e.RunCommand(cmd)
+	return e.RunCommand(func(ctx context.Context) *exec.Cmd {
+		cmd := e.builder.ExecCommand(ctx, args...)
+		cmd.Stdin = strings.NewReader(pipedContent)
+		return cmd
+	})
 }
 
 func (e *dockerExecutor) CopyFromHost(src string, dst string) (res string, err error) {
 	maxAttempts := 3
 	attempt := 0
 	for attempt < maxAttempts {
-		cmd := e.builder.RemoteCopyCommand(src, dst)
 		if attempt > 0 {
-			fmt.Printf("Retrying (%v) (%d of %d) Error: %v\n", cmd, attempt, maxAttempts, err)
+			logger.Info("Retrying", "src", src, "dst", dst, "attempt", attempt, "max_attempts", maxAttempts, "err", err)
 		}
 		attempt++
-		res, err = e.RunCommand(cmd)
+		res, err = e.RunCommand(func(ctx context.Context) *exec.Cmd {
+			return e.builder.RemoteCopyCommand(ctx, src, dst)
+		})
 		if err == nil {
 			break
 		}
@@ -126,20 +180,55 @@ func (e *dockerExecutor) CopyFromHost(src string, dst string) (res string, err e
 }
 
 func (e *dockerExecutor) PullImage(image string) error {
-	_, err := e.Exec(RuntimeCommand, "image", "inspect", image)
-	if err == nil {
-		return nil
+	return e.PullImageWithPolicy(image, "")
+}
+
+// PullImageWithPolicy pulls the image according to the policy, which
+// defaults to the configured one if empty.
+func (e *dockerExecutor) PullImageWithPolicy(image string, policy PullPolicy) error {
+	policy, err := ResolvePullPolicy(policy)
+	if err != nil {
+		return err
+	}
+
+	image = config.MirrorImage(image, config.RegistryMirror())
+	if policy != PullAlways {
+		_, err := e.Exec(RuntimeCommand, "image", "inspect", image)
+		if err == nil {
+			return nil
+		}
+
+		if policy == PullNever {
+			return fmt.Errorf("image %s is not present locally and the pull policy is %s", image, policy)
+		}
 	}
+
 	_, err = e.Exec(RuntimeCommand, "pull", image)
 	return err
 }
 
+// LoadImageFromArchive loads the images in an OCI or docker archive (as
+// produced by 'image save') into the local image store, so they can be run
+// without a registry. It returns the references of all loaded images.
+func (e *dockerExecutor) LoadImageFromArchive(path string) ([]string, error) {
+	output, err := e.Exec(RuntimeCommand, "image", "load", "-i", path)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := parseLoadedImages(output)
+	if len(refs) == 0 {
+		return nil, fmt.Errorf("no images loaded from %s: %s", path, output)
+	}
+	return refs, nil
+}
+
 func (e *dockerExecutor) IsContainerRunning(containerID string) (bool, error) {
-	result, err := e.ExecWithoutRetry(RuntimeCommand, "inspect", containerID, "--format='{{.State.Running}}'")
+	state, err := e.inspectState(e.ExecWithoutRetry, containerID)
 	if err != nil {
 		return false, err
 	}
-	return strconv.ParseBool(strings.Trim(result, "\"'"))
+	return state.Running, nil
 }
 
 func (e *dockerExecutor) ContainerID(cf ContainerFilter) string {
@@ -160,11 +249,208 @@ func (e *dockerExecutor) ContainerExists(cf ContainerFilter) (bool, error) {
 }
 
 func (e *dockerExecutor) ExitCode(cf ContainerFilter) (int, error) {
-	result, err := e.Exec(RuntimeCommand, "inspect", cf.Name, "--format='{{.State.ExitCode}}'")
+	state, err := e.GetContainerState(cf.Name)
+	if err != nil {
+		return -1, err
+	}
+	return state.ExitCode, nil
+}
+
+// GetContainerState returns the state of a container from a single inspect,
+// rather than one per field.
+func (e *dockerExecutor) GetContainerState(containerID string) (ContainerState, error) {
+	return e.inspectState(e.Exec, containerID)
+}
+
+// inspectState inspects the state of a container with the given exec
+// function, so that callers can choose whether to retry.
+func (e *dockerExecutor) inspectState(exec func(args ...string) (string, error), containerID string) (ContainerState, error) {
+	result, err := exec(RuntimeCommand, "inspect", containerID, "--format='{{json .State}}'")
+	if err != nil {
+		return ContainerState{}, err
+	}
+	return parseContainerState(strings.Trim(result, "\"'"))
+}
+
+// GetContainerPID returns the host PID of the main process of a container
+func (e *dockerExecutor) GetContainerPID(containerID string) (int, error) {
+	state, err := e.GetContainerState(containerID)
 	if err != nil {
 		return -1, err
 	}
-	return strconv.Atoi(strings.Trim(result, "\"'"))
+	return state.Pid, nil
+}
+
+// GetContainerIPv6 returns the global IPv6 address of a container, from the
+// first of its networks that has one, or an empty string if it has none,
+// e.g. because IPv6 is not enabled for its networks.
+func (e *dockerExecutor) GetContainerIPv6(containerID string) (string, error) {
+	result, err := e.Exec(RuntimeCommand, "inspect",
+		"--format='{{range .NetworkSettings.Networks}}{{.GlobalIPv6Address}} {{end}}'", containerID)
+	if err != nil {
+		return "", err
+	}
+	return firstIPv6(strings.Fields(strings.Trim(result, "\"'"))), nil
+}
+
+// GetContainerExitReason returns the exit code of a container, the signal that
+// terminated it (if any, derived from exit codes above 128) and whether it was
+// killed for running out of memory.
+func (e *dockerExecutor) GetContainerExitReason(containerID string) (exitCode int, signal string, oomKilled bool, err error) {
+	state, err := e.GetContainerState(containerID)
+	if err != nil {
+		return -1, "", false, err
+	}
+	return state.ExitCode, SignalFromExitCode(state.ExitCode), state.OOMKilled, nil
+}
+
+// GetContainerUptime returns how long the container has been running since
+// it was last (re)started.
+func (e *dockerExecutor) GetContainerUptime(containerID string) (time.Duration, error) {
+	state, err := e.GetContainerState(containerID)
+	if err != nil {
+		return 0, err
+	}
+	return time.Since(state.StartedAt), nil
+}
+
+// GetContainerTimes returns when the main process of the container was last
+// started and, once it has exited, when it finished, as recorded by the
+// runtime with the clock of the host running the containers. The finish
+// time is zero while the container is running.
+func (e *dockerExecutor) GetContainerTimes(containerID string) (started time.Time, finished time.Time, err error) {
+	state, err := e.GetContainerState(containerID)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return state.StartedAt, state.FinishedAt, nil
+}
+
+// GetContainerCapabilities returns the effective capabilities of the main
+// process of a container, read from its status on the host, and whether the
+// container is privileged.
+func (e *dockerExecutor) GetContainerCapabilities(containerID string) ([]string, bool, error) {
+	result, err := e.Exec(RuntimeCommand, "inspect", containerID, "--format='{{.HostConfig.Privileged}}'")
+	if err != nil {
+		return nil, false, err
+	}
+
+	privileged, err := strconv.ParseBool(strings.Trim(result, "\"'"))
+	if err != nil {
+		return nil, false, err
+	}
+
+	pid, err := e.GetContainerPID(containerID)
+	if err != nil {
+		return nil, false, err
+	}
+
+	status, err := e.Exec("cat", fmt.Sprintf("/proc/%d/status", pid))
+	if err != nil {
+		return nil, false, err
+	}
+
+	effective, err := parseEffectiveCapabilities(status)
+	return effective, privileged, err
+}
+
+// GetContainerMountPropagation returns the propagation mode of each mount of
+// a container (e.g. rslave), keyed by its destination in the container.
+func (e *dockerExecutor) GetContainerMountPropagation(containerID string) (map[string]string, error) {
+	result, err := e.Exec(RuntimeCommand, "inspect", containerID, "--format='{{json .Mounts}}'")
+	if err != nil {
+		return nil, err
+	}
+
+	return parseMountPropagation(strings.Trim(result, "\"'"))
+}
+
+// GetContainerResolvConf returns the content of /etc/resolv.conf in the
+// container, i.e. the DNS configuration its processes actually use.
+func (e *dockerExecutor) GetContainerResolvConf(containerID string) (string, error) {
+	return e.Exec(RuntimeCommand, "exec", containerID, "cat", "/etc/resolv.conf")
+}
+
+// GetContainerSockets returns the TCP sockets in the network namespace of
+// the main process of a container, read from its /proc on the host.
+func (e *dockerExecutor) GetContainerSockets(containerID string) ([]Socket, error) {
+	pid, err := e.GetContainerPID(containerID)
+	if err != nil {
+		return nil, err
+	}
+
+	// tcp6 is missing if IPv6 is disabled, so its errors are ignored
+	content, err := e.Exec("sh", "-c", fmt.Sprintf("cat /proc/%d/net/tcp; cat /proc/%d/net/tcp6 2>/dev/null; true", pid, pid))
+	if err != nil {
+		return nil, err
+	}
+
+	return parseSockets(content)
+}
+
+// GetContainerExecSessions returns the processes started in a container by
+// execs that are still running, read from its /proc on the host. The
+// runtime only tracks the execs themselves (ExecIDs in inspect), which end
+// with the exec'd command, so any process they left running in the
+// background, and that could interfere with later tests, would be missed.
+func (e *dockerExecutor) GetContainerExecSessions(containerID string) ([]ExecSession, error) {
+	pid, err := e.GetContainerPID(containerID)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := e.Exec("sh", "-c", fmt.Sprintf(containerProcessesScript, pid))
+	if err != nil {
+		return nil, err
+	}
+
+	return parseExecSessions(output, pid)
+}
+
+// GetContainerChanges returns the changes made to the filesystem of the
+// container, relative to its image.
+func (e *dockerExecutor) GetContainerChanges(containerID string) ([]FilesystemChange, error) {
+	result, err := e.Exec(RuntimeCommand, "diff", containerID)
+	if err != nil {
+		return nil, err
+	}
+	return parseContainerChanges(result), nil
+}
+
+// FollowContainerLogs streams the combined stdout and stderr of the
+// container as it is written, starting from the beginning of its logs.
+// Closing the returned reader stops following.
+func (e *dockerExecutor) FollowContainerLogs(containerID string) (io.ReadCloser, error) {
+	args := []string{RuntimeCommand, "logs", "--follow", containerID}
+	if RuntimeAsRoot {
+		args = append([]string{"sudo"}, args...)
+	}
+
+	reader, writer := io.Pipe()
+	cmd := e.builder.ExecCommand(context.Background(), args...)
+	cmd.Stdout = writer
+	cmd.Stderr = writer
+
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+
+	go func() {
+		writer.CloseWithError(cmd.Wait())
+	}()
+
+	return &followedLogs{PipeReader: reader, cmd: cmd}, nil
+}
+
+// followedLogs stops the underlying logs command when closed.
+type followedLogs struct {
+	*io.PipeReader
+	cmd *exec.Cmd
+}
+
+func (f *followedLogs) Close() error {
+	f.cmd.Process.Kill()
+	return f.PipeReader.Close()
 }
 
 // checkContainerCommandError returns nil if the output of the container
@@ -188,6 +474,99 @@ func containerErrorCheckFunction(name string, cmd string) func(string, error) er
 	}
 }
 
+// StartContainer starts a detached container with the provided configuration,
+// and returns its ID.
+func (e *dockerExecutor) StartContainer(config ContainerStartConfig) (string, error) {
+	readHostFile := func(path string) (string, error) {
+		return e.ExecWithoutRetry("cat", path)
+	}
+	if err := validateSecurityOpts(config.SecurityOpt, readHostFile); err != nil {
+		return "", err
+	}
+
+	if config.IPAddress != "" {
+		if config.NetworkMode == "" {
+			return "", fmt.Errorf("a static IP address requires a user-defined network")
+		}
+
+		subnets, err := e.networkSubnets(config.NetworkMode)
+		if err != nil {
+			return "", fmt.Errorf("failed to get the subnets of network %s: %w", config.NetworkMode, err)
+		}
+		if err := validateIPAddress(config.IPAddress, subnets); err != nil {
+			return "", err
+		}
+	}
+
+	config.Labels = withTestLabel(config.Labels)
+	e.track(config.Name)
+
+	cmd := append([]string{RuntimeCommand}, buildRunArgs(config)...)
+	output, err := e.Exec(cmd...)
+	if err != nil {
+		return "", err
+	}
+
+	outLines := strings.Split(output, "\n")
+	return outLines[len(outLines)-1], nil
+}
+
+// ExecContainer runs a command in a running container.
+func (e *dockerExecutor) ExecContainer(containerName string, cmd []string) (string, error) {
+	return e.ExecContainerOpts(containerName, ExecOptions{Cmd: cmd})
+}
+
+// ExecContainerOpts runs a command in a running container, with the
+// environment, working directory, user and stdin of the options.
+func (e *dockerExecutor) ExecContainerOpts(containerName string, opts ExecOptions) (string, error) {
+	cmd := append([]string{RuntimeCommand}, buildExecArgs(containerName, opts)...)
+	if opts.Stdin != "" {
+		return e.ExecWithStdin(opts.Stdin, cmd...)
+	}
+	return e.Exec(cmd...)
+}
+
+// track records a container started through StartContainer, to be removed
+// by CleanupTracked. It is recorded before it is started, as a failed start
+// may still leave a container behind.
+func (e *dockerExecutor) track(name string) {
+	e.trackedMutex.Lock()
+	defer e.trackedMutex.Unlock()
+
+	if !slices.Contains(e.tracked, name) {
+		e.tracked = append(e.tracked, name)
+	}
+}
+
+// CleanupTracked removes every container started through StartContainer, as
+// well as any other container labeled as started by the tests, including
+// those leaked by earlier runs. All containers are attempted, and all errors
+// are returned together.
+func (e *dockerExecutor) CleanupTracked() error {
+	e.trackedMutex.Lock()
+	containers := e.tracked
+	e.tracked = nil
+	e.trackedMutex.Unlock()
+
+	output, err := e.Exec(RuntimeCommand, "ps", "--all", "--quiet", "--filter", "label="+TestContainerLabel)
+	if err != nil {
+		return err
+	}
+	containers = append(containers, strings.Fields(strings.Trim(output, "\"'"))...)
+
+	var result error
+	for _, container := range containers {
+		if exists, _ := e.ContainerExists(ContainerFilter{Name: container}); !exists {
+			continue
+		}
+
+		if _, err := e.ExecWithoutRetry(RuntimeCommand, "rm", "--force", container); err != nil {
+			result = multierror.Append(result, fmt.Errorf("failed to remove %s: %w", container, err))
+		}
+	}
+	return result
+}
+
 // KillContainer runs the kill operation on the provided container name
 func (e *dockerExecutor) KillContainer(name string) (string, error) {
 	return e.ExecWithErrorCheck(containerErrorCheckFunction(name, "kill"), RuntimeCommand, "kill", name)
@@ -203,13 +582,171 @@ func (e *dockerExecutor) StopContainer(name string) (string, error) {
 	return e.ExecWithErrorCheck(containerErrorCheckFunction(name, "stop"), RuntimeCommand, "stop", name)
 }
 
-func (e *localCommandBuilder) ExecCommand(execArgs ...string) *exec.Cmd {
-	return exec.Command(execArgs[0], execArgs[1:]...)
+// StopContainerWithSignal runs the stop operation on the provided container
+// name, sending the given signal rather than the container's stop signal.
+func (e *dockerExecutor) StopContainerWithSignal(name string, signal string) (string, error) {
+	return e.ExecWithErrorCheck(containerErrorCheckFunction(name, "stop"), RuntimeCommand, "stop", "--signal", signal, name)
+}
+
+// RestartContainer runs the restart operation on the provided container name.
+// The container keeps its ID, with a fresh main process.
+func (e *dockerExecutor) RestartContainer(name string) (string, error) {
+	return e.Exec(RuntimeCommand, "restart", name)
+}
+
+// PingRuntime checks that the container runtime daemon is responding.
+func (e *dockerExecutor) PingRuntime() error {
+	output, err := e.ExecWithoutRetry(RuntimeCommand, "info", "--format", "'{{.ID}}'")
+	if err != nil {
+		return fmt.Errorf("%w: %s", err, output)
+	}
+	return nil
+}
+
+// Reconnect recreates the command builder used to reach the runtime, as
+// after a daemon restart, and checks the runtime is responding again.
+func (e *dockerExecutor) Reconnect() error {
+	e.builder = newCommandBuilder()
+	return e.PingRuntime()
+}
+
+// ExposeToHost makes a local port reachable on the same port of the host
+// running the containers, e.g. for collector to reach the mock sensor when
+// the containers run on a remote host. It is a no-op for the local host.
+func (e *dockerExecutor) ExposeToHost(port int) (stop func(), err error) {
+	if ssh, ok := e.builder.(*sshCommandBuilder); ok {
+		return ssh.startReverseTunnel(port)
+	}
+	return func() {}, nil
+}
+
+// ListContainers lists the names of all containers, running or not,
+// matching the provided filter, in the format of the runtime's --filter
+// option (e.g. label=foo). An empty filter lists all containers.
+func (e *dockerExecutor) ListContainers(filter string) ([]string, error) {
+	args := []string{RuntimeCommand, "ps", "-a", "--format='{{.Names}}'"}
+	if filter != "" {
+		args = append(args, "--filter", filter)
+	}
+
+	result, err := e.Exec(args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseContainerNames(result), nil
+}
+
+// CreateNetwork creates a user-defined network, labeled as created by the
+// tests so that it can be cleaned up if it is leaked.
+func (e *dockerExecutor) CreateNetwork(name string) error {
+	return e.CreateNetworkWithOptions(name, NetworkOptions{})
 }
 
-func (e *localCommandBuilder) RemoteCopyCommand(remoteSrc string, localDst string) *exec.Cmd {
+// CreateNetworkWithOptions creates a user-defined network as CreateNetwork,
+// with the given options.
+func (e *dockerExecutor) CreateNetworkWithOptions(name string, opts NetworkOptions) error {
+	args := []string{RuntimeCommand, "network", "create", "--label", TestNetworkLabel + "=true"}
+	if opts.Subnet != "" {
+		args = append(args, "--subnet", opts.Subnet)
+	}
+
+	_, err := e.Exec(append(args, name)...)
+	return err
+}
+
+// networkSubnets returns the subnets of the network with the provided name.
+func (e *dockerExecutor) networkSubnets(name string) ([]string, error) {
+	output, err := e.Exec(RuntimeCommand, "network", "inspect",
+		"--format='{{range .IPAM.Config}}{{.Subnet}} {{end}}'", name)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(strings.Trim(output, "\"'")), nil
+}
+
+// ListNetworks lists all networks matching the provided filter, in the
+// format of the runtime's --filter option (e.g. label=foo). An empty
+// filter lists all networks.
+func (e *dockerExecutor) ListNetworks(filter string) ([]NetworkInfo, error) {
+	args := []string{RuntimeCommand, "network", "ls", "--no-trunc", "--format='{{.ID}} {{.Name}} {{.Labels}}'"}
+	if filter != "" {
+		args = append(args, "--filter", filter)
+	}
+
+	result, err := e.Exec(args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseNetworkList(result), nil
+}
+
+// RemoveNetwork removes the network with the provided name
+func (e *dockerExecutor) RemoveNetwork(name string) error {
+	_, err := e.Exec(RuntimeCommand, "network", "rm", name)
+	return err
+}
+
+func (e *localCommandBuilder) ExecCommand(ctx context.Context, execArgs ...string) *exec.Cmd {
+	return exec.CommandContext(ctx, execArgs[0], execArgs[1:]...)
+}
+
+func (e *localCommandBuilder) RemoteCopyCommand(ctx context.Context, remoteSrc string, localDst string) *exec.Cmd {
 	if remoteSrc != localDst {
-		return exec.Command("cp", remoteSrc, localDst)
+		return exec.CommandContext(ctx, "cp", remoteSrc, localDst)
 	}
 	return nil
 }
+
+// GetHostDmesg returns the host kernel ring buffer messages logged since the
+// provided time. dmesg is run in a short-lived privileged container, together
+// with /proc/uptime so the relative timestamps can be mapped to wall-clock time.
+func (e *dockerExecutor) GetHostDmesg(since time.Time) (string, error) {
+	image := config.Images().ImageByKey("busybox")
+	err := e.PullImage(image)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := e.Exec(RuntimeCommand, "run", "--rm", "--privileged", image,
+		"sh", "-c", "cat /proc/uptime && dmesg")
+	if err != nil {
+		return "", err
+	}
+
+	return filterDmesgSince(output, time.Now(), since), nil
+}
+
+// filterDmesgSince takes the output of 'cat /proc/uptime && dmesg' captured
+// at the given time, and returns only the dmesg lines logged after since.
+// Lines without a parseable timestamp are kept.
+func filterDmesgSince(output string, now time.Time, since time.Time) string {
+	lines := strings.Split(output, "\n")
+	if len(lines) == 0 {
+		return ""
+	}
+
+	uptimeFields := strings.Fields(lines[0])
+	if len(uptimeFields) == 0 {
+		return strings.Join(lines, "\n")
+	}
+
+	uptime, err := strconv.ParseFloat(uptimeFields[0], 64)
+	if err != nil {
+		return strings.Join(lines, "\n")
+	}
+	boot := now.Add(-time.Duration(uptime * float64(time.Second)))
+
+	filtered := []string{}
+	for _, line := range lines[1:] {
+		start, end := strings.Index(line, "["), strings.Index(line, "]")
+		if start == 0 && end > start {
+			ts, err := strconv.ParseFloat(strings.TrimSpace(line[start+1:end]), 64)
+			if err == nil && boot.Add(time.Duration(ts*float64(time.Second))).Before(since) {
+				continue
+			}
+		}
+		filtered = append(filtered, line)
+	}
+
+	return strings.Join(filtered, "\n")
+}
@@ -1,18 +1,25 @@
 package executor
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/stackrox/collector/integration-tests/pkg/common"
+	"golang.org/x/sys/unix"
 	coreV1 "k8s.io/api/core/v1"
 	metaV1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
 )
 
 const (
@@ -20,25 +27,27 @@ const (
 )
 
 type K8sExecutor struct {
-	clientset *kubernetes.Clientset
+	clientset  *kubernetes.Clientset
+	restConfig *rest.Config
 }
 
 func newK8sExecutor() (*K8sExecutor, error) {
-	fmt.Println("Creating k8s configuration")
+	logger.Info("Creating k8s configuration")
 	config, err := rest.InClusterConfig()
 	if err != nil {
-		fmt.Printf("Error: Failed to get cluster config: %s\n", err)
+		logger.Error("Failed to get cluster config", "err", err)
 		return nil, err
 	}
 
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
-		fmt.Printf("Error: Failed to create client: %s", err)
+		logger.Error("Failed to create client", "err", err)
 		return nil, err
 	}
 
 	k8s := &K8sExecutor{
-		clientset: clientset,
+		clientset:  clientset,
+		restConfig: config,
 	}
 	return k8s, nil
 }
@@ -51,13 +60,21 @@ func (e *K8sExecutor) PullImage(image string) error {
 	return fmt.Errorf("Unimplemented")
 }
 
+func (e *K8sExecutor) PullImageWithPolicy(image string, policy PullPolicy) error {
+	return fmt.Errorf("Unimplemented")
+}
+
+func (e *K8sExecutor) LoadImageFromArchive(path string) ([]string, error) {
+	return nil, fmt.Errorf("Unimplemented")
+}
+
 func (e *K8sExecutor) IsContainerRunning(podName string) (bool, error) {
 	pod, err := e.clientset.CoreV1().Pods(TESTS_NAMESPACE).Get(context.Background(), podName, metaV1.GetOptions{})
 	if err != nil {
 		return false, err
 	}
 
-	if pod == nil || pod.Status.Phase != coreV1.PodRunning {
+	if pod == nil || pod.Status.Phase != coreV1.PodRunning || len(pod.Status.ContainerStatuses) == 0 {
 		return false, nil
 	}
 
@@ -67,7 +84,7 @@ func (e *K8sExecutor) IsContainerRunning(podName string) (bool, error) {
 func (e *K8sExecutor) ContainerID(podFilter ContainerFilter) string {
 	pod, err := e.ClientSet().CoreV1().Pods(podFilter.Namespace).Get(context.Background(), podFilter.Name, metaV1.GetOptions{})
 	if err != nil {
-		fmt.Printf("%s\n", err)
+		logger.Error("Failed to get pod", "pod", podFilter.Name, "err", err)
 		return ""
 	}
 
@@ -81,13 +98,13 @@ func (e *K8sExecutor) ContainerID(podFilter ContainerFilter) string {
 	 */
 	containerID := pod.Status.ContainerStatuses[0].ContainerID
 	if len(containerID) < 12 {
-		fmt.Printf("Invalid container ID: %q\n", containerID)
+		logger.Error("Invalid container ID", "id", containerID)
 		return ""
 	}
 
 	i := strings.LastIndex(containerID, "/")
 	if i == -1 {
-		fmt.Printf("Invalid container ID: %q\n", containerID)
+		logger.Error("Invalid container ID", "id", containerID)
 		return ""
 	}
 
@@ -121,6 +138,93 @@ func (e *K8sExecutor) ExitCode(podFilter ContainerFilter) (int, error) {
 	return int(terminated.ExitCode), nil
 }
 
+func (e *K8sExecutor) GetContainerExitReason(podName string) (exitCode int, signal string, oomKilled bool, err error) {
+	pod, err := e.clientset.CoreV1().Pods(TESTS_NAMESPACE).Get(context.Background(), podName, metaV1.GetOptions{})
+	if err != nil {
+		return -1, "", false, err
+	}
+
+	if len(pod.Status.ContainerStatuses) == 0 {
+		return -1, "", false, fmt.Errorf("no container status for pod %s", podName)
+	}
+
+	terminated := pod.Status.ContainerStatuses[0].State.Terminated
+	if terminated == nil {
+		return -1, "", false, fmt.Errorf("failed to get termination status")
+	}
+
+	exitCode = int(terminated.ExitCode)
+	signal = SignalFromExitCode(exitCode)
+	if terminated.Signal != 0 {
+		signal = unix.SignalName(syscall.Signal(terminated.Signal))
+	}
+
+	return exitCode, signal, terminated.Reason == "OOMKilled", nil
+}
+
+func (e *K8sExecutor) GetContainerUptime(podName string) (time.Duration, error) {
+	pod, err := e.clientset.CoreV1().Pods(TESTS_NAMESPACE).Get(context.Background(), podName, metaV1.GetOptions{})
+	if err != nil {
+		return 0, err
+	}
+
+	if len(pod.Status.ContainerStatuses) == 0 {
+		return 0, fmt.Errorf("no container status for pod %s", podName)
+	}
+
+	running := pod.Status.ContainerStatuses[0].State.Running
+	if running == nil {
+		return 0, fmt.Errorf("pod %s is not running", podName)
+	}
+
+	return time.Since(running.StartedAt.Time), nil
+}
+
+// GetContainerTimes returns when the first container of a pod was last
+// started and, once it has terminated, when it finished, as recorded by the
+// kubelet.
+func (e *K8sExecutor) GetContainerTimes(podName string) (started time.Time, finished time.Time, err error) {
+	state, err := e.GetContainerState(podName)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return state.StartedAt, state.FinishedAt, nil
+}
+
+// GetContainerState returns the state of the first container of a pod. The
+// host PID and health of the container are not reported by K8s, and are
+// left empty.
+func (e *K8sExecutor) GetContainerState(podName string) (ContainerState, error) {
+	pod, err := e.clientset.CoreV1().Pods(TESTS_NAMESPACE).Get(context.Background(), podName, metaV1.GetOptions{})
+	if err != nil {
+		return ContainerState{}, err
+	}
+
+	if len(pod.Status.ContainerStatuses) == 0 {
+		return ContainerState{}, fmt.Errorf("no container status for pod %s", podName)
+	}
+
+	status := pod.Status.ContainerStatuses[0].State
+	switch {
+	case status.Running != nil:
+		return ContainerState{
+			Status:    "running",
+			Running:   true,
+			StartedAt: status.Running.StartedAt.Time,
+		}, nil
+	case status.Terminated != nil:
+		return ContainerState{
+			Status:     "exited",
+			ExitCode:   int(status.Terminated.ExitCode),
+			OOMKilled:  status.Terminated.Reason == "OOMKilled",
+			StartedAt:  status.Terminated.StartedAt.Time,
+			FinishedAt: status.Terminated.FinishedAt.Time,
+		}, nil
+	default:
+		return ContainerState{Status: "waiting"}, nil
+	}
+}
+
 func (e *K8sExecutor) Exec(args ...string) (string, error) {
 	return "", fmt.Errorf("Unimplemented")
 }
@@ -150,6 +254,132 @@ func (e *K8sExecutor) StopContainer(name string) (string, error) {
 	return "", fmt.Errorf("Unimplemented")
 }
 
+func (e *K8sExecutor) StopContainerWithSignal(name string, signal string) (string, error) {
+	return "", fmt.Errorf("Unimplemented")
+}
+
+func (e *K8sExecutor) RestartContainer(name string) (string, error) {
+	return "", fmt.Errorf("Unimplemented")
+}
+
+// PingRuntime checks that the API server is responding.
+func (e *K8sExecutor) PingRuntime() error {
+	_, err := e.clientset.Discovery().ServerVersion()
+	return err
+}
+
+// Reconnect recreates the client from the cluster configuration, as after
+// a disruption of the API server, and checks the API server is responding.
+// Copies of the executor keep using the previous client.
+func (e *K8sExecutor) Reconnect() error {
+	clientset, err := kubernetes.NewForConfig(e.restConfig)
+	if err != nil {
+		return err
+	}
+
+	e.clientset = clientset
+	return e.PingRuntime()
+}
+
+func (e *K8sExecutor) ExposeToHost(port int) (func(), error) {
+	return nil, fmt.Errorf("Unimplemented")
+}
+
+func (e *K8sExecutor) GetContainerResolvConf(containerID string) (string, error) {
+	return "", fmt.Errorf("Unimplemented")
+}
+
+func (e *K8sExecutor) GetContainerSockets(containerID string) ([]Socket, error) {
+	return nil, fmt.Errorf("Unimplemented")
+}
+
+func (e *K8sExecutor) GetContainerExecSessions(containerID string) ([]ExecSession, error) {
+	return nil, fmt.Errorf("Unimplemented")
+}
+
+func (e *K8sExecutor) GetHostDmesg(since time.Time) (string, error) {
+	return "", fmt.Errorf("Unimplemented")
+}
+
+func (e *K8sExecutor) GetContainerPID(containerID string) (int, error) {
+	return -1, fmt.Errorf("Unimplemented")
+}
+
+// GetContainerIPv6 returns the IPv6 address of a pod of the tests
+// namespace, or an empty string if it has none, i.e. on single-stack
+// IPv4 clusters.
+func (e *K8sExecutor) GetContainerIPv6(podName string) (string, error) {
+	pod, err := e.ClientSet().CoreV1().Pods(TESTS_NAMESPACE).Get(context.Background(), podName, metaV1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	addresses := []string{}
+	for _, podIP := range pod.Status.PodIPs {
+		addresses = append(addresses, podIP.IP)
+	}
+	return firstIPv6(addresses), nil
+}
+
+func (e *K8sExecutor) GetContainerCapabilities(containerID string) ([]string, bool, error) {
+	return nil, false, fmt.Errorf("Unimplemented")
+}
+
+func (e *K8sExecutor) GetContainerChanges(containerID string) ([]FilesystemChange, error) {
+	return nil, fmt.Errorf("Unimplemented")
+}
+
+func (e *K8sExecutor) GetContainerMountPropagation(containerID string) (map[string]string, error) {
+	return nil, fmt.Errorf("Unimplemented")
+}
+
+func (e *K8sExecutor) StartContainer(config ContainerStartConfig) (string, error) {
+	return "", fmt.Errorf("Unimplemented")
+}
+
+// ExecContainer is not supported on K8s, where commands are run in pods
+// through ExecInPod instead.
+func (e *K8sExecutor) ExecContainer(containerName string, cmd []string) (string, error) {
+	return e.ExecContainerOpts(containerName, ExecOptions{Cmd: cmd})
+}
+
+func (e *K8sExecutor) ExecContainerOpts(containerName string, opts ExecOptions) (string, error) {
+	return "", fmt.Errorf("Unimplemented")
+}
+
+// CleanupTracked has nothing to clean up, as containers cannot be started
+// through StartContainer on K8s.
+func (e *K8sExecutor) CleanupTracked() error {
+	return nil
+}
+
+// FollowContainerLogs streams the logs of the first container of the pod
+// as they are written. Closing the returned reader stops following.
+func (e *K8sExecutor) FollowContainerLogs(podName string) (io.ReadCloser, error) {
+	req := e.clientset.CoreV1().Pods(TESTS_NAMESPACE).GetLogs(podName, &coreV1.PodLogOptions{Follow: true})
+	return req.Stream(context.Background())
+}
+
+func (e *K8sExecutor) ListContainers(filter string) ([]string, error) {
+	return nil, fmt.Errorf("Unimplemented")
+}
+
+func (e *K8sExecutor) CreateNetwork(name string) error {
+	return fmt.Errorf("Unimplemented")
+}
+
+func (e *K8sExecutor) CreateNetworkWithOptions(name string, opts NetworkOptions) error {
+	return fmt.Errorf("Unimplemented")
+}
+
+func (e *K8sExecutor) ListNetworks(filter string) ([]NetworkInfo, error) {
+	return nil, fmt.Errorf("Unimplemented")
+}
+
+func (e *K8sExecutor) RemoveNetwork(name string) error {
+	return fmt.Errorf("Unimplemented")
+}
+
 func (e *K8sExecutor) CreateNamespace(ns string) (*coreV1.Namespace, error) {
 	meta := metaV1.ObjectMeta{Name: ns}
 	return e.clientset.CoreV1().Namespaces().Create(context.Background(), &coreV1.Namespace{ObjectMeta: meta}, metaV1.CreateOptions{})
@@ -176,6 +406,34 @@ func (e *K8sExecutor) ClientSet() *kubernetes.Clientset {
 	return e.clientset
 }
 
+// ExecInPod runs a command in a container of the given pod, and returns
+// its stdout and stderr separately.
+func (e *K8sExecutor) ExecInPod(namespace, pod, container string, cmd []string) (stdout, stderr string, err error) {
+	req := e.clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(namespace).
+		Name(pod).
+		SubResource("exec").
+		VersionedParams(&coreV1.PodExecOptions{
+			Container: container,
+			Command:   cmd,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	exec, err := remotecommand.NewSPDYExecutor(e.restConfig, "POST", req.URL())
+	if err != nil {
+		return "", "", err
+	}
+
+	var stdoutBuf, stderrBuf bytes.Buffer
+	err = exec.StreamWithContext(context.Background(), remotecommand.StreamOptions{
+		Stdout: &stdoutBuf,
+		Stderr: &stderrBuf,
+	})
+	return stdoutBuf.String(), stderrBuf.String(), err
+}
+
 func (e *K8sExecutor) CapturePodConfiguration(testName, ns, podName string) error {
 	pod, err := e.clientset.CoreV1().Pods(ns).Get(context.Background(), podName, metaV1.GetOptions{})
 	if err != nil {
@@ -218,14 +476,13 @@ func (e *K8sExecutor) CreateNamespaceEventWatcher(testName, ns string) (watch.In
 		for event := range watcher.ResultChan() {
 			eventJson, err := json.Marshal(event)
 			if err != nil {
-				fmt.Printf("Failed to marshal event: %+v\n", event)
-				fmt.Printf("%s\n", err)
+				logger.Error("Failed to marshal event", "event", fmt.Sprintf("%+v", event), "err", err)
 				return
 			}
 
 			_, err = logFile.WriteString(string(eventJson) + "\n")
 			if err != nil {
-				fmt.Printf("Failed to write to event file: %s\n", err)
+				logger.Error("Failed to write to event file", "err", err)
 				return
 			}
 		}
@@ -0,0 +1,36 @@
+package executor
+
+import (
+	"fmt"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// SignalFromExitCode returns the name of the signal that terminated a
+// container, following the shell convention of exit codes of 128+n for
+// signal n. An empty string is returned for regular exit codes.
+func SignalFromExitCode(exitCode int) string {
+	if exitCode <= 128 || exitCode > 128+64 {
+		return ""
+	}
+
+	name := unix.SignalName(syscall.Signal(exitCode - 128))
+	if name == "" {
+		return fmt.Sprintf("signal %d", exitCode-128)
+	}
+	return name
+}
+
+// DescribeExit returns a human readable summary of the reason a container
+// exited, suitable for error messages.
+func DescribeExit(exitCode int, signal string, oomKilled bool) string {
+	description := fmt.Sprintf("exit code %d", exitCode)
+	if signal != "" {
+		description += ", signal " + signal
+	}
+	if oomKilled {
+		description += ", OOM killed"
+	}
+	return description
+}
@@ -0,0 +1,25 @@
+package executor
+
+import (
+	"strings"
+)
+
+// parseLoadedImages returns the references of the images loaded by an
+// 'image load' command, from its output. Images without a tag are
+// referenced by ID. Older podman versions list all images on a single
+// comma-separated line.
+func parseLoadedImages(output string) []string {
+	refs := []string{}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		for _, prefix := range []string{"Loaded image:", "Loaded image ID:", "Loaded image(s):"} {
+			if loaded, found := strings.CutPrefix(line, prefix); found {
+				for _, ref := range strings.Split(loaded, ",") {
+					refs = append(refs, strings.TrimSpace(ref))
+				}
+				break
+			}
+		}
+	}
+	return refs
+}
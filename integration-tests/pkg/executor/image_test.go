@@ -0,0 +1,25 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLoadedImages(t *testing.T) {
+	output := "Getting image source signatures\n" +
+		"Loaded image: quay.io/example/server:1.0\n" +
+		"Loaded image: quay.io/example/client:1.0\n" +
+		"Loaded image ID: sha256:4f0e3d1b\n"
+
+	assert.Equal(t, []string{
+		"quay.io/example/server:1.0",
+		"quay.io/example/client:1.0",
+		"sha256:4f0e3d1b",
+	}, parseLoadedImages(output))
+
+	assert.Equal(t, []string{"localhost/a:1", "localhost/b:2"},
+		parseLoadedImages("Loaded image(s): localhost/a:1,localhost/b:2"))
+
+	assert.Empty(t, parseLoadedImages("open /tmp/missing.tar: no such file or directory"))
+}
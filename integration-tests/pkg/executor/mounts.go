@@ -0,0 +1,30 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// mount is the subset of a container mount, as reported by inspect, that
+// the tests care about.
+type mount struct {
+	Destination string
+	Propagation string
+}
+
+// parseMountPropagation returns the propagation mode of each mount of a
+// container, keyed by its destination, from the JSON list of mounts
+// reported by inspect. Mounts without a propagation mode (e.g. volumes)
+// map to an empty string.
+func parseMountPropagation(mountsJson string) (map[string]string, error) {
+	var mounts []mount
+	if err := json.Unmarshal([]byte(mountsJson), &mounts); err != nil {
+		return nil, fmt.Errorf("failed to parse container mounts: %w", err)
+	}
+
+	propagation := make(map[string]string, len(mounts))
+	for _, m := range mounts {
+		propagation[m.Destination] = m.Propagation
+	}
+	return propagation, nil
+}
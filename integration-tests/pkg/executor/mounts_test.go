@@ -0,0 +1,24 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseMountPropagation(t *testing.T) {
+	mounts := `[{"Type":"bind","Source":"/proc","Destination":"/host/proc","Mode":"ro","RW":false,"Propagation":"rslave"},` +
+		`{"Type":"bind","Source":"/tmp","Destination":"/tmp","Mode":"","RW":true,"Propagation":"rprivate"},` +
+		`{"Type":"volume","Name":"abc","Destination":"/data","Driver":"local","RW":true,"Propagation":""}]`
+
+	propagation, err := parseMountPropagation(mounts)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"/host/proc": "rslave",
+		"/tmp":       "rprivate",
+		"/data":      "",
+	}, propagation)
+
+	_, err = parseMountPropagation("<no value>")
+	assert.Error(t, err)
+}
@@ -0,0 +1,120 @@
+package executor
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// TestNetworkLabel is applied to every network created by the tests, so
+// that networks leaked by failed tests can be found and removed.
+const TestNetworkLabel = "io.stackrox.collector.integration-test"
+
+type NetworkInfo struct {
+	ID     string
+	Name   string
+	Labels map[string]string
+}
+
+// NetworkOptions describes a network to be created with
+// CreateNetworkWithOptions. Zero values leave the runtime defaults in place.
+type NetworkOptions struct {
+	// Subnet in CIDR notation, e.g. 172.30.0.0/24, which is required to
+	// assign static addresses to containers (see ContainerStartConfig)
+	Subnet string
+}
+
+// RemoveNetworks removes all networks for which match returns true. All
+// matching networks are attempted, and the first failure is returned.
+func RemoveNetworks(e Executor, match func(NetworkInfo) bool) error {
+	networks, err := e.ListNetworks("")
+	if err != nil {
+		return err
+	}
+
+	var firstErr error
+	for _, network := range networks {
+		if !match(network) {
+			continue
+		}
+
+		if err := e.RemoveNetwork(network.Name); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("failed to remove network %s: %w", network.Name, err)
+		}
+	}
+	return firstErr
+}
+
+// RemoveNetworksByLabel removes all networks that have the given label.
+func RemoveNetworksByLabel(e Executor, label string) error {
+	return RemoveNetworks(e, func(network NetworkInfo) bool {
+		_, ok := network.Labels[label]
+		return ok
+	})
+}
+
+// RemoveNetworksByPrefix removes all networks whose name starts with prefix.
+func RemoveNetworksByPrefix(e Executor, prefix string) error {
+	return RemoveNetworks(e, func(network NetworkInfo) bool {
+		return strings.HasPrefix(network.Name, prefix)
+	})
+}
+
+// parseNetworkList parses the output of 'network ls' formatted as
+// '{{.ID}} {{.Name}} {{.Labels}}', with one network per line.
+func parseNetworkList(output string) []NetworkInfo {
+	networks := []NetworkInfo{}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.SplitN(strings.Trim(line, "\"' "), " ", 3)
+		if len(fields) < 2 {
+			continue
+		}
+
+		network := NetworkInfo{
+			ID:     fields[0],
+			Name:   fields[1],
+			Labels: map[string]string{},
+		}
+
+		if len(fields) == 3 && fields[2] != "" {
+			for _, label := range strings.Split(fields[2], ",") {
+				key, value, _ := strings.Cut(label, "=")
+				network.Labels[key] = value
+			}
+		}
+
+		networks = append(networks, network)
+	}
+	return networks
+}
+
+// validateIPAddress checks that ip is an address in one of the subnets,
+// as the runtime only assigns static addresses from the network's subnets.
+func validateIPAddress(ip string, subnets []string) error {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return fmt.Errorf("invalid IP address %q", ip)
+	}
+
+	for _, subnet := range subnets {
+		_, ipNet, err := net.ParseCIDR(subnet)
+		if err != nil {
+			return fmt.Errorf("invalid subnet %q: %w", subnet, err)
+		}
+		if ipNet.Contains(addr) {
+			return nil
+		}
+	}
+	return fmt.Errorf("IP address %s is not in the network's subnets %v", ip, subnets)
+}
+
+// firstIPv6 returns the first of the addresses that is an IPv6 address, or
+// an empty string if there is none.
+func firstIPv6(addresses []string) string {
+	for _, address := range addresses {
+		if ip := net.ParseIP(address); ip != nil && ip.To4() == nil {
+			return address
+		}
+	}
+	return ""
+}
@@ -0,0 +1,70 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeNetworkExecutor struct {
+	Executor
+	networks []NetworkInfo
+	removed  []string
+}
+
+func (f *fakeNetworkExecutor) ListNetworks(filter string) ([]NetworkInfo, error) {
+	return f.networks, nil
+}
+
+func (f *fakeNetworkExecutor) RemoveNetwork(name string) error {
+	f.removed = append(f.removed, name)
+	return nil
+}
+
+func TestRemoveNetworks(t *testing.T) {
+	networks := []NetworkInfo{
+		{ID: "1", Name: "bridge", Labels: map[string]string{}},
+		{ID: "2", Name: "udp-tests", Labels: map[string]string{TestNetworkLabel: "true"}},
+		{ID: "3", Name: "udp-tests-orphan", Labels: map[string]string{}},
+		{ID: "4", Name: "other", Labels: map[string]string{"unrelated": "label"}},
+	}
+
+	e := &fakeNetworkExecutor{networks: networks}
+	assert.NoError(t, RemoveNetworksByPrefix(e, "udp-tests"))
+	assert.Equal(t, []string{"udp-tests", "udp-tests-orphan"}, e.removed)
+
+	e = &fakeNetworkExecutor{networks: networks}
+	assert.NoError(t, RemoveNetworksByLabel(e, TestNetworkLabel))
+	assert.Equal(t, []string{"udp-tests"}, e.removed)
+}
+
+func TestParseNetworkList(t *testing.T) {
+	output := "'abc123 bridge '\n" +
+		"'def456 udp-tests io.stackrox.collector.integration-test=true,owner=tests'\n"
+
+	expected := []NetworkInfo{
+		{ID: "abc123", Name: "bridge", Labels: map[string]string{}},
+		{ID: "def456", Name: "udp-tests", Labels: map[string]string{
+			TestNetworkLabel: "true",
+			"owner":          "tests",
+		}},
+	}
+
+	assert.Equal(t, expected, parseNetworkList(output))
+}
+
+func TestValidateIPAddress(t *testing.T) {
+	subnets := []string{"172.30.0.0/24", "fd00::/64"}
+
+	assert.NoError(t, validateIPAddress("172.30.0.10", subnets))
+	assert.NoError(t, validateIPAddress("fd00::10", subnets))
+	assert.ErrorContains(t, validateIPAddress("172.30.1.10", subnets), "not in the network's subnets")
+	assert.ErrorContains(t, validateIPAddress("172.30.0.10", nil), "not in the network's subnets")
+	assert.ErrorContains(t, validateIPAddress("not-an-ip", subnets), "invalid IP address")
+}
+
+func TestFirstIPv6(t *testing.T) {
+	assert.Equal(t, "fd00::2", firstIPv6([]string{"172.17.0.2", "fd00::2", "fd01::2"}))
+	assert.Equal(t, "", firstIPv6([]string{"172.17.0.2", "::ffff:172.17.0.3"}))
+	assert.Equal(t, "", firstIPv6(nil))
+}
@@ -0,0 +1,37 @@
+package executor
+
+import (
+	"fmt"
+
+	"github.com/stackrox/collector/integration-tests/pkg/config"
+)
+
+// PullPolicy governs when images are pulled, with the same semantics as
+// the K8s image pull policy.
+type PullPolicy string
+
+const (
+	// PullAlways pulls the image even if it is present locally, so a
+	// stale cached image is never used.
+	PullAlways PullPolicy = "Always"
+	// PullIfNotPresent only pulls the image if it is absent locally.
+	PullIfNotPresent PullPolicy = "IfNotPresent"
+	// PullNever never pulls the image, and fails if it is absent locally,
+	// e.g. to test an image freshly built on the host.
+	PullNever PullPolicy = "Never"
+)
+
+// ResolvePullPolicy validates the pull policy, replacing an empty one
+// with the configured policy.
+func ResolvePullPolicy(policy PullPolicy) (PullPolicy, error) {
+	if policy == "" {
+		policy = PullPolicy(config.ImagePullPolicy())
+	}
+
+	switch policy {
+	case PullAlways, PullIfNotPresent, PullNever:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("invalid image pull policy: %q", policy)
+	}
+}
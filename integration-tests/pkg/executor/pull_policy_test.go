@@ -0,0 +1,25 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolvePullPolicy(t *testing.T) {
+	policy, err := ResolvePullPolicy("")
+	assert.NoError(t, err)
+	assert.Equal(t, PullIfNotPresent, policy)
+
+	t.Setenv("IMAGE_PULL_POLICY", "Never")
+	policy, err = ResolvePullPolicy("")
+	assert.NoError(t, err)
+	assert.Equal(t, PullNever, policy)
+
+	policy, err = ResolvePullPolicy(PullAlways)
+	assert.NoError(t, err)
+	assert.Equal(t, PullAlways, policy)
+
+	_, err = ResolvePullPolicy("Sometimes")
+	assert.ErrorContains(t, err, "Sometimes")
+}
@@ -0,0 +1,25 @@
+package executor
+
+import (
+	"strings"
+)
+
+// ParseResolvConf returns the name servers and search domains configured
+// in the content of a resolv.conf file, in order.
+func ParseResolvConf(content string) (nameservers []string, search []string) {
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+
+		switch fields[0] {
+		case "nameserver":
+			nameservers = append(nameservers, fields[1])
+		case "search":
+			// the last search directive overrides the previous ones
+			search = fields[1:]
+		}
+	}
+	return nameservers, search
+}
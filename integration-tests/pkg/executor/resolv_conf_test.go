@@ -0,0 +1,24 @@
+package executor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseResolvConf(t *testing.T) {
+	nameservers, search := ParseResolvConf(`# Generated by Docker Engine.
+search example.com
+nameserver 10.0.0.53
+nameserver 10.0.0.54
+search collector.test svc.local
+options ndots:0
+`)
+
+	assert.Equal(t, []string{"10.0.0.53", "10.0.0.54"}, nameservers)
+	assert.Equal(t, []string{"collector.test", "svc.local"}, search)
+
+	nameservers, search = ParseResolvConf("")
+	assert.Empty(t, nameservers)
+	assert.Empty(t, search)
+}
@@ -1,6 +1,7 @@
 package executor
 
 import (
+	"errors"
 	"time"
 
 	"github.com/stackrox/collector/integration-tests/pkg/common"
@@ -9,6 +10,10 @@ import (
 const (
 	max_retries     = 5
 	retry_wait_time = 3 * time.Second
+
+	// a timed out command is likely to time out again, so it is retried
+	// less, to bound the time spent on it
+	max_timeout_retries = 2
 )
 
 type retryable = func() (string, error)
@@ -21,11 +26,21 @@ func Retry(f retryable) (output string, err error) {
 
 // Simple retry with error checker
 func RetryWithErrorCheck(ec errorchecker, f retryable) (output string, err error) {
+	timeouts := 0
 	for i := 0; i < max_retries; i++ {
 		output, err = f()
 		if ec(output, err) == nil {
 			return output, nil
-		} else if i != max_retries-1 {
+		}
+
+		if errors.Is(err, ErrCommandTimeout) {
+			timeouts++
+			if timeouts == max_timeout_retries {
+				break
+			}
+		}
+
+		if i != max_retries-1 {
 			common.Sleep(retry_wait_time)
 		}
 	}
@@ -0,0 +1,99 @@
+package executor
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// Socket is a TCP socket, as listed in /proc/<pid>/net/tcp{,6}.
+type Socket struct {
+	LocalIP    net.IP
+	LocalPort  int
+	RemoteIP   net.IP
+	RemotePort int
+	State      string
+}
+
+// tcpStates are the names of the TCP states, indexed by their value in
+// /proc/<pid>/net/tcp.
+var tcpStates = []string{
+	"UNKNOWN",
+	"ESTABLISHED",
+	"SYN_SENT",
+	"SYN_RECV",
+	"FIN_WAIT1",
+	"FIN_WAIT2",
+	"TIME_WAIT",
+	"CLOSE",
+	"CLOSE_WAIT",
+	"LAST_ACK",
+	"LISTEN",
+	"CLOSING",
+}
+
+// parseSockets parses the content of /proc/<pid>/net/tcp and tcp6. Header
+// lines are skipped, so the files can be concatenated.
+func parseSockets(content string) ([]Socket, error) {
+	sockets := []Socket{}
+	for _, line := range strings.Split(content, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 4 || fields[0] == "sl" {
+			continue
+		}
+
+		localIP, localPort, err := parseSocketAddress(fields[1])
+		if err != nil {
+			return nil, err
+		}
+
+		remoteIP, remotePort, err := parseSocketAddress(fields[2])
+		if err != nil {
+			return nil, err
+		}
+
+		state, err := strconv.ParseUint(fields[3], 16, 8)
+		if err != nil || int(state) >= len(tcpStates) {
+			return nil, fmt.Errorf("invalid socket state %q", fields[3])
+		}
+
+		sockets = append(sockets, Socket{
+			LocalIP:    localIP,
+			LocalPort:  localPort,
+			RemoteIP:   remoteIP,
+			RemotePort: remotePort,
+			State:      tcpStates[state],
+		})
+	}
+	return sockets, nil
+}
+
+// parseSocketAddress parses an address of /proc/<pid>/net/tcp, e.g.
+// 0100007F:270F, where the IP address is made of 32-bit words in host byte
+// order (assumed little endian) and the port is in hex.
+func parseSocketAddress(address string) (net.IP, int, error) {
+	ipHex, portHex, found := strings.Cut(address, ":")
+	if !found {
+		return nil, 0, fmt.Errorf("invalid socket address %q", address)
+	}
+
+	raw, err := hex.DecodeString(ipHex)
+	if err != nil || (len(raw) != net.IPv4len && len(raw) != net.IPv6len) {
+		return nil, 0, fmt.Errorf("invalid socket address %q", address)
+	}
+
+	ip := make(net.IP, len(raw))
+	for i := 0; i < len(raw); i += 4 {
+		binary.BigEndian.PutUint32(ip[i:], binary.LittleEndian.Uint32(raw[i:]))
+	}
+
+	port, err := strconv.ParseUint(portHex, 16, 16)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid socket address %q", address)
+	}
+
+	return ip, int(port), nil
+}
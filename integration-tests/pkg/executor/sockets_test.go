@@ -0,0 +1,46 @@
+package executor
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const procNetTcp = `  sl  local_address rem_address   st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 12345 1 0000000000000000 100 0 0 10 0
+   1: 0100007F:B2A4 0100007F:270F 01 00000000:00000000 00:00000000 00000000     0        0 12346 1 0000000000000000 20 4 30 10 -1
+  sl  local_address                         remote_address                        st tx_queue rx_queue tr tm->when retrnsmt   uid  timeout inode
+   0: 00000000000000000000000001000000:0050 00000000000000000000000000000000:0000 0A 00000000:00000000 00:00000000 00000000     0        0 12347 1 0000000000000000 100 0 0 10 0
+`
+
+func TestParseSockets(t *testing.T) {
+	sockets, err := parseSockets(procNetTcp)
+	assert.NoError(t, err)
+	assert.Equal(t, []Socket{
+		{
+			LocalIP:    net.ParseIP("0.0.0.0").To4(),
+			LocalPort:  8080,
+			RemoteIP:   net.ParseIP("0.0.0.0").To4(),
+			RemotePort: 0,
+			State:      "LISTEN",
+		},
+		{
+			LocalIP:    net.ParseIP("127.0.0.1").To4(),
+			LocalPort:  45732,
+			RemoteIP:   net.ParseIP("127.0.0.1").To4(),
+			RemotePort: 9999,
+			State:      "ESTABLISHED",
+		},
+		{
+			LocalIP:    net.ParseIP("::1"),
+			LocalPort:  80,
+			RemoteIP:   net.ParseIP("::"),
+			RemotePort: 0,
+			State:      "LISTEN",
+		},
+	}, sockets)
+
+	_, err = parseSockets("   0: 0100007F 0100007F:270F 01")
+	assert.Error(t, err)
+}
@@ -0,0 +1,112 @@
+package executor
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/stackrox/collector/integration-tests/pkg/config"
+)
+
+// tunnelSetupTime is how long a tunnel is given to fail, e.g. because the
+// port is already bound on the remote host, before it is considered up.
+const tunnelSetupTime = 2 * time.Second
+
+// sshCommandBuilder runs the commands on a remote host over ssh, so that
+// the containers run there while the tests and their assertions run
+// locally.
+type sshCommandBuilder struct {
+	user    string
+	address string
+	options []string
+}
+
+func newSSHCommandBuilder() CommandBuilder {
+	host := config.HostInfo()
+	return &sshCommandBuilder{
+		user:    host.User,
+		address: host.Address,
+		options: strings.Fields(host.Options),
+	}
+}
+
+// newCommandBuilder returns the command builder for the configured host.
+func newCommandBuilder() CommandBuilder {
+	if config.HostInfo().IsSSH() {
+		return newSSHCommandBuilder()
+	}
+	return newLocalCommandBuilder()
+}
+
+func (e *sshCommandBuilder) target() string {
+	if e.user == "" {
+		return e.address
+	}
+	return e.user + "@" + e.address
+}
+
+// baseArgs returns the arguments shared by ssh and scp.
+func (e *sshCommandBuilder) baseArgs() []string {
+	args := []string{"-o", "StrictHostKeyChecking=no", "-o", "BatchMode=yes"}
+	return append(args, e.options...)
+}
+
+func (e *sshCommandBuilder) ExecCommand(ctx context.Context, execArgs ...string) *exec.Cmd {
+	args := append(e.baseArgs(), e.target(), "--")
+	// the remote shell splits the command again, so the arguments are
+	// quoted to survive it
+	for _, arg := range execArgs {
+		args = append(args, shellQuote(arg))
+	}
+	return exec.CommandContext(ctx, "ssh", args...)
+}
+
+// shellQuote quotes an argument for a POSIX shell, so that it is passed on
+// verbatim: it is wrapped in single quotes, within which nothing is special
+// except a single quote, which ends the quoting, is escaped, and starts it
+// again.
+func shellQuote(arg string) string {
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+func (e *sshCommandBuilder) RemoteCopyCommand(ctx context.Context, remoteSrc string, localDst string) *exec.Cmd {
+	args := append(e.baseArgs(), e.target()+":"+remoteSrc, localDst)
+	return exec.CommandContext(ctx, "scp", args...)
+}
+
+// reverseTunnelCommand returns the command forwarding a port on the remote
+// host to the same port locally, until it is killed.
+func (e *sshCommandBuilder) reverseTunnelCommand(port int) *exec.Cmd {
+	args := append(e.baseArgs(),
+		"-N", "-o", "ExitOnForwardFailure=yes",
+		"-R", fmt.Sprintf("%d:localhost:%d", port, port),
+		e.target())
+	return exec.Command("ssh", args...)
+}
+
+// startReverseTunnel starts forwarding a port on the remote host to the
+// same port locally, and returns a function stopping it.
+func (e *sshCommandBuilder) startReverseTunnel(port int) (stop func(), err error) {
+	cmd := e.reverseTunnelCommand(port)
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start tunnel for port %d: %w", port, err)
+	}
+
+	exited := make(chan error, 1)
+	go func() {
+		exited <- cmd.Wait()
+	}()
+
+	select {
+	case err := <-exited:
+		return nil, fmt.Errorf("tunnel for port %d exited: %v", port, err)
+	case <-time.After(tunnelSetupTime):
+	}
+
+	return func() {
+		cmd.Process.Kill()
+		<-exited
+	}, nil
+}
@@ -0,0 +1,55 @@
+package executor
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSSHCommandBuilder(t *testing.T) {
+	builder := &sshCommandBuilder{
+		user:    "tester",
+		address: "10.0.0.5",
+		options: []string{"-i", "/keys/id_rsa"},
+	}
+
+	cmd := builder.ExecCommand(context.Background(), "docker", "exec", "collector", "sh", "-c", "echo hello")
+	assert.Equal(t, []string{"ssh", "-o", "StrictHostKeyChecking=no", "-o", "BatchMode=yes",
+		"-i", "/keys/id_rsa", "tester@10.0.0.5", "--",
+		"'docker'", "'exec'", "'collector'", "'sh'", "'-c'", "'echo hello'"}, cmd.Args)
+
+	cmd = builder.RemoteCopyCommand(context.Background(), "/tmp/perf.data", "logs/perf.data")
+	assert.Equal(t, []string{"scp", "-o", "StrictHostKeyChecking=no", "-o", "BatchMode=yes",
+		"-i", "/keys/id_rsa", "tester@10.0.0.5:/tmp/perf.data", "logs/perf.data"}, cmd.Args)
+
+	cmd = builder.reverseTunnelCommand(9999)
+	assert.Equal(t, []string{"ssh", "-o", "StrictHostKeyChecking=no", "-o", "BatchMode=yes",
+		"-i", "/keys/id_rsa", "-N", "-o", "ExitOnForwardFailure=yes",
+		"-R", "9999:localhost:9999", "tester@10.0.0.5"}, cmd.Args)
+}
+
+func TestShellQuote(t *testing.T) {
+	assert.Equal(t, `'plain'`, shellQuote("plain"))
+	assert.Equal(t, `''`, shellQuote(""))
+	assert.Equal(t, "'$HOME `id` $(id)'", shellQuote("$HOME `id` $(id)"))
+	assert.Equal(t, `'it'\''s'`, shellQuote("it's"))
+	assert.Equal(t, `'--format='\''{{.ID}}'\'''`, shellQuote("--format='{{.ID}}'"))
+	assert.Equal(t, "'line one\nline two'", shellQuote("line one\nline two"))
+}
+
+func TestShellQuoteRoundTrip(t *testing.T) {
+	args := []string{"$HOME", "it's", "line one\nline two", `back\slash "double"`, "*", ""}
+
+	quoted := []string{}
+	for _, arg := range args {
+		quoted = append(quoted, shellQuote(arg))
+	}
+
+	// the shell prints each argument it was passed NUL terminated
+	output, err := exec.Command("sh", "-c", `printf '%s\0' `+strings.Join(quoted, " ")).Output()
+	assert.NoError(t, err)
+	assert.Equal(t, args, strings.Split(strings.TrimSuffix(string(output), "\x00"), "\x00"))
+}
@@ -0,0 +1,73 @@
+package executor
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// ContainerState is the state of a container at a point in time, as
+// reported by a single inspect, so that its fields are consistent with each
+// other even while the container changes.
+type ContainerState struct {
+	Status    string
+	Running   bool
+	Paused    bool
+	ExitCode  int
+	OOMKilled bool
+	// Health is the status of the health check (e.g. healthy), empty for
+	// containers without one
+	Health     string
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Pid        int
+}
+
+// healthState is the subset of the health check state reported by inspect
+// that the tests care about.
+type healthState struct {
+	Status string
+}
+
+// inspectState is the container state as reported by inspect. Older podman
+// versions report the health check state as Healthcheck, rather than Health.
+type inspectState struct {
+	Status      string
+	Running     bool
+	Paused      bool
+	ExitCode    int
+	OOMKilled   bool
+	Health      *healthState
+	Healthcheck *healthState
+	StartedAt   time.Time
+	FinishedAt  time.Time
+	Pid         int
+}
+
+// parseContainerState returns the state of a container from the JSON state
+// reported by inspect.
+func parseContainerState(stateJson string) (ContainerState, error) {
+	var state inspectState
+	if err := json.Unmarshal([]byte(stateJson), &state); err != nil {
+		return ContainerState{}, fmt.Errorf("failed to parse container state: %w", err)
+	}
+
+	health := ""
+	if state.Health != nil {
+		health = state.Health.Status
+	} else if state.Healthcheck != nil {
+		health = state.Healthcheck.Status
+	}
+
+	return ContainerState{
+		Status:     state.Status,
+		Running:    state.Running,
+		Paused:     state.Paused,
+		ExitCode:   state.ExitCode,
+		OOMKilled:  state.OOMKilled,
+		Health:     health,
+		StartedAt:  state.StartedAt,
+		FinishedAt: state.FinishedAt,
+		Pid:        state.Pid,
+	}, nil
+}
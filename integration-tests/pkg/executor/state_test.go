@@ -0,0 +1,38 @@
+package executor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseContainerState(t *testing.T) {
+	docker := `{"Status":"exited","Running":false,"Paused":false,"Restarting":false,"OOMKilled":true,"Dead":false,` +
+		`"Pid":0,"ExitCode":137,"Error":"","StartedAt":"2024-05-01T10:00:00.123456789Z","FinishedAt":"2024-05-01T10:05:00Z",` +
+		`"Health":{"Status":"unhealthy","FailingStreak":3,"Log":[]}}`
+
+	state, err := parseContainerState(docker)
+	assert.NoError(t, err)
+	assert.Equal(t, ContainerState{
+		Status:     "exited",
+		ExitCode:   137,
+		OOMKilled:  true,
+		Health:     "unhealthy",
+		StartedAt:  time.Date(2024, 5, 1, 10, 0, 0, 123456789, time.UTC),
+		FinishedAt: time.Date(2024, 5, 1, 10, 5, 0, 0, time.UTC),
+	}, state)
+
+	podman := `{"Status":"running","Running":true,"Paused":false,"OOMKilled":false,"Pid":4242,"ExitCode":0,` +
+		`"StartedAt":"2024-05-01T10:00:00Z","FinishedAt":"0001-01-01T00:00:00Z","Healthcheck":{"Status":"healthy"}}`
+
+	state, err = parseContainerState(podman)
+	assert.NoError(t, err)
+	assert.True(t, state.Running)
+	assert.Equal(t, 4242, state.Pid)
+	assert.Equal(t, "healthy", state.Health)
+	assert.True(t, state.FinishedAt.IsZero())
+
+	_, err = parseContainerState("<no value>")
+	assert.Error(t, err)
+}
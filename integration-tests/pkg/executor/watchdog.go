@@ -0,0 +1,49 @@
+package executor
+
+import (
+	"fmt"
+	"sync"
+)
+
+// RuntimeWatchdog tells apart failing commands from an unavailable container
+// runtime. After a number of consecutive failed executor calls, it pings the
+// runtime, so that a dead daemon is reported as such rather than as a
+// cascade of unrelated errors.
+type RuntimeWatchdog struct {
+	executor  Executor
+	threshold int
+
+	mutex    sync.Mutex
+	failures int
+}
+
+func NewRuntimeWatchdog(e Executor, threshold int) *RuntimeWatchdog {
+	return &RuntimeWatchdog{
+		executor:  e,
+		threshold: threshold,
+	}
+}
+
+// Observe records the result of an executor call. Once the threshold of
+// consecutive failures is reached, it returns an error if the runtime
+// doesn't respond to a ping.
+func (w *RuntimeWatchdog) Observe(err error) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if err == nil {
+		w.failures = 0
+		return nil
+	}
+
+	w.failures++
+	if w.failures < w.threshold {
+		return nil
+	}
+	w.failures = 0
+
+	if pingErr := w.executor.PingRuntime(); pingErr != nil {
+		return fmt.Errorf("container runtime unavailable: %w (last executor error: %s)", pingErr, err)
+	}
+	return nil
+}
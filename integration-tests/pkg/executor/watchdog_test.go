@@ -0,0 +1,45 @@
+package executor
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakePingExecutor struct {
+	Executor
+	pings   int
+	pingErr error
+}
+
+func (f *fakePingExecutor) PingRuntime() error {
+	f.pings++
+	return f.pingErr
+}
+
+func TestRuntimeWatchdog(t *testing.T) {
+	fake := &fakePingExecutor{}
+	watchdog := NewRuntimeWatchdog(fake, 3)
+	failure := errors.New("exit status 1")
+
+	// successes reset the count of consecutive failures
+	assert.NoError(t, watchdog.Observe(failure))
+	assert.NoError(t, watchdog.Observe(failure))
+	assert.NoError(t, watchdog.Observe(nil))
+	assert.NoError(t, watchdog.Observe(failure))
+	assert.NoError(t, watchdog.Observe(failure))
+	assert.Equal(t, 0, fake.pings)
+
+	// the runtime responds, so the failures are the commands' own
+	assert.NoError(t, watchdog.Observe(failure))
+	assert.Equal(t, 1, fake.pings)
+
+	fake.pingErr = errors.New("Cannot connect to the Docker daemon")
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, watchdog.Observe(failure))
+	}
+	err := watchdog.Observe(failure)
+	assert.ErrorContains(t, err, "container runtime unavailable")
+	assert.ErrorContains(t, err, "Cannot connect to the Docker daemon")
+}
@@ -0,0 +1,83 @@
+// Package fixtures provides the workload containers shared by many suites,
+// as configurations to be started with executor.StartContainer. Each may be
+// adjusted (e.g. its network or DNS) before it is started. Every fixture
+// documents the signals collector is expected to report for it, so that
+// assertions can reference them.
+package fixtures
+
+import (
+	"fmt"
+
+	"github.com/stackrox/collector/integration-tests/pkg/config"
+	"github.com/stackrox/collector/integration-tests/pkg/executor"
+)
+
+// NginxPort is the port NginxServer listens on.
+const NginxPort = 80
+
+// images is the store the fixture images are looked up in, which tests
+// can replace.
+var images = config.Images
+
+// idleCommand keeps a container running for the duration of a suite,
+// while commands are exec'd in it. It is a new slice for every fixture, so
+// that adjusting one does not affect the others.
+func idleCommand() []string {
+	return []string{"/bin/sleep 300"}
+}
+
+// NginxServer is an HTTP server listening on NginxPort. It is reported
+// with a process for nginx, a listening endpoint on NginxPort and a
+// ROLE_SERVER connection with local address ":80" for each client.
+func NginxServer(name string) executor.ContainerStartConfig {
+	return executor.ContainerStartConfig{
+		Name:  name,
+		Image: images().ImageByKey("nginx"),
+	}
+}
+
+// SocatServer is a TCP server on the given port, which accepts any number
+// of connections and discards what it receives. It is reported with a
+// process for socat, a listening endpoint on the port and a ROLE_SERVER
+// connection with local address ":<port>" for each client.
+func SocatServer(name string, port int) executor.ContainerStartConfig {
+	return executor.ContainerStartConfig{
+		Name:       name,
+		Image:      images().QaImageByKey("qa-socat"),
+		Entrypoint: []string{"socat"},
+		Command:    []string{fmt.Sprintf("TCP4-LISTEN:%d,reuseaddr,fork", port), "-"},
+	}
+}
+
+// SocatClient is an idle container with socat, to connect to servers with
+// commands such as "echo hello | socat - TCP4:<ip>:<port>". Nothing is
+// reported for it until then, after which it is reported with a process for
+// each command, and a ROLE_CLIENT connection with remote address
+// "<ip>:<port>" for each connection.
+func SocatClient(name string) executor.ContainerStartConfig {
+	return executor.ContainerStartConfig{
+		Name:       name,
+		Image:      images().QaImageByKey("qa-socat"),
+		Entrypoint: []string{"/bin/sh", "-c"},
+		Command:    idleCommand(),
+	}
+}
+
+// HTTPClient is an idle container with curl, to send requests with the
+// HTTPRequest command. Nothing is reported for it until then, after which
+// it is reported with a process for curl (/usr/bin/curl), and a ROLE_CLIENT
+// connection with remote address "<ip>:<port>" of the target.
+func HTTPClient(name string) executor.ContainerStartConfig {
+	return executor.ContainerStartConfig{
+		Name:       name,
+		Image:      images().QaImageByKey("qa-alpine-curl"),
+		Entrypoint: []string{"/bin/sh", "-c"},
+		Command:    idleCommand(),
+	}
+}
+
+// HTTPRequest is the command to send a request to the target from an
+// HTTPClient, which fails if the request does not succeed.
+func HTTPRequest(target string) []string {
+	return []string{"curl", "-s", "--fail", target}
+}
@@ -0,0 +1,66 @@
+package fixtures
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stackrox/collector/integration-tests/pkg/config"
+	"github.com/stackrox/collector/integration-tests/pkg/executor"
+)
+
+func withTestImages(t *testing.T) *config.ImageStore {
+	store := &config.ImageStore{
+		NonQa: map[string]string{"nginx": "nginx:1.25"},
+		Qa: map[string]string{
+			"qa-socat":       "quay.io/rhacs-eng/qa-multi-arch:socat",
+			"qa-alpine-curl": "quay.io/rhacs-eng/qa-multi-arch:alpine-curl",
+		},
+	}
+
+	images = func() *config.ImageStore { return store }
+	t.Cleanup(func() { images = config.Images })
+	return store
+}
+
+func TestFixtures(t *testing.T) {
+	store := withTestImages(t)
+
+	assert.Equal(t, executor.ContainerStartConfig{
+		Name:  "server",
+		Image: store.ImageByKey("nginx"),
+	}, NginxServer("server"))
+
+	assert.Equal(t, executor.ContainerStartConfig{
+		Name:       "server",
+		Image:      store.QaImageByKey("qa-socat"),
+		Entrypoint: []string{"socat"},
+		Command:    []string{"TCP4-LISTEN:8080,reuseaddr,fork", "-"},
+	}, SocatServer("server", 8080))
+
+	assert.Equal(t, executor.ContainerStartConfig{
+		Name:       "client",
+		Image:      store.QaImageByKey("qa-socat"),
+		Entrypoint: []string{"/bin/sh", "-c"},
+		Command:    []string{"/bin/sleep 300"},
+	}, SocatClient("client"))
+
+	assert.Equal(t, executor.ContainerStartConfig{
+		Name:       "client",
+		Image:      store.QaImageByKey("qa-alpine-curl"),
+		Entrypoint: []string{"/bin/sh", "-c"},
+		Command:    []string{"/bin/sleep 300"},
+	}, HTTPClient("client"))
+
+	assert.Equal(t, []string{"curl", "-s", "--fail", "http://10.0.0.1/"}, HTTPRequest("http://10.0.0.1/"))
+}
+
+func TestFixturesAreIndependent(t *testing.T) {
+	withTestImages(t)
+
+	adjusted := SocatClient("adjusted")
+	adjusted.Command[0] = "/bin/sleep 10"
+
+	assert.Equal(t, []string{"/bin/sleep 300"}, SocatClient("other").Command)
+	assert.Equal(t, []string{"/bin/sleep 300"}, HTTPClient("other").Command)
+}
@@ -0,0 +1,101 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/stackrox/collector/integration-tests/pkg/config"
+)
+
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Logger writes harness log lines for a single component (e.g. executor),
+// either as human-readable text or as JSON objects, one per line, depending
+// on the configured LOG_FORMAT.
+type Logger struct {
+	component string
+}
+
+func New(component string) *Logger {
+	return &Logger{component: component}
+}
+
+// Debug, Info and Error log a message along with optional fields,
+// provided as alternating keys and values.
+func (l *Logger) Debug(msg string, fields ...any) {
+	l.log("debug", msg, fields)
+}
+
+func (l *Logger) Info(msg string, fields ...any) {
+	l.log("info", msg, fields)
+}
+
+func (l *Logger) Error(msg string, fields ...any) {
+	l.log("error", msg, fields)
+}
+
+func (l *Logger) log(level string, msg string, fields []any) {
+	if config.LogFormat() == FormatJSON {
+		fmt.Println(formatJSON(time.Now(), level, l.component, msg, fields))
+	} else {
+		fmt.Println(formatText(level, l.component, msg, fields))
+	}
+}
+
+func formatText(level string, component string, msg string, fields []any) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "[%s] ", component)
+	if level == "error" {
+		sb.WriteString("Error: ")
+	}
+	sb.WriteString(msg)
+
+	for i := 0; i < len(fields); i += 2 {
+		fmt.Fprintf(&sb, " %v=%v", fields[i], fieldValue(fields, i+1))
+	}
+	return sb.String()
+}
+
+func formatJSON(ts time.Time, level string, component string, msg string, fields []any) string {
+	line := map[string]any{
+		"time":      ts.Format(time.RFC3339Nano),
+		"level":     level,
+		"component": component,
+		"msg":       msg,
+	}
+
+	if len(fields) > 0 {
+		values := map[string]any{}
+		for i := 0; i < len(fields); i += 2 {
+			value := fieldValue(fields, i+1)
+			if err, ok := value.(error); ok {
+				// errors don't marshal to anything useful
+				value = err.Error()
+			}
+			values[fmt.Sprint(fields[i])] = value
+		}
+		line["fields"] = values
+	}
+
+	out, err := json.Marshal(line)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to marshal log line: %s\n", err)
+		return formatText(level, component, msg, fields)
+	}
+	return string(out)
+}
+
+// fieldValue returns the value at idx, tolerating a missing value for the
+// last key.
+func fieldValue(fields []any, idx int) any {
+	if idx < len(fields) {
+		return fields[idx]
+	}
+	return "<missing>"
+}
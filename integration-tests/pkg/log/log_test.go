@@ -0,0 +1,28 @@
+package log
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatText(t *testing.T) {
+	assert.Equal(t, "[executor] Run cmd=docker ps",
+		formatText("debug", "executor", "Run", []any{"cmd", "docker ps"}))
+	assert.Equal(t, "[executor] Error: failed attempt=2 extra=<missing>",
+		formatText("error", "executor", "failed", []any{"attempt", 2, "extra"}))
+}
+
+func TestFormatJSON(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	assert.JSONEq(t,
+		`{"time":"2024-01-02T03:04:05Z","level":"info","component":"k8s","msg":"hello"}`,
+		formatJSON(ts, "info", "k8s", "hello", nil))
+
+	assert.JSONEq(t,
+		`{"time":"2024-01-02T03:04:05Z","level":"error","component":"k8s","msg":"failed","fields":{"err":"boom","attempt":1}}`,
+		formatJSON(ts, "error", "k8s", "failed", []any{"err", errors.New("boom"), "attempt", 1}))
+}
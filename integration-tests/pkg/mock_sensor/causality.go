@@ -0,0 +1,79 @@
+package mock_sensor
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stackrox/collector/integration-tests/pkg/types"
+)
+
+// causalityTolerance is how much later than a network signal the signal of
+// its process may arrive. Processes and network signals are sent on
+// separate streams, so their order of arrival is only meaningful up to
+// some delay.
+const causalityTolerance = time.Second
+
+// AssertSignalCausality asserts that the processes of a given container ID
+// were reported no later than the network signals they own, within
+// causalityTolerance: for each endpoint, a process matching its originator,
+// and for each connection, which does not identify its process, any
+// process of the container. This only holds for workloads started after
+// collector, whose processes were all reported.
+func (m *MockSensor) AssertSignalCausality(t *testing.T, containerID string) bool {
+	m.processMutex.Lock()
+	instances := make([]processInstance, len(m.processInstances[containerID]))
+	copy(instances, m.processInstances[containerID])
+	m.processMutex.Unlock()
+
+	violations := causalityViolations(instances, m.ConnectionEvents(containerID), m.EndpointEvents(containerID), causalityTolerance)
+	return assert.Empty(t, violations, "network signals reported before their process")
+}
+
+// causalityViolations describes each connection or endpoint event which
+// arrived more than the tolerance before the process it belongs to, or for
+// which no such process was reported at all.
+func causalityViolations(instances []processInstance, connections []ConnectionEvent, endpoints []EndpointEvent, tolerance time.Duration) []string {
+	violations := []string{}
+
+	firstReceived := func(matches func(types.ProcessInfo) bool) (time.Time, bool) {
+		var first time.Time
+		found := false
+		for _, instance := range instances {
+			if matches(instance.process) && (!found || instance.received.Before(first)) {
+				first, found = instance.received, true
+			}
+		}
+		return first, found
+	}
+
+	check := func(kind string, signal any, received time.Time, matches func(types.ProcessInfo) bool) {
+		processReceived, found := firstReceived(matches)
+		switch {
+		case !found:
+			violations = append(violations, fmt.Sprintf("%s without a process: %+v", kind, signal))
+		case processReceived.Sub(received) > tolerance:
+			violations = append(violations, fmt.Sprintf("%s reported %s before its process: %+v",
+				kind, processReceived.Sub(received), signal))
+		}
+	}
+
+	for _, event := range connections {
+		check("connection", event.Connection, event.Received, func(types.ProcessInfo) bool {
+			return true
+		})
+	}
+
+	for _, event := range endpoints {
+		originator := event.Endpoint.Originator
+		check("endpoint", event.Endpoint, event.Received, func(process types.ProcessInfo) bool {
+			return process.Name == originator.ProcessName &&
+				process.ExePath == originator.ProcessExecFilePath &&
+				process.Args == originator.ProcessArgs
+		})
+	}
+
+	return violations
+}
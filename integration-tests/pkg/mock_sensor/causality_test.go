@@ -0,0 +1,38 @@
+package mock_sensor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stackrox/collector/integration-tests/pkg/types"
+)
+
+func TestCausalityViolations(t *testing.T) {
+	base := time.Now()
+	socat := types.ProcessInfo{Name: "socat", ExePath: "/usr/bin/socat", Args: "TCP-LISTEN:80 -"}
+	instances := []processInstance{
+		{process: socat, received: base.Add(2 * time.Second)},
+	}
+
+	listening := types.EndpointInfo{Originator: types.ProcessOriginator{
+		ProcessName: "socat", ProcessExecFilePath: "/usr/bin/socat", ProcessArgs: "TCP-LISTEN:80 -",
+	}}
+	unknown := types.EndpointInfo{Originator: types.ProcessOriginator{ProcessName: "nginx"}}
+	conn := types.NetworkInfo{RemoteAddress: "10.0.0.2:80"}
+
+	// within the tolerance
+	assert.Empty(t, causalityViolations(instances,
+		[]ConnectionEvent{{Connection: conn, Received: base.Add(1500 * time.Millisecond)}},
+		[]EndpointEvent{{Endpoint: listening, Received: base.Add(3 * time.Second)}},
+		time.Second))
+
+	violations := causalityViolations(instances,
+		[]ConnectionEvent{{Connection: conn, Received: base}},
+		[]EndpointEvent{{Endpoint: unknown, Received: base.Add(3 * time.Second)}},
+		time.Second)
+	assert.Len(t, violations, 2)
+	assert.Contains(t, violations[0], "connection reported 2s before its process")
+	assert.Contains(t, violations[1], "endpoint without a process")
+}
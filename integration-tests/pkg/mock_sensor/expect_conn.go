@@ -1,127 +1,423 @@
 package mock_sensor
 
 import (
+	"errors"
+	"fmt"
+	"net"
 	"testing"
 
 	"time"
 
 	"github.com/stretchr/testify/assert"
-	"github.com/thoas/go-funk"
 
 	"github.com/stackrox/collector/integration-tests/pkg/types"
 )
 
-// ExpectConnections waits up to the timeout for the gRPC server to receive
-// the list of expected Connections. It will first check to see if the connections
-// have been received already, and then monitor the live feed of connections
-// until timeout or until all the events have been received.
-func (s *MockSensor) ExpectConnections(t *testing.T, containerID string, timeout time.Duration, expected ...types.NetworkInfo) bool {
+// ConnectionMatcher selects connections by a subset of their fields, for
+// cases where an exact match of every field is impractical.
+type ConnectionMatcher func(types.NetworkInfo) bool
 
-	to_find := funk.Filter(expected, func(x types.NetworkInfo) bool {
-		return !s.HasConnection(containerID, x)
-	}).([]types.NetworkInfo)
+// EndpointMatcher selects endpoints by a subset of their fields.
+type EndpointMatcher func(types.EndpointInfo) bool
 
-	if len(to_find) == 0 {
-		return true
+// EqualConnection returns a ConnectionMatcher for an exact match
+// of the expected connection.
+func EqualConnection(expected types.NetworkInfo) ConnectionMatcher {
+	return func(conn types.NetworkInfo) bool {
+		return conn == expected
 	}
+}
 
-	timer := time.After(timeout)
+// LoopbackConnection matches connections with a loopback remote address,
+// e.g. between two processes of a container over 127.0.0.1.
+func LoopbackConnection(conn types.NetworkInfo) bool {
+	ip := net.ParseIP(remoteIP(conn.RemoteAddress))
+	return ip != nil && ip.IsLoopback()
+}
 
-loop:
-	for {
-		select {
-		case <-timer:
-			// we know they don't match at this point, but by using
-			// ElementsMatch we get much better logging about the differences
-			return assert.ElementsMatch(t, expected, s.Connections(containerID), "timed out waiting for networks")
-		case network := <-s.LiveConnections():
-			if network.GetContainerId() != containerID {
-				continue loop
-			}
+// ExpectConnectionMatch waits up to the timeout for the gRPC server to receive
+// a connection that satisfies the matcher. It will first check to see if such
+// a connection has been received already, and then keep polling for
+// connections until timeout or until a matching one has been received.
+func (s *MockSensor) ExpectConnectionMatch(t *testing.T, containerID string, timeout time.Duration, matcher ConnectionMatcher) bool {
+	err := pollUntil(timeout, func() (bool, error) {
+		return s.HasConnectionMatch(containerID, matcher), nil
+	})
+
+	if err != nil {
+		return assert.Fail(t, "timed out waiting for a matching connection",
+			"connections: %+v", s.Connections(containerID))
+	}
+	return true
+}
+
+// ExpectNoConnectionMatch asserts that no connection satisfying the matcher
+// is reported for the given container over the window. It fails immediately
+// if a matching connection has been received already.
+func (s *MockSensor) ExpectNoConnectionMatch(t *testing.T, containerID string, window time.Duration, matcher ConnectionMatcher) bool {
+	err := pollUntil(window, func() (bool, error) {
+		if s.HasConnectionMatch(containerID, matcher) {
+			return false, errors.New("unexpected connection reported")
+		}
+		return false, nil
+	})
+
+	if err != errPollTimeout {
+		return assert.Fail(t, "unexpected matching connection reported", "connections: %+v", s.Connections(containerID))
+	}
+	return true
+}
+
+// ExpectConnectionOrdering waits up to the timeout for the gRPC server to
+// receive connections satisfying each matcher of the sequence, in that order
+// of arrival. Other connections may be interleaved with the sequence.
+// On timeout, the observed sequence of connections is reported.
+func (s *MockSensor) ExpectConnectionOrdering(t *testing.T, containerID string, timeout time.Duration, sequence []ConnectionMatcher) bool {
+	err := pollUntil(timeout, func() (bool, error) {
+		return matchesSequence(s.ConnectionEvents(containerID), sequence), nil
+	})
+
+	if err != nil {
+		return assert.Fail(t, "timed out waiting for the expected connection ordering",
+			"observed: %+v", s.ConnectionEvents(containerID))
+	}
+	return true
+}
+
+// matchesSequence returns whether the events contain, in order, a connection
+// satisfying each of the matchers in the sequence.
+func matchesSequence(events []ConnectionEvent, sequence []ConnectionMatcher) bool {
+	next := 0
+	for _, event := range events {
+		if next == len(sequence) {
+			break
+		}
+
+		if sequence[next](event.Connection) {
+			next++
+		}
+	}
+	return next == len(sequence)
+}
 
-			to_find = funk.Filter(expected, func(x types.NetworkInfo) bool {
-				return !s.HasConnection(containerID, x)
-			}).([]types.NetworkInfo)
+// ExpectUDPConnection waits up to the timeout for the gRPC server to receive
+// a UDP flow between the two containers, given their IP addresses. Since UDP
+// is connectionless, there is no handshake to attribute roles from: collector
+// records the flow from the datagram syscalls (e.g. sendto and recvfrom) on
+// each side. The flow is matched when the client reports a UDP connection in
+// the client role to the server's address and a port, on which the server
+// reports a UDP connection in the server role from the client's address.
+func (s *MockSensor) ExpectUDPConnection(t *testing.T, clientContainer, clientIP, serverContainer, serverIP string, timeout time.Duration) bool {
+	err := pollUntil(timeout, func() (bool, error) {
+		return hasUDPFlow(s.UDPConnections(clientContainer), clientIP, s.UDPConnections(serverContainer), serverIP), nil
+	})
 
-			if len(to_find) == 0 {
+	if err != nil {
+		return assert.Fail(t, "timed out waiting for a UDP connection",
+			"client connections: %+v, server connections: %+v",
+			s.Connections(clientContainer), s.Connections(serverContainer))
+	}
+	return true
+}
+
+// hasUDPFlow returns whether one of the client connections targets the
+// server's IP and the port of one of the server connections, which comes
+// from the client's IP. From the server's side, the remote address only has
+// the client's IP, since the client's port is ephemeral.
+func hasUDPFlow(client []types.NetworkInfo, clientIP string, server []types.NetworkInfo, serverIP string) bool {
+	for _, c := range client {
+		if c.Role != "ROLE_CLIENT" {
+			continue
+		}
+		remoteHost, remotePort, err := net.SplitHostPort(c.RemoteAddress)
+		if err != nil || remoteHost != serverIP {
+			continue
+		}
+
+		for _, s := range server {
+			if s.Role != "ROLE_SERVER" || s.RemoteAddress != clientIP {
+				continue
+			}
+			_, localPort, err := net.SplitHostPort(s.LocalAddress)
+			if err == nil && localPort == remotePort {
 				return true
 			}
 		}
 	}
+	return false
+}
+
+// ExpectConnections waits up to the timeout for the gRPC server to receive
+// the list of expected Connections. It will first check to see if the connections
+// have been received already, and then keep polling for connections
+// until timeout or until all the events have been received.
+func (s *MockSensor) ExpectConnections(t *testing.T, containerID string, timeout time.Duration, expected ...types.NetworkInfo) bool {
+	err := pollUntil(timeout, func() (bool, error) {
+		for _, conn := range expected {
+			if !s.HasConnection(containerID, conn) {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+
+	if err != nil {
+		// we know they don't match at this point, but by using
+		// ElementsMatch we get much better logging about the differences
+		return assert.ElementsMatch(t, expected, s.Connections(containerID), "timed out waiting for networks")
+	}
+	return true
 }
 
 // ExpectConnectionsN waits up to the timeout for the gRPC server to receive
 // the a set number of connections. It will first check to see if the connections
-// have been received already, and then monitor the live feed of connections
+// have been received already, and then keep polling for connections
 // until timeout or until all the events have been received.
 //
 // It does not consider the content of the events, just that a certain number
 // have been received
 func (s *MockSensor) ExpectConnectionsN(t *testing.T, containerID string, timeout time.Duration, n int) []types.NetworkInfo {
-	if len(s.Connections(containerID)) == n {
-		return s.Connections(containerID)
-	}
-
-	timer := time.After(timeout)
-loop:
-	for {
-		select {
-		case <-timer:
-			assert.FailNowf(t, "timed out", "found %d connections (expected %d)", len(s.Connections(containerID)), n)
-		case conn := <-s.LiveConnections():
-			if conn.GetContainerId() != containerID {
-				continue loop
+	err := pollUntil(timeout, func() (bool, error) {
+		return len(s.Connections(containerID)) == n, nil
+	})
+
+	if err != nil {
+		assert.FailNowf(t, "timed out", "found %d connections (expected %d)", len(s.Connections(containerID)), n)
+	}
+	return s.Connections(containerID)
+}
+
+// WaitConnectionsMatchingN waits up to the timeout for at least n of the
+// connections received for the container to match, e.g. for connections to
+// be reported closed.
+func (s *MockSensor) WaitConnectionsMatchingN(containerID string, timeout time.Duration, n int, matcher ConnectionMatcher) bool {
+	err := pollUntil(timeout, func() (bool, error) {
+		count := 0
+		for _, conn := range s.Connections(containerID) {
+			if matcher(conn) {
+				count++
 			}
+		}
+		return count >= n, nil
+	})
+	return err == nil
+}
+
+// ExpectConnectionsEventuallyExactly waits up to the timeout for the number
+// of connections received to reach exactly the expected count, and then to
+// stay there for stableFor, which catches connections reported late as well
+// as duplicates. It fails as soon as the count goes over the expected one.
+func (s *MockSensor) ExpectConnectionsEventuallyExactly(t *testing.T, containerID string, exact int, stableFor time.Duration, timeout time.Duration) bool {
+	var stableSince time.Time
+	err := pollUntil(timeout, func() (bool, error) {
+		n := len(s.Connections(containerID))
+		if n > exact {
+			return false, fmt.Errorf("found %d connections (expected %d)", n, exact)
+		}
+		if n < exact {
+			stableSince = time.Time{}
+			return false, nil
+		}
+
+		if stableSince.IsZero() {
+			stableSince = time.Now()
+		}
+		return time.Since(stableSince) >= stableFor, nil
+	})
+
+	switch {
+	case err == errPollTimeout:
+		return assert.Fail(t, "timed out waiting for the connection count to settle",
+			"found %d connections (expected %d, stable for %s): %+v",
+			len(s.Connections(containerID)), exact, stableFor, s.Connections(containerID))
+	case err != nil:
+		return assert.Fail(t, "too many connections", "%s: %+v", err, s.Connections(containerID))
+	}
+	return true
+}
 
-			if len(s.Connections(containerID)) == n {
-				return s.Connections(containerID)
+// ExpectConnectionsAcrossContainers waits up to the timeout for at least
+// perContainer distinct connections to be reported for each of the given
+// containers, e.g. replicas of the same workload. Reports of the same
+// connection being opened and closed count once.
+func (s *MockSensor) ExpectConnectionsAcrossContainers(t *testing.T, ids []string, perContainer int, timeout time.Duration) bool {
+	missing := map[string]int{}
+	err := pollUntil(timeout, func() (bool, error) {
+		missing = map[string]int{}
+		for _, id := range ids {
+			if n := countDistinctConnections(s.Connections(id)); n < perContainer {
+				missing[id] = n
 			}
 		}
+		return len(missing) == 0, nil
+	})
+
+	if err != nil {
+		return assert.Fail(t, "timed out waiting for connections across containers",
+			"%d of %d containers have fewer than %d connections (container: count): %v",
+			len(missing), len(ids), perContainer, missing)
+	}
+	return true
+}
+
+// countDistinctConnections counts connections, regardless of their close
+// timestamp.
+func countDistinctConnections(connections []types.NetworkInfo) int {
+	distinct := map[types.NetworkInfo]bool{}
+	for _, conn := range connections {
+		conn.CloseTimestamp = ""
+		distinct[conn] = true
 	}
+	return len(distinct)
 }
 
 // ExpectEndpoints waits up to the timeout for the gRPC server to receive
 // the list of expected Endpoints. It will first check to see if the endpoints
-// have been received already, and then monitor the live feed of endpoints
+// have been received already, and then keep polling for endpoints
 // until timeout or until all the events have been received.
 func (s *MockSensor) ExpectEndpoints(t *testing.T, containerID string, timeout time.Duration, expected ...types.EndpointInfo) bool {
+	err := pollUntil(timeout, func() (bool, error) {
+		for _, endpoint := range expected {
+			if !s.HasEndpoint(containerID, endpoint) {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
 
-	to_find := funk.Filter(expected, func(x types.EndpointInfo) bool {
-		return !s.HasEndpoint(containerID, x)
-	}).([]types.EndpointInfo)
-
-	if len(to_find) == 0 {
-		return true
+	if err != nil {
+		// we know they don't match at this point, but by using
+		// ElementsMatch we get much better logging about the differences
+		return assert.ElementsMatch(t, expected, s.Endpoints(containerID), "timed out waiting for endpoints")
 	}
+	return true
+}
 
-	timer := time.After(timeout)
-
-loop:
-	for {
-		select {
-		case <-timer:
-			// we know they don't match at this point, but by using
-			// ElementsMatch we get much better logging about the differences
-			return assert.ElementsMatch(t, expected, s.Endpoints(containerID), "timed out waiting for endpoints")
-		case network := <-s.LiveEndpoints():
-			if network.GetContainerId() != containerID {
-				continue loop
+// ExpectEndpointAfter waits up to the timeout for the gRPC server to receive
+// an endpoint that satisfies the matcher, reported after the given time.
+// Endpoints reported earlier are ignored, so this can be used to check that
+// an endpoint is observed afresh, e.g. after its container restarted.
+func (s *MockSensor) ExpectEndpointAfter(t *testing.T, containerID string, after time.Time, timeout time.Duration, matcher EndpointMatcher) bool {
+	err := pollUntil(timeout, func() (bool, error) {
+		for _, event := range s.EndpointEvents(containerID) {
+			if event.Received.After(after) && matcher(event.Endpoint) {
+				return true, nil
 			}
+		}
+		return false, nil
+	})
+
+	if err != nil {
+		return assert.Fail(t, "timed out waiting for a matching endpoint",
+			"reported after %s: %+v", after, s.EndpointEvents(containerID))
+	}
+	return true
+}
 
-			to_find = funk.Filter(expected, func(x types.EndpointInfo) bool {
-				return !s.HasEndpoint(containerID, x)
-			}).([]types.EndpointInfo)
+// ExpectNoEndpoints asserts that no endpoints are reported for the given
+// container over the window, e.g. for a container that is a pure client.
+// It fails immediately if any endpoint has been received already.
+func (s *MockSensor) ExpectNoEndpoints(t *testing.T, containerID string, window time.Duration) bool {
+	err := pollUntil(window, func() (bool, error) {
+		if len(s.Endpoints(containerID)) != 0 {
+			return false, errors.New("unexpected endpoints reported")
+		}
+		return false, nil
+	})
 
-			if len(to_find) == 0 {
-				return true
+	if err != errPollTimeout {
+		return assert.Fail(t, "unexpected endpoints reported", "endpoints: %+v", s.Endpoints(containerID))
+	}
+	return true
+}
+
+// ExpectEndpointReportedOnce asserts that a stable endpoint satisfying the
+// matcher is reported exactly once over the window, which should span
+// several scrape intervals. Unlike repeated opens of the same endpoint, a
+// stable endpoint must not be reported again by each scrape.
+func (s *MockSensor) ExpectEndpointReportedOnce(t *testing.T, containerID string, matcher EndpointMatcher, over time.Duration) bool {
+	reports := func() []EndpointEvent {
+		matching := []EndpointEvent{}
+		for _, event := range s.EndpointEvents(containerID) {
+			if matcher(event.Endpoint) {
+				matching = append(matching, event)
 			}
 		}
+		return matching
 	}
+
+	err := pollUntil(over, func() (bool, error) {
+		if len(reports()) > 1 {
+			return false, errors.New("endpoint reported more than once")
+		}
+		return false, nil
+	})
+
+	if err != errPollTimeout {
+		return assert.Fail(t, "endpoint reported more than once", "reports: %+v", reports())
+	}
+	return assert.Len(t, reports(), 1, "endpoint not reported exactly once")
+}
+
+// ExpectEndpointOriginator waits up to the timeout for the gRPC server to
+// receive an endpoint listening on the given port, and asserts that it is
+// attributed to the expected process, e.g. the binary that was finally
+// exec'd to listen, rather than the wrapper that started it.
+func (s *MockSensor) ExpectEndpointOriginator(t *testing.T, containerID string, port int, timeout time.Duration, expected types.ProcessOriginator) bool {
+	err := pollUntil(timeout, func() (bool, error) {
+		return len(s.EndpointsByPort(containerID)[port]) > 0, nil
+	})
+
+	if err != nil {
+		return assert.Fail(t, "timed out waiting for an endpoint", "port %d, endpoints: %+v", port, s.Endpoints(containerID))
+	}
+
+	originators := []types.ProcessOriginator{}
+	for _, endpoint := range s.EndpointsByPort(containerID)[port] {
+		originators = append(originators, endpoint.Originator)
+	}
+	return assert.Equal(t, []types.ProcessOriginator{expected}, originators, "unexpected originator for port %d", port)
+}
+
+// ExpectEndpointClosed waits up to the timeout for the lifecycle of the
+// endpoint on the given port to end in a close, for a port that is no longer
+// listened on. The lifecycle must be consistent (see checkEndpointLifecycle),
+// so that no phantom endpoint lingers after the rebinds of the port.
+func (s *MockSensor) ExpectEndpointClosed(t *testing.T, containerID string, port int, timeout time.Duration) bool {
+	err := pollUntil(timeout, func() (bool, error) {
+		lifecycle := s.EndpointLifecycle(containerID, port)
+		return len(lifecycle) > 0 && checkEndpointLifecycle(lifecycle) == nil, nil
+	})
+
+	if err != nil {
+		lifecycle := s.EndpointLifecycle(containerID, port)
+		return assert.Fail(t, "endpoint lifecycle did not end in a close",
+			"port %d: %v, lifecycle: %+v", port, checkEndpointLifecycle(lifecycle), lifecycle)
+	}
+	return true
+}
+
+// checkEndpointLifecycle checks that the reports of an endpoint form a
+// consistent sequence of opens and closes, ending closed: the first report
+// must be an open, and the last one a close. Repeated reports of the same
+// state are allowed, as scrapes may report an endpoint again.
+func checkEndpointLifecycle(lifecycle []EndpointEvent) error {
+	if len(lifecycle) == 0 {
+		return nil
+	}
+
+	if first := lifecycle[0].Endpoint; !first.IsActive() {
+		return fmt.Errorf("closed before being reported open: %+v", first)
+	}
+	if last := lifecycle[len(lifecycle)-1].Endpoint; last.IsActive() {
+		return fmt.Errorf("left open: %+v", last)
+	}
+	return nil
 }
 
 // ExpectEndpointsN waits up to the timeout for the gRPC server to receive
 // the a set number of endpoints. It will first check to see if the endpoints
-// have been received already, and then monitor the live feed of endpoints
+// have been received already, and then keep polling for endpoints
 // until timeout or until all the events have been received.
 //
 // It does not consider the content of the events, just that a certain number
@@ -141,29 +437,17 @@ func (s *MockSensor) WaitEndpointsN(t *testing.T, containerID string, timeout ti
 // waitEndpointsN is a helper function for waiting for a set number of endpoints.
 // the timeoutFn function can be used to control error behaviour on timeout.
 func (s *MockSensor) waitEndpointsN(t *testing.T, timeoutFn func(), containerID string, timeout time.Duration, n int) []types.EndpointInfo {
-	seen := len(s.Endpoints(containerID))
-
-	if seen == n {
-		return s.Endpoints(containerID)
-	} else if seen > n {
+	if seen := len(s.Endpoints(containerID)); seen > n {
 		assert.FailNow(t, "too many endpoints", "found %d endpoints (expected %d)", seen, n)
 	}
 
-	timer := time.After(timeout)
-loop:
-	for {
-		select {
-		case <-timer:
-			timeoutFn()
-			return make([]types.EndpointInfo, 0)
-		case ep := <-s.LiveEndpoints():
-			if ep.GetContainerId() != containerID {
-				continue loop
-			}
+	err := pollUntil(timeout, func() (bool, error) {
+		return len(s.Endpoints(containerID)) == n, nil
+	})
 
-			if len(s.Endpoints(containerID)) == n {
-				return s.Endpoints(containerID)
-			}
-		}
+	if err != nil {
+		timeoutFn()
+		return make([]types.EndpointInfo, 0)
 	}
+	return s.Endpoints(containerID)
 }
@@ -0,0 +1,81 @@
+package mock_sensor
+
+import (
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stackrox/collector/integration-tests/pkg/types"
+)
+
+func TestCheckEndpointLifecycle(t *testing.T) {
+	open := EndpointEvent{Endpoint: types.EndpointInfo{CloseTimestamp: types.NilTimestamp}}
+	closed := EndpointEvent{Endpoint: types.EndpointInfo{CloseTimestamp: "2024-01-01 00:00:00 +0000 UTC"}}
+
+	assert.NoError(t, checkEndpointLifecycle(nil))
+	assert.NoError(t, checkEndpointLifecycle([]EndpointEvent{open, closed}))
+	assert.NoError(t, checkEndpointLifecycle([]EndpointEvent{open, closed, open, open, closed, closed}))
+
+	assert.ErrorContains(t, checkEndpointLifecycle([]EndpointEvent{closed, open, closed}), "before being reported open")
+	assert.ErrorContains(t, checkEndpointLifecycle([]EndpointEvent{open, closed, open}), "left open")
+}
+
+func TestExpectConnectionsEventuallyExactly(t *testing.T) {
+	m := NewMockSensor("test")
+	m.logger = log.New(io.Discard, "", 0)
+
+	pushConnections(m, 1, 2)
+	assert.True(t, m.ExpectConnectionsEventuallyExactly(t, "abc", 2, 50*time.Millisecond, time.Second))
+
+	// not reached
+	assert.False(t, m.ExpectConnectionsEventuallyExactly(new(testing.T), "abc", 3, 0, 50*time.Millisecond))
+
+	// overshot, which fails without waiting for the timeout
+	pushConnections(m, 3)
+	start := time.Now()
+	assert.False(t, m.ExpectConnectionsEventuallyExactly(new(testing.T), "abc", 2, 0, 5*time.Second))
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestWaitConnectionsMatchingN(t *testing.T) {
+	m := NewMockSensor("test")
+	m.logger = log.New(io.Discard, "", 0)
+
+	highPort := func(conn types.NetworkInfo) bool {
+		return conn.RemotePort() >= 2
+	}
+
+	pushConnections(m, 1, 2)
+	assert.False(t, m.WaitConnectionsMatchingN("abc", 50*time.Millisecond, 2, highPort))
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		pushConnections(m, 3)
+	}()
+	assert.True(t, m.WaitConnectionsMatchingN("abc", time.Second, 2, highPort))
+}
+
+func TestLoopbackConnection(t *testing.T) {
+	assert.True(t, LoopbackConnection(types.NetworkInfo{RemoteAddress: "127.0.0.1:8080"}))
+	assert.True(t, LoopbackConnection(types.NetworkInfo{RemoteAddress: "127.0.0.53"}))
+	assert.True(t, LoopbackConnection(types.NetworkInfo{RemoteAddress: "[::1]:8080"}))
+	assert.False(t, LoopbackConnection(types.NetworkInfo{RemoteAddress: "172.17.0.2:8080"}))
+	assert.False(t, LoopbackConnection(types.NetworkInfo{RemoteAddress: ""}))
+}
+
+func TestHasUDPFlow(t *testing.T) {
+	client := []types.NetworkInfo{{RemoteAddress: "172.17.0.2:9090", Role: "ROLE_CLIENT"}}
+	server := []types.NetworkInfo{{LocalAddress: ":9090", RemoteAddress: "172.17.0.3", Role: "ROLE_SERVER"}}
+
+	assert.True(t, hasUDPFlow(client, "172.17.0.3", server, "172.17.0.2"))
+
+	// same port, but to or from another address
+	assert.False(t, hasUDPFlow(client, "172.17.0.3", server, "172.17.0.4"))
+	assert.False(t, hasUDPFlow(client, "172.17.0.4", server, "172.17.0.2"))
+
+	otherPort := []types.NetworkInfo{{LocalAddress: ":9091", RemoteAddress: "172.17.0.3", Role: "ROLE_SERVER"}}
+	assert.False(t, hasUDPFlow(client, "172.17.0.3", otherPort, "172.17.0.2"))
+}
@@ -1,16 +1,60 @@
 package mock_sensor
 
 import (
+	"path"
 	"testing"
 
 	"time"
 
 	"github.com/stretchr/testify/assert"
-	"github.com/thoas/go-funk"
 
 	"github.com/stackrox/collector/integration-tests/pkg/types"
 )
 
+// ProcessMatcher selects processes by a subset of their fields, e.g. to
+// find a process whose pid is not known in advance.
+type ProcessMatcher func(types.ProcessInfo) bool
+
+// ExpectProcessMatch waits up to the timeout for the gRPC server to receive
+// a process that satisfies the matcher, and returns it so that its other
+// fields can be asserted.
+func (s *MockSensor) ExpectProcessMatch(t *testing.T, containerID string, timeout time.Duration, matcher ProcessMatcher) (types.ProcessInfo, bool) {
+	var found types.ProcessInfo
+	err := pollUntil(timeout, func() (bool, error) {
+		for _, process := range s.Processes(containerID) {
+			if matcher(process) {
+				found = process
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+
+	if err != nil {
+		return found, assert.Fail(t, "timed out waiting for a matching process",
+			"processes: %+v", s.Processes(containerID))
+	}
+	return found, true
+}
+
+// ExpectProcessExePath waits up to the timeout for a process with the given
+// name, and asserts that its reported exe path is the expected one. The
+// expected path should be the canonical one, with symlinks resolved (e.g.
+// with readlink -f in the container), as collector reports the file that
+// was actually executed rather than the path it was executed by. Both
+// paths are cleaned before they are compared.
+func (s *MockSensor) ExpectProcessExePath(t *testing.T, containerID string, timeout time.Duration, name string, expectedPath string) (types.ProcessInfo, bool) {
+	process, ok := s.ExpectProcessMatch(t, containerID, timeout, func(process types.ProcessInfo) bool {
+		return process.Name == name
+	})
+	if !ok {
+		return process, false
+	}
+
+	return process, assert.Equal(t, path.Clean(expectedPath), path.Clean(process.ExePath),
+		"unexpected exe path of %s", name)
+}
+
 func (s *MockSensor) ExpectProcessesN(t *testing.T, containerID string, timeout time.Duration, n int) []types.ProcessInfo {
 	return s.waitProcessesN(func() {
 		assert.FailNowf(t, "timed out", "found %d processes (expected %d)", len(s.Processes(containerID)), n)
@@ -24,80 +68,35 @@ func (s *MockSensor) WaitProcessesN(containerID string, timeout time.Duration, n
 func (s *MockSensor) ExpectProcesses(
 	t *testing.T, containerID string, timeout time.Duration, expected ...types.ProcessInfo) bool {
 
-	// might have already seen some of the events
-	to_find := funk.Filter(expected, func(x types.ProcessInfo) bool {
-		return !s.HasProcess(containerID, x)
-	}).([]types.ProcessInfo)
-
-	if len(to_find) == 0 {
-		// seen them all
-		return true
-	}
-
-	timer := time.After(timeout)
-
-loop:
-	for {
-		select {
-		case <-timer:
-			return assert.ElementsMatch(t, expected, s.Processes(containerID), "Not all processes received")
-		case process := <-s.LiveProcesses():
-			if process.GetContainerId() != containerID {
-				continue loop
-			}
-
-			info := types.ProcessInfo{
-				Name:    process.GetName(),
-				ExePath: process.GetExecFilePath(),
-				// Pid:     int(process.GetPid()),
-				Uid:  int(process.GetUid()),
-				Gid:  int(process.GetGid()),
-				Args: process.GetArgs(),
-			}
-
-			to_find = funk.Filter(to_find, func(x types.ProcessInfo) bool {
-				return x != info
-			}).([]types.ProcessInfo)
-
-			if len(to_find) == 0 {
-				return true
+	err := pollUntil(timeout, func() (bool, error) {
+		for _, process := range expected {
+			if !s.HasProcess(containerID, process) {
+				return false, nil
 			}
 		}
+		return true, nil
+	})
+
+	if err != nil {
+		return assert.ElementsMatch(t, expected, s.Processes(containerID), "Not all processes received")
 	}
+	return true
 }
 
 func (s *MockSensor) ExpectLineages(t *testing.T, containerID string, timeout time.Duration, processName string, expected ...types.ProcessLineage) bool {
-	to_find := funk.Filter(expected, func(x types.ProcessLineage) bool {
-		return s.HasLineage(containerID, x)
-	}).([]types.ProcessLineage)
-
-	if len(to_find) == 0 {
-		return true
-	}
-
-	timer := time.After(timeout)
-
-	for {
-		select {
-		case <-timer:
-			return assert.ElementsMatch(t, expected, s.ProcessLineages(containerID), "Not all process lineages received")
-		case lineage := <-s.LiveLineages():
-			info := types.ProcessLineage{
-				Name:          processName,
-				ParentExePath: lineage.GetParentExecFilePath(),
-				ParentUid:     int(lineage.GetParentUid()),
-			}
-
-			to_find = funk.Filter(to_find, func(x types.ProcessLineage) bool {
-				return s.HasLineage(containerID, info)
-			}).([]types.ProcessLineage)
-
-			if len(to_find) == 0 {
-				return true
+	err := pollUntil(timeout, func() (bool, error) {
+		for _, lineage := range expected {
+			if !s.HasLineage(containerID, lineage) {
+				return false, nil
 			}
 		}
-	}
+		return true, nil
+	})
 
+	if err != nil {
+		return assert.ElementsMatch(t, expected, s.ProcessLineages(containerID), "Not all process lineages received")
+	}
+	return true
 }
 
 // Wait for expected number of processes to show up in a specified container.
@@ -105,7 +104,7 @@ func (s *MockSensor) ExpectLineages(t *testing.T, containerID string, timeout ti
 //   - containerID: the target container for searching processes
 //   - timeout: maximum waiting time
 //   - n: expected number of processes
-//   - tickSeconds: how often to wake up within the timeout time, default is 1s
+//   - tickSeconds: how often to call tickFn within the timeout time, default is 1s
 //   - tickFn: what to do when ticking, could be used to trigger expected number
 //     of processes
 func (s *MockSensor) waitProcessesN(
@@ -116,33 +115,26 @@ func (s *MockSensor) waitProcessesN(
 	tickSeconds time.Duration,
 	tickFn func()) []types.ProcessInfo {
 
-	if len(s.Processes(containerID)) >= n {
-		return s.Processes(containerID)
-	}
-
 	if tickSeconds == 0 {
 		tickSeconds = 1 * time.Second
 	}
 
-	tick := time.Tick(tickSeconds)
-	timer := time.After(timeout)
+	lastTick := time.Now()
+	err := pollUntil(timeout, func() (bool, error) {
+		if len(s.Processes(containerID)) >= n {
+			return true, nil
+		}
 
-loop:
-	for {
-		select {
-		case <-tick:
+		if time.Since(lastTick) >= tickSeconds {
 			tickFn()
-		case <-timer:
-			timeoutFn()
-			return make([]types.ProcessInfo, 0)
-		case proc := <-s.LiveProcesses():
-			if proc.GetContainerId() != containerID {
-				continue loop
-			}
-
-			if len(s.Processes(containerID)) >= n {
-				return s.Processes(containerID)
-			}
+			lastTick = time.Now()
 		}
+		return false, nil
+	})
+
+	if err != nil {
+		timeoutFn()
+		return make([]types.ProcessInfo, 0)
 	}
+	return s.Processes(containerID)
 }
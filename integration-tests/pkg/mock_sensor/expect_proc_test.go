@@ -0,0 +1,74 @@
+package mock_sensor
+
+import (
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stackrox/rox/generated/storage"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/stackrox/collector/integration-tests/pkg/types"
+)
+
+func TestExpectProcessExePath(t *testing.T) {
+	m := NewMockSensor("test")
+	m.logger = log.New(io.Discard, "", 0)
+
+	m.pushProcess("abc", &storage.ProcessSignal{ContainerId: "abc", Name: "plop", ExecFilePath: "/process-listening-on-ports"})
+
+	_, ok := m.ExpectProcessExePath(t, "abc", time.Second, "plop", "/./process-listening-on-ports")
+	assert.True(t, ok)
+
+	// the path it was executed by is not the one reported
+	_, ok = m.ExpectProcessExePath(new(testing.T), "abc", time.Second, "plop", "/plop")
+	assert.False(t, ok)
+
+	_, ok = m.ExpectProcessExePath(new(testing.T), "abc", 50*time.Millisecond, "other", "/process-listening-on-ports")
+	assert.False(t, ok)
+}
+
+func TestProcessStartTimes(t *testing.T) {
+	m := NewMockSensor("test")
+	m.logger = log.New(io.Discard, "", 0)
+
+	first := time.Unix(1000, 0)
+	second := time.Unix(2000, 0)
+	m.pushProcess("abc", &storage.ProcessSignal{ContainerId: "abc", Name: "uname", Pid: 42, Time: timestamppb.New(first)})
+	m.pushProcess("abc", &storage.ProcessSignal{ContainerId: "abc", Name: "true", Pid: 43, Time: timestamppb.New(first)})
+	m.pushProcess("abc", &storage.ProcessSignal{ContainerId: "abc", Name: "true", Pid: 42, Time: timestamppb.New(second)})
+
+	starts := m.ProcessStartTimes("abc", 42)
+	assert.Len(t, starts, 2)
+	assert.True(t, first.Equal(starts[0]))
+	assert.True(t, second.Equal(starts[1]))
+
+	assert.Empty(t, m.ProcessStartTimes("abc", 44))
+	assert.Empty(t, m.ProcessStartTimes("def", 42))
+}
+
+func TestExpectLineages(t *testing.T) {
+	m := NewMockSensor("test")
+	m.logger = log.New(io.Discard, "", 0)
+
+	lineage := types.ProcessLineage{Name: "ls", ParentExePath: "/bin/sh", ParentUid: 0}
+	other := types.ProcessLineage{Name: "ls", ParentExePath: "/bin/bash", ParentUid: 0}
+
+	// lineages reported before the call are found, without waiting for
+	// further ones
+	m.pushLineage("abc", &storage.ProcessSignal{ContainerId: "abc", Name: "ls"},
+		&storage.ProcessSignal_LineageInfo{ParentExecFilePath: "/bin/sh"})
+	assert.True(t, m.ExpectLineages(t, "abc", time.Second, "ls", lineage))
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		m.pushLineage("abc", &storage.ProcessSignal{ContainerId: "abc", Name: "ls"},
+			&storage.ProcessSignal_LineageInfo{ParentExecFilePath: "/bin/bash"})
+	}()
+	assert.True(t, m.ExpectLineages(t, "abc", time.Second, "ls", lineage, other))
+
+	missing := types.ProcessLineage{Name: "ls", ParentExePath: "/bin/zsh", ParentUid: 0}
+	assert.False(t, m.ExpectLineages(new(testing.T), "abc", 50*time.Millisecond, "ls", lineage, missing))
+}
@@ -0,0 +1,86 @@
+package mock_sensor
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// faults holds the fault injection settings of the MockSensor, used to
+// simulate a slow or misbehaving Sensor.
+type faults struct {
+	mutex sync.Mutex
+
+	latency     time.Duration
+	errorRate   float64
+	rejectAfter int
+	received    int
+}
+
+// InjectLatency delays the handling of every received message by the
+// given duration, simulating a slow Sensor. A zero duration disables it.
+func (m *MockSensor) InjectLatency(d time.Duration) {
+	m.faults.mutex.Lock()
+	defer m.faults.mutex.Unlock()
+
+	m.faults.latency = d
+}
+
+// InjectError makes the given fraction (between 0 and 1) of received
+// messages fail with a transient gRPC error, which terminates the stream.
+// A rate of zero disables it.
+func (m *MockSensor) InjectError(rate float64) {
+	m.faults.mutex.Lock()
+	defer m.faults.mutex.Unlock()
+
+	m.faults.errorRate = rate
+}
+
+// RejectAfter makes every message after the first n received ones fail
+// with a gRPC error. A negative value disables it.
+func (m *MockSensor) RejectAfter(n int) {
+	m.faults.mutex.Lock()
+	defer m.faults.mutex.Unlock()
+
+	m.faults.rejectAfter = n
+	m.faults.received = 0
+}
+
+// ClearFaults disables all fault injection.
+func (m *MockSensor) ClearFaults() {
+	m.faults.mutex.Lock()
+	defer m.faults.mutex.Unlock()
+
+	m.faults.latency = 0
+	m.faults.errorRate = 0
+	m.faults.rejectAfter = -1
+	m.faults.received = 0
+}
+
+// injectFault applies the configured faults to a received message, and
+// returns the error to terminate the stream with, if any.
+func (m *MockSensor) injectFault() error {
+	m.faults.mutex.Lock()
+	latency := m.faults.latency
+	errorRate := m.faults.errorRate
+	m.faults.received++
+	rejected := m.faults.rejectAfter >= 0 && m.faults.received > m.faults.rejectAfter
+	m.faults.mutex.Unlock()
+
+	if latency > 0 {
+		time.Sleep(latency)
+	}
+
+	if rejected {
+		return status.Error(codes.ResourceExhausted, "mock sensor: rejecting messages")
+	}
+
+	if errorRate > 0 && rand.Float64() < errorRate {
+		return status.Error(codes.Unavailable, "mock sensor: injected error")
+	}
+
+	return nil
+}
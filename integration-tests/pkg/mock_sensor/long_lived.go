@@ -0,0 +1,63 @@
+package mock_sensor
+
+import (
+	"time"
+
+	"github.com/stackrox/collector/integration-tests/pkg/types"
+)
+
+// ProcessAliveCheck returns whether the process with the given host PID is
+// still running.
+type ProcessAliveCheck func(pid int) bool
+
+// processInstance is a single reported execution of a process.
+type processInstance struct {
+	process types.ProcessInfo
+	pid     int
+	start   time.Time
+	// received is when the process signal arrived
+	received time.Time
+}
+
+// SetProcessAliveCheck sets how to tell whether a reported process is still
+// running, which the sensor cannot tell from the signals alone. Without it,
+// every process is considered to be running.
+func (m *MockSensor) SetProcessAliveCheck(check ProcessAliveCheck) {
+	m.processMutex.Lock()
+	defer m.processMutex.Unlock()
+
+	m.processAlive = check
+}
+
+// DistinctLongLivedProcesses returns the distinct processes of a given
+// container ID which are still running, and have been for at least
+// minLifetime. This leaves out incidental short-lived processes, e.g. the
+// helpers of the base image or of exec'd commands, so that the count of
+// workload processes is stable. The list is sorted (see
+// types.SortProcesses).
+func (m *MockSensor) DistinctLongLivedProcesses(containerID string, minLifetime time.Duration) []types.ProcessInfo {
+	m.processMutex.Lock()
+	instances := make([]processInstance, len(m.processInstances[containerID]))
+	copy(instances, m.processInstances[containerID])
+	alive := m.processAlive
+	m.processMutex.Unlock()
+
+	if alive == nil {
+		alive = func(int) bool { return true }
+	}
+	return longLivedProcesses(instances, time.Now(), minLifetime, alive)
+}
+
+func longLivedProcesses(instances []processInstance, now time.Time, minLifetime time.Duration, alive ProcessAliveCheck) []types.ProcessInfo {
+	seen := map[types.ProcessInfo]bool{}
+	processes := []types.ProcessInfo{}
+	for _, instance := range instances {
+		if seen[instance.process] || now.Sub(instance.start) < minLifetime || !alive(instance.pid) {
+			continue
+		}
+		seen[instance.process] = true
+		processes = append(processes, instance.process)
+	}
+	types.SortProcesses(processes)
+	return processes
+}
@@ -0,0 +1,32 @@
+package mock_sensor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stackrox/collector/integration-tests/pkg/types"
+)
+
+func TestLongLivedProcesses(t *testing.T) {
+	now := time.Now()
+	socat := types.ProcessInfo{Name: "socat", ExePath: "/usr/bin/socat", Args: "TCP-LISTEN:80,fork STDOUT"}
+	sh := types.ProcessInfo{Name: "sh", ExePath: "/bin/sh", Args: "-c socat TCP-LISTEN:80,fork STDOUT &"}
+	ps := types.ProcessInfo{Name: "ps", ExePath: "/bin/ps"}
+
+	instances := []processInstance{
+		{process: sh, pid: 10, start: now.Add(-time.Minute)},
+		// killed, then restarted
+		{process: socat, pid: 11, start: now.Add(-time.Minute)},
+		{process: socat, pid: 12, start: now.Add(-30 * time.Second)},
+		// still running, but only just started
+		{process: ps, pid: 13, start: now.Add(-100 * time.Millisecond)},
+	}
+	running := map[int]bool{12: true, 13: true}
+
+	processes := longLivedProcesses(instances, now, time.Second, func(pid int) bool {
+		return running[pid]
+	})
+	assert.Equal(t, []types.ProcessInfo{socat}, processes)
+}
@@ -0,0 +1,78 @@
+package mock_sensor
+
+import (
+	"net"
+	"sort"
+
+	"github.com/stackrox/collector/integration-tests/pkg/types"
+)
+
+// NetworkEdge is a connection between two containers, identified by their
+// IDs, from the one that connected to the one that accepted.
+type NetworkEdge struct {
+	Client string
+	Server string
+}
+
+// NetworkGraph returns the connections reported between the given
+// containers, which are mapped to their IP addresses, since the remote end
+// of a connection is only known by address. Each side of a connection is
+// enough for its edge to be reported, so that the graph shows what each
+// container saw. The edges are distinct and sorted.
+func (m *MockSensor) NetworkGraph(containerIPs map[string]string) []NetworkEdge {
+	connections := map[string][]types.NetworkInfo{}
+	for containerID := range containerIPs {
+		connections[containerID] = m.Connections(containerID)
+	}
+	return networkGraph(containerIPs, connections)
+}
+
+func networkGraph(containerIPs map[string]string, connections map[string][]types.NetworkInfo) []NetworkEdge {
+	byIP := map[string]string{}
+	for containerID, ip := range containerIPs {
+		byIP[ip] = containerID
+	}
+
+	seen := map[NetworkEdge]bool{}
+	edges := []NetworkEdge{}
+	for containerID, conns := range connections {
+		for _, conn := range conns {
+			remote, ok := byIP[remoteIP(conn.RemoteAddress)]
+			if !ok {
+				continue
+			}
+
+			var edge NetworkEdge
+			switch conn.Role {
+			case "ROLE_CLIENT":
+				edge = NetworkEdge{Client: containerID, Server: remote}
+			case "ROLE_SERVER":
+				edge = NetworkEdge{Client: remote, Server: containerID}
+			default:
+				continue
+			}
+
+			if !seen[edge] {
+				seen[edge] = true
+				edges = append(edges, edge)
+			}
+		}
+	}
+
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].Client != edges[j].Client {
+			return edges[i].Client < edges[j].Client
+		}
+		return edges[i].Server < edges[j].Server
+	})
+	return edges
+}
+
+// remoteIP returns the IP of a remote address, which has no port on the
+// server side of a connection.
+func remoteIP(address string) string {
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		return host
+	}
+	return address
+}
@@ -0,0 +1,37 @@
+package mock_sensor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stackrox/collector/integration-tests/pkg/types"
+)
+
+func TestNetworkGraph(t *testing.T) {
+	ips := map[string]string{
+		"client": "10.0.0.1",
+		"proxy":  "10.0.0.2",
+		"server": "10.0.0.3",
+	}
+
+	connections := map[string][]types.NetworkInfo{
+		"client": {
+			{RemoteAddress: "10.0.0.2:8080", Role: "ROLE_CLIENT"},
+			// outside of the graph
+			{RemoteAddress: "1.1.1.1:53", Role: "ROLE_CLIENT"},
+		},
+		"proxy": {
+			{LocalAddress: ":8080", RemoteAddress: "10.0.0.1", Role: "ROLE_SERVER"},
+			{RemoteAddress: "10.0.0.3:80", Role: "ROLE_CLIENT"},
+			{RemoteAddress: "10.0.0.3:80", Role: "ROLE_CLIENT", CloseTimestamp: "2024-01-01 00:00:00 +0000 UTC"},
+		},
+		// only the client side of the second hop was reported
+		"server": {},
+	}
+
+	assert.Equal(t, []NetworkEdge{
+		{Client: "client", Server: "proxy"},
+		{Client: "proxy", Server: "server"},
+	}, networkGraph(ips, connections))
+}
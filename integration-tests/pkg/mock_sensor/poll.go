@@ -0,0 +1,44 @@
+package mock_sensor
+
+import (
+	"errors"
+	"math/rand"
+	"time"
+
+	"github.com/stackrox/collector/integration-tests/pkg/config"
+)
+
+// errPollTimeout is returned by pollUntil if the check did not succeed
+// before the timeout expired.
+var errPollTimeout = errors.New("timed out")
+
+// pollUntil runs the check until it succeeds, fails, or the timeout expires.
+// The interval between checks starts small, so that signals that are already
+// there (or arrive shortly) are found quickly, and doubles after each check
+// up to a maximum, so that long waits do not keep the CPU busy. A jitter of
+// up to 20% is applied to each interval.
+func pollUntil(timeout time.Duration, check func() (bool, error)) error {
+	options := config.PollingInfo()
+	deadline := time.Now().Add(timeout)
+	interval := options.InitialInterval
+
+	for {
+		done, err := check()
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return errPollTimeout
+		}
+
+		sleep := time.Duration(float64(interval) * (0.8 + 0.4*rand.Float64()))
+		time.Sleep(min(sleep, remaining))
+
+		interval = min(2*interval, options.MaxInterval)
+	}
+}
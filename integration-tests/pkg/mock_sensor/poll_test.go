@@ -0,0 +1,43 @@
+package mock_sensor
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPollUntilSucceeds(t *testing.T) {
+	calls := 0
+	err := pollUntil(5*time.Second, func() (bool, error) {
+		calls++
+		return calls == 3, nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestPollUntilFails(t *testing.T) {
+	failure := errors.New("unexpected signal")
+	err := pollUntil(5*time.Second, func() (bool, error) {
+		return false, failure
+	})
+
+	assert.ErrorIs(t, err, failure)
+}
+
+func TestPollUntilTimeout(t *testing.T) {
+	start := time.Now()
+	calls := 0
+	err := pollUntil(300*time.Millisecond, func() (bool, error) {
+		calls++
+		return false, nil
+	})
+
+	assert.ErrorIs(t, err, errPollTimeout)
+	assert.GreaterOrEqual(t, time.Since(start), 300*time.Millisecond)
+	// checked once more once the timeout expired
+	assert.Greater(t, calls, 1)
+}
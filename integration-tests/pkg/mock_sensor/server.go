@@ -1,6 +1,7 @@
 package mock_sensor
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net"
@@ -15,7 +16,9 @@ import (
 
 	"github.com/stackrox/rox/generated/storage"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/keepalive"
+	"google.golang.org/protobuf/proto"
 
 	"github.com/stackrox/collector/integration-tests/pkg/config"
 	"github.com/stackrox/collector/integration-tests/pkg/types"
@@ -33,9 +36,32 @@ const (
 // us to use any comparable type as the key)
 type ProcessMap map[types.ProcessInfo]interface{}
 type LineageMap map[types.ProcessLineage]interface{}
+
+// ConnMap stores the last raw signal received for each connection
 type ConnMap map[types.NetworkInfo]interface{}
 type EndpointMap map[types.EndpointInfo]interface{}
 
+// ConnectionEvent is a single connection report, along with the time
+// at which it was received.
+type ConnectionEvent struct {
+	Connection types.NetworkInfo
+	Received   time.Time
+}
+
+// EndpointEvent is a single endpoint report, along with the time at which
+// it was received.
+type EndpointEvent struct {
+	Endpoint types.EndpointInfo
+	Received time.Time
+}
+
+// ConnectionState is a single observation of the state of a connection.
+type ConnectionState struct {
+	Active         bool
+	CloseTimestamp string
+	Received       time.Time
+}
+
 type MockSensor struct {
 	testName string
 	logger   *log.Logger
@@ -46,11 +72,20 @@ type MockSensor struct {
 
 	processes       map[string]ProcessMap
 	processLineages map[string]LineageMap
-	processMutex    sync.Mutex
-
-	connections  map[string]ConnMap
-	endpoints    map[string]EndpointMap
-	networkMutex sync.Mutex
+	// the start time last reported for each process name, by container
+	processTimes map[string]map[string]time.Time
+	// every reported process instance, by container
+	processInstances map[string][]processInstance
+	processAlive     ProcessAliveCheck
+	processLimit     storeLimit
+	processMutex     sync.Mutex
+
+	connections      map[string]ConnMap
+	connectionEvents map[string][]ConnectionEvent
+	endpoints        map[string]EndpointMap
+	endpointEvents   map[string][]EndpointEvent
+	limit            storeLimit
+	networkMutex     sync.Mutex
 
 	// every event will be forwarded to these channels, to allow
 	// tests to look directly at the incoming data without
@@ -59,16 +94,30 @@ type MockSensor struct {
 	lineageChannel    RingChan[*storage.ProcessSignal_LineageInfo]
 	connectionChannel RingChan[*sensorAPI.NetworkConnection]
 	endpointChannel   RingChan[*sensorAPI.NetworkEndpoint]
+
+	faults faults
+
+	// the signal types to record, or nil for all of them
+	accepted      map[SignalType]bool
+	acceptedMutex sync.Mutex
 }
 
 func NewMockSensor(test string) *MockSensor {
-	return &MockSensor{
-		testName:        test,
-		processes:       make(map[string]ProcessMap),
-		processLineages: make(map[string]LineageMap),
-		connections:     make(map[string]ConnMap),
-		endpoints:       make(map[string]EndpointMap),
+	m := &MockSensor{
+		testName:         test,
+		processes:        make(map[string]ProcessMap),
+		processLineages:  make(map[string]LineageMap),
+		processTimes:     make(map[string]map[string]time.Time),
+		processInstances: make(map[string][]processInstance),
+		connections:      make(map[string]ConnMap),
+		connectionEvents: make(map[string][]ConnectionEvent),
+		endpoints:        make(map[string]EndpointMap),
+		endpointEvents:   make(map[string][]EndpointEvent),
+		faults:           faults{rejectAfter: -1},
 	}
+	m.processLimit.room = sync.NewCond(&m.processMutex)
+	m.limit.room = sync.NewCond(&m.networkMutex)
+	return m
 }
 
 // LiveProcesses returns a channel that can be used to read live
@@ -78,7 +127,8 @@ func (m *MockSensor) LiveProcesses() <-chan *storage.ProcessSignal {
 }
 
 // Processes returns a list of all processes that have been receieved for
-// a given container ID
+// a given container ID. The list is sorted by process name and pid (see
+// types.SortProcesses), so the order is stable across calls and runs.
 func (m *MockSensor) Processes(containerID string) []types.ProcessInfo {
 	m.processMutex.Lock()
 	defer m.processMutex.Unlock()
@@ -88,6 +138,7 @@ func (m *MockSensor) Processes(containerID string) []types.ProcessInfo {
 		for k := range processes {
 			keys = append(keys, k)
 		}
+		types.SortProcesses(keys)
 		return keys
 	}
 	return make([]types.ProcessInfo, 0)
@@ -107,6 +158,32 @@ func (m *MockSensor) HasProcess(containerID string, process types.ProcessInfo) b
 	return false
 }
 
+// ProcessStartTime returns the start time collector last reported for a
+// process of the given name in a given container ID.
+func (m *MockSensor) ProcessStartTime(containerID string, name string) (time.Time, bool) {
+	m.processMutex.Lock()
+	defer m.processMutex.Unlock()
+
+	start, ok := m.processTimes[containerID][name]
+	return start, ok
+}
+
+// ProcessStartTimes returns the start time of every process instance
+// collector reported with the given PID in a given container ID, in the
+// order they were received.
+func (m *MockSensor) ProcessStartTimes(containerID string, pid int) []time.Time {
+	m.processMutex.Lock()
+	defer m.processMutex.Unlock()
+
+	starts := []time.Time{}
+	for _, instance := range m.processInstances[containerID] {
+		if instance.pid == pid {
+			starts = append(starts, instance.start)
+		}
+	}
+	return starts
+}
+
 // LiveLineages returns a channel that can be used to read live
 // process lineage events
 func (m *MockSensor) LiveLineages() <-chan *storage.ProcessSignal_LineageInfo {
@@ -165,6 +242,54 @@ func (m *MockSensor) Connections(containerID string) []types.NetworkInfo {
 	return make([]types.NetworkInfo, 0)
 }
 
+// ConnectionCount returns the number of distinct connections received for a
+// given container ID. A connection reported both open and closed counts once.
+func (m *MockSensor) ConnectionCount(containerID string) int {
+	return countDistinctConnections(m.Connections(containerID))
+}
+
+// ConnectionEvents returns every connection report received for a given
+// container ID, including repeated reports, in the order they arrived.
+func (m *MockSensor) ConnectionEvents(containerID string) []ConnectionEvent {
+	m.networkMutex.Lock()
+	defer m.networkMutex.Unlock()
+
+	events := make([]ConnectionEvent, len(m.connectionEvents[containerID]))
+	copy(events, m.connectionEvents[containerID])
+	return events
+}
+
+// ConnectionHistory returns the ordered state observations of a single
+// logical connection, selected by the matcher (e.g. by its addresses and
+// role, regardless of its close timestamp). This shows the lifecycle of the
+// connection, e.g. reported active and later closed once afterglow expired.
+func (m *MockSensor) ConnectionHistory(containerID string, matcher ConnectionMatcher) []ConnectionState {
+	history := make([]ConnectionState, 0)
+	for _, event := range m.ConnectionEvents(containerID) {
+		if matcher(event.Connection) {
+			history = append(history, ConnectionState{
+				Active:         event.Connection.IsActive(),
+				CloseTimestamp: event.Connection.CloseTimestamp,
+				Received:       event.Received,
+			})
+		}
+	}
+	return history
+}
+
+// CountConnections returns the number of reports of connections satisfying
+// the matcher for a given container ID, counting repeated reports of the
+// same connection separately.
+func (m *MockSensor) CountConnections(containerID string, matcher ConnectionMatcher) int {
+	count := 0
+	for _, event := range m.ConnectionEvents(containerID) {
+		if matcher(event.Connection) {
+			count++
+		}
+	}
+	return count
+}
+
 // HasConnection returns whether a given connection has been seen for a given
 // container ID
 func (m *MockSensor) HasConnection(containerID string, conn types.NetworkInfo) bool {
@@ -179,6 +304,56 @@ func (m *MockSensor) HasConnection(containerID string, conn types.NetworkInfo) b
 	return false
 }
 
+// HasConnectionMatch returns whether a connection satisfying the matcher
+// has been seen for a given container ID
+func (m *MockSensor) HasConnectionMatch(containerID string, matcher ConnectionMatcher) bool {
+	m.networkMutex.Lock()
+	defer m.networkMutex.Unlock()
+
+	if conns, ok := m.connections[containerID]; ok {
+		for conn := range conns {
+			if matcher(conn) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// RawSignal returns the raw signal last received for a connection satisfying
+// the matcher, to help tell whether an unexpected value was sent by
+// collector or introduced when the harness parsed it.
+func (m *MockSensor) RawSignal(containerID string, matcher ConnectionMatcher) (proto.Message, bool) {
+	m.networkMutex.Lock()
+	defer m.networkMutex.Unlock()
+
+	for conn, raw := range m.connections[containerID] {
+		if matcher(conn) {
+			signal, ok := raw.(*sensorAPI.NetworkConnection)
+			return signal, ok
+		}
+	}
+
+	return nil, false
+}
+
+// UDPConnections returns the connections received for a given container ID
+// that use the UDP protocol.
+func (m *MockSensor) UDPConnections(containerID string) []types.NetworkInfo {
+	m.networkMutex.Lock()
+	defer m.networkMutex.Unlock()
+
+	udp := make([]types.NetworkInfo, 0)
+	for conn, raw := range m.connections[containerID] {
+		signal, ok := raw.(*sensorAPI.NetworkConnection)
+		if ok && signal.GetProtocol() == storage.L4Protocol_L4_PROTOCOL_UDP {
+			udp = append(udp, conn)
+		}
+	}
+	return udp
+}
+
 // Liveendpoints returns a channel that can be used to read live
 // endpoint events
 func (m *MockSensor) LiveEndpoints() <-chan *sensorAPI.NetworkEndpoint {
@@ -186,7 +361,8 @@ func (m *MockSensor) LiveEndpoints() <-chan *sensorAPI.NetworkEndpoint {
 }
 
 // Endpoints returns a list of all endpoints that have been received for
-// a given container ID
+// a given container ID. The list is sorted by protocol, port and address
+// (see types.SortEndpoints), so the order is stable across calls and runs.
 func (m *MockSensor) Endpoints(containerID string) []types.EndpointInfo {
 	m.networkMutex.Lock()
 	defer m.networkMutex.Unlock()
@@ -196,11 +372,70 @@ func (m *MockSensor) Endpoints(containerID string) []types.EndpointInfo {
 		for k := range endpoints {
 			keys = append(keys, k)
 		}
+		types.SortEndpoints(keys)
 		return keys
 	}
 	return make([]types.EndpointInfo, 0)
 }
 
+// ConnectionsByRemotePort returns the connections for a given container ID,
+// grouped by their remote port, so that the connections to each port of a
+// multi-port service can be asserted separately. Connections without a
+// remote port are grouped under 0.
+func (m *MockSensor) ConnectionsByRemotePort(containerID string) map[int][]types.NetworkInfo {
+	byPort := make(map[int][]types.NetworkInfo)
+	for _, conn := range m.Connections(containerID) {
+		port := conn.RemotePort()
+		byPort[port] = append(byPort[port], conn)
+	}
+	return byPort
+}
+
+// EndpointsByPort returns the endpoints for a given container ID, grouped
+// by their listening port.
+func (m *MockSensor) EndpointsByPort(containerID string) map[int][]types.EndpointInfo {
+	byPort := make(map[int][]types.EndpointInfo)
+	for _, endpoint := range m.Endpoints(containerID) {
+		byPort[endpoint.Address.Port] = append(byPort[endpoint.Address.Port], endpoint)
+	}
+	return byPort
+}
+
+// EndpointsByProtocol returns the endpoints for a given container ID,
+// grouped by their L4 protocol (e.g. L4_PROTOCOL_UDP), so that reporting of
+// each protocol can be asserted separately.
+func (m *MockSensor) EndpointsByProtocol(containerID string) map[string][]types.EndpointInfo {
+	byProtocol := make(map[string][]types.EndpointInfo)
+	for _, endpoint := range m.Endpoints(containerID) {
+		byProtocol[endpoint.Protocol] = append(byProtocol[endpoint.Protocol], endpoint)
+	}
+	return byProtocol
+}
+
+// EndpointEvents returns every endpoint report received for a given
+// container ID, including repeated reports, in the order they arrived.
+func (m *MockSensor) EndpointEvents(containerID string) []EndpointEvent {
+	m.networkMutex.Lock()
+	defer m.networkMutex.Unlock()
+
+	events := make([]EndpointEvent, len(m.endpointEvents[containerID]))
+	copy(events, m.endpointEvents[containerID])
+	return events
+}
+
+// EndpointLifecycle returns the endpoint reports received for a given
+// container ID on the listening port, in the order they arrived, which
+// traces the opens and closes of the port.
+func (m *MockSensor) EndpointLifecycle(containerID string, port int) []EndpointEvent {
+	lifecycle := []EndpointEvent{}
+	for _, event := range m.EndpointEvents(containerID) {
+		if event.Endpoint.Address.Port == port {
+			lifecycle = append(lifecycle, event)
+		}
+	}
+	return lifecycle
+}
+
 // HasEndpoint returns whether a given endpoint has been seen for a given
 // container ID
 func (m *MockSensor) HasEndpoint(containerID string, endpoint types.EndpointInfo) bool {
@@ -218,6 +453,49 @@ func (m *MockSensor) HasEndpoint(containerID string, endpoint types.EndpointInfo
 	return false
 }
 
+// HasSignalsFor returns whether any signal of the given types was received
+// for a given container ID, or of any type if none is given.
+func (m *MockSensor) HasSignalsFor(containerID string, signalTypes ...SignalType) bool {
+	if len(signalTypes) == 0 {
+		signalTypes = []SignalType{ProcessSignalType, LineageSignalType, ConnectionSignalType, EndpointSignalType}
+	}
+
+	for _, signalType := range signalTypes {
+		switch signalType {
+		case ProcessSignalType:
+			if len(m.Processes(containerID)) > 0 {
+				return true
+			}
+		case LineageSignalType:
+			if len(m.ProcessLineages(containerID)) > 0 {
+				return true
+			}
+		case ConnectionSignalType:
+			if len(m.Connections(containerID)) > 0 {
+				return true
+			}
+		case EndpointSignalType:
+			if len(m.Endpoints(containerID)) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasNetworkInfo returns whether any connection or endpoint was received,
+// for any container.
+func (m *MockSensor) HasNetworkInfo() bool {
+	m.networkMutex.Lock()
+	defer m.networkMutex.Unlock()
+	return len(m.connectionEvents) > 0 || len(m.endpointEvents) > 0
+}
+
+// Port returns the port the gRPC server listens on for collector.
+func (m *MockSensor) Port() int {
+	return gMockSensorPort
+}
+
 // Start will initialize the gRPC server and begin serving
 // The server itself runs in a separate thread.
 func (m *MockSensor) Start() {
@@ -258,6 +536,14 @@ func (m *MockSensor) Start() {
 	m.connectionChannel = NewRingChan[*sensorAPI.NetworkConnection](gDefaultRingSize)
 	m.endpointChannel = NewRingChan[*sensorAPI.NetworkEndpoint](gDefaultRingSize)
 
+	m.processMutex.Lock()
+	m.processLimit.close(false)
+	m.processMutex.Unlock()
+
+	m.networkMutex.Lock()
+	m.limit.close(false)
+	m.networkMutex.Unlock()
+
 	go func() {
 		if err := m.grpcServer.Serve(m.listener); err != nil {
 			log.Fatalf("failed to serve: %v", err)
@@ -265,18 +551,46 @@ func (m *MockSensor) Start() {
 	}()
 }
 
+// WaitForListening waits up to the timeout for the gRPC server to accept
+// connections, so that collector connects on its first attempt rather than
+// retrying. The server must have been started.
+func (m *MockSensor) WaitForListening(timeout time.Duration) error {
+	if m.listener == nil {
+		return fmt.Errorf("mock sensor is not started")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, fmt.Sprintf("localhost:%d", m.Port()),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithBlock(),
+	)
+	if err != nil {
+		return fmt.Errorf("mock sensor is not accepting connections: %w", err)
+	}
+	return conn.Close()
+}
+
 // Stop will shut down the gRPC server and clear the internal store of
 // all events
 func (m *MockSensor) Stop() {
+	// release the reports blocked on a full history, so that their
+	// handlers can return
+	m.processMutex.Lock()
+	m.processLimit.close(true)
+	m.processMutex.Unlock()
+
+	m.networkMutex.Lock()
+	m.limit.close(true)
+	m.networkMutex.Unlock()
+
 	m.grpcServer.Stop()
 	m.listener.Close()
 	m.logFile.Close()
 	m.logger = nil
 
-	m.processes = make(map[string]ProcessMap)
-	m.processLineages = make(map[string]LineageMap)
-	m.connections = make(map[string]ConnMap)
-	m.endpoints = make(map[string]EndpointMap)
+	m.Clear()
 
 	m.processChannel.Stop()
 	m.lineageChannel.Stop()
@@ -284,6 +598,26 @@ func (m *MockSensor) Stop() {
 	m.endpointChannel.Stop()
 }
 
+// Clear empties the internal store of all events, without interrupting
+// the gRPC server, so that a test can check signals phase by phase.
+func (m *MockSensor) Clear() {
+	m.processMutex.Lock()
+	m.processes = make(map[string]ProcessMap)
+	m.processLineages = make(map[string]LineageMap)
+	m.processTimes = make(map[string]map[string]time.Time)
+	m.processInstances = make(map[string][]processInstance)
+	m.processLimit.reset()
+	m.processMutex.Unlock()
+
+	m.networkMutex.Lock()
+	m.connections = make(map[string]ConnMap)
+	m.connectionEvents = make(map[string][]ConnectionEvent)
+	m.endpoints = make(map[string]EndpointMap)
+	m.endpointEvents = make(map[string][]EndpointEvent)
+	m.limit.reset()
+	m.networkMutex.Unlock()
+}
+
 // PushSignals conforms to the Sensor API. It is here that process signals and
 // process lineage information is handled and stored/sent to the relevant channel
 func (m *MockSensor) PushSignals(stream sensorAPI.SignalService_PushSignalsServer) error {
@@ -293,6 +627,10 @@ func (m *MockSensor) PushSignals(stream sensorAPI.SignalService_PushSignalsServe
 			return err
 		}
 
+		if err := m.injectFault(); err != nil {
+			return err
+		}
+
 		if signal != nil && signal.GetSignal() != nil && signal.GetSignal().GetProcessSignal() != nil {
 			processSignal := signal.GetSignal().GetProcessSignal()
 
@@ -340,6 +678,10 @@ func (m *MockSensor) PushNetworkConnectionInfo(stream sensorAPI.NetworkConnectio
 			return err
 		}
 
+		if err := m.injectFault(); err != nil {
+			return err
+		}
+
 		networkConnInfo := signal.GetInfo()
 		connections := networkConnInfo.GetUpdatedConnections()
 		endpoints := networkConnInfo.GetUpdatedEndpoints()
@@ -359,6 +701,10 @@ func (m *MockSensor) PushNetworkConnectionInfo(stream sensorAPI.NetworkConnectio
 // pushProcess converts a process signal into the test's own structure
 // and stores it
 func (m *MockSensor) pushProcess(containerID string, processSignal *storage.ProcessSignal) {
+	if !m.accepts(ProcessSignalType) {
+		return
+	}
+
 	m.processMutex.Lock()
 	defer m.processMutex.Unlock()
 
@@ -379,17 +725,41 @@ func (m *MockSensor) pushProcess(containerID string, processSignal *storage.Proc
 		Args:    processSignal.GetArgs(),
 	}
 
+	if !m.processLimit.reserve(m.evictOldestProcess) {
+		return
+	}
+
 	if processes, ok := m.processes[containerID]; ok {
 		processes[process] = true
 	} else {
 		processes := ProcessMap{process: true}
 		m.processes[containerID] = processes
 	}
+
+	start := time.Now()
+	if processSignal.GetTime() != nil {
+		start = processSignal.GetTime().AsTime()
+		if _, ok := m.processTimes[containerID]; !ok {
+			m.processTimes[containerID] = make(map[string]time.Time)
+		}
+		m.processTimes[containerID][process.Name] = start
+	}
+
+	m.processInstances[containerID] = append(m.processInstances[containerID], processInstance{
+		process:  process,
+		pid:      int(processSignal.GetPid()),
+		start:    start,
+		received: time.Now(),
+	})
 }
 
 // pushLineage converts a process lineage into the test's own structure
 // and stores it
 func (m *MockSensor) pushLineage(containerID string, process *storage.ProcessSignal, lineage *storage.ProcessSignal_LineageInfo) {
+	if !m.accepts(LineageSignalType) {
+		return
+	}
+
 	m.processMutex.Lock()
 	defer m.processMutex.Unlock()
 
@@ -417,6 +787,10 @@ func (m *MockSensor) pushLineage(containerID string, process *storage.ProcessSig
 // pushConnection converts a connection event into the test's own structure
 // and stores it
 func (m *MockSensor) pushConnection(containerID string, connection *sensorAPI.NetworkConnection) {
+	if !m.accepts(ConnectionSignalType) {
+		return
+	}
+
 	m.networkMutex.Lock()
 	defer m.networkMutex.Unlock()
 
@@ -436,10 +810,17 @@ func (m *MockSensor) pushConnection(containerID string, connection *sensorAPI.Ne
 		CloseTimestamp: connection.GetCloseTimestamp().String(),
 	}
 
+	if m.limit.reserve(m.evictOldestReport) {
+		m.connectionEvents[containerID] = append(m.connectionEvents[containerID], ConnectionEvent{
+			Connection: conn,
+			Received:   time.Now(),
+		})
+	}
+
 	if connections, ok := m.connections[containerID]; ok {
-		connections[conn] = true
+		connections[conn] = connection
 	} else {
-		connections := ConnMap{conn: true}
+		connections := ConnMap{conn: connection}
 		m.connections[containerID] = connections
 	}
 }
@@ -447,6 +828,10 @@ func (m *MockSensor) pushConnection(containerID string, connection *sensorAPI.Ne
 // pushEndpoint converts an endpoint event into the test's own structure
 // and stores it
 func (m *MockSensor) pushEndpoint(containerID string, endpoint *sensorAPI.NetworkEndpoint) {
+	if !m.accepts(EndpointSignalType) {
+		return
+	}
+
 	m.networkMutex.Lock()
 	defer m.networkMutex.Unlock()
 
@@ -477,11 +862,19 @@ func (m *MockSensor) pushEndpoint(containerID string, endpoint *sensorAPI.Networ
 
 	ep := types.EndpointInfo{
 		Protocol:       endpoint.GetProtocol().String(),
+		SocketFamily:   endpoint.GetSocketFamily().String(),
 		Originator:     originator,
 		CloseTimestamp: endpoint.GetCloseTimestamp().String(),
 		Address:        listen,
 	}
 
+	if m.limit.reserve(m.evictOldestReport) {
+		m.endpointEvents[containerID] = append(m.endpointEvents[containerID], EndpointEvent{
+			Endpoint: ep,
+			Received: time.Now(),
+		})
+	}
+
 	if endpoints, ok := m.endpoints[containerID]; ok {
 		endpoints[ep] = true
 	} else {
@@ -0,0 +1,38 @@
+package mock_sensor
+
+// SignalType is a kind of signal the MockSensor can record.
+type SignalType int
+
+const (
+	ProcessSignalType SignalType = iota
+	LineageSignalType
+	ConnectionSignalType
+	EndpointSignalType
+)
+
+// SetAcceptedSignalTypes makes the MockSensor only record signals of the
+// given types, and drop the others, for tests that only care about some of
+// them. Calling it without any type accepts all of them again, which is the
+// default.
+func (m *MockSensor) SetAcceptedSignalTypes(signalTypes ...SignalType) {
+	m.acceptedMutex.Lock()
+	defer m.acceptedMutex.Unlock()
+
+	if len(signalTypes) == 0 {
+		m.accepted = nil
+		return
+	}
+
+	m.accepted = make(map[SignalType]bool, len(signalTypes))
+	for _, signalType := range signalTypes {
+		m.accepted[signalType] = true
+	}
+}
+
+// accepts returns whether signals of the given type should be recorded.
+func (m *MockSensor) accepts(signalType SignalType) bool {
+	m.acceptedMutex.Lock()
+	defer m.acceptedMutex.Unlock()
+
+	return m.accepted == nil || m.accepted[signalType]
+}
@@ -0,0 +1,40 @@
+package mock_sensor
+
+import (
+	"io"
+	"log"
+	"testing"
+
+	sensorAPI "github.com/stackrox/rox/generated/internalapi/sensor"
+	"github.com/stackrox/rox/generated/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetAcceptedSignalTypes(t *testing.T) {
+	m := NewMockSensor("test")
+	m.logger = log.New(io.Discard, "", 0)
+
+	m.SetAcceptedSignalTypes(EndpointSignalType)
+
+	process := &storage.ProcessSignal{ContainerId: "abc", Name: "sleep"}
+	m.pushProcess("abc", process)
+	m.pushLineage("abc", process, &storage.ProcessSignal_LineageInfo{ParentUid: 0})
+	m.pushConnection("abc", &sensorAPI.NetworkConnection{ContainerId: "abc"})
+	m.pushEndpoint("abc", &sensorAPI.NetworkEndpoint{ContainerId: "abc"})
+
+	assert.Empty(t, m.Processes("abc"))
+	assert.Empty(t, m.ProcessLineages("abc"))
+	assert.Empty(t, m.Connections("abc"))
+	assert.Len(t, m.Endpoints("abc"), 1)
+	assert.True(t, m.HasSignalsFor("abc"))
+	assert.False(t, m.HasSignalsFor("abc", ProcessSignalType, ConnectionSignalType))
+	assert.False(t, m.HasSignalsFor("def"))
+
+	// without any type, all of them are accepted again
+	m.SetAcceptedSignalTypes()
+	m.pushProcess("abc", process)
+	m.pushConnection("abc", &sensorAPI.NetworkConnection{ContainerId: "abc"})
+
+	assert.Len(t, m.Processes("abc"), 1)
+	assert.Len(t, m.Connections("abc"), 1)
+}
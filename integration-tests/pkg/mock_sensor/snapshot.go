@@ -0,0 +1,63 @@
+package mock_sensor
+
+import (
+	"fmt"
+
+	"github.com/stackrox/collector/integration-tests/pkg/types"
+)
+
+// SignalSnapshot is a copy of the signals received for a container at a
+// point in time, which remains valid once the store is cleared, so that the
+// signals of separate runs of a scenario can be compared.
+type SignalSnapshot struct {
+	Processes   []types.ProcessInfo
+	Connections []types.NetworkInfo
+	Endpoints   []types.EndpointInfo
+}
+
+// Snapshot returns a copy of the signals received so far for a given
+// container ID.
+func (m *MockSensor) Snapshot(containerID string) SignalSnapshot {
+	return SignalSnapshot{
+		Processes:   m.Processes(containerID),
+		Connections: m.Connections(containerID),
+		Endpoints:   m.Endpoints(containerID),
+	}
+}
+
+// DiffSnapshots returns the signals that are only in one of the snapshots,
+// prefixed with - if they are only in the first one, or + if they are only
+// in the second one. Signals are compared exactly, so any field that varies
+// between runs (e.g. PIDs) must be normalized beforehand.
+func DiffSnapshots(a, b SignalSnapshot) []string {
+	diff := []string{}
+	diff = append(diff, diffSignals("process", a.Processes, b.Processes)...)
+	diff = append(diff, diffSignals("connection", a.Connections, b.Connections)...)
+	diff = append(diff, diffSignals("endpoint", a.Endpoints, b.Endpoints)...)
+	return diff
+}
+
+func diffSignals[T comparable](kind string, a, b []T) []string {
+	inA := make(map[T]bool, len(a))
+	for _, signal := range a {
+		inA[signal] = true
+	}
+
+	inB := make(map[T]bool, len(b))
+	for _, signal := range b {
+		inB[signal] = true
+	}
+
+	diff := []string{}
+	for _, signal := range a {
+		if !inB[signal] {
+			diff = append(diff, fmt.Sprintf("- %s %+v", kind, signal))
+		}
+	}
+	for _, signal := range b {
+		if !inA[signal] {
+			diff = append(diff, fmt.Sprintf("+ %s %+v", kind, signal))
+		}
+	}
+	return diff
+}
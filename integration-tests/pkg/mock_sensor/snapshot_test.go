@@ -0,0 +1,31 @@
+package mock_sensor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stackrox/collector/integration-tests/pkg/types"
+)
+
+func TestDiffSnapshots(t *testing.T) {
+	curl := types.ProcessInfo{Name: "curl", ExePath: "/usr/bin/curl"}
+	sh := types.ProcessInfo{Name: "sh", ExePath: "/bin/sh"}
+	conn := types.NetworkInfo{RemoteAddress: "10.0.0.2:80", Role: "ROLE_CLIENT"}
+
+	a := SignalSnapshot{
+		Processes:   []types.ProcessInfo{curl, sh},
+		Connections: []types.NetworkInfo{conn},
+	}
+	b := SignalSnapshot{
+		Processes:   []types.ProcessInfo{curl},
+		Connections: []types.NetworkInfo{conn},
+		Endpoints:   []types.EndpointInfo{{Protocol: "L4_PROTOCOL_TCP"}},
+	}
+
+	assert.Empty(t, DiffSnapshots(a, a))
+	assert.Equal(t, []string{
+		"- process {Name:sh ExePath:/bin/sh Uid:0 Gid:0 Pid:0 Args:}",
+		"+ endpoint {Protocol:L4_PROTOCOL_TCP SocketFamily: Address:{AddressData: Port:0 IpNetwork:} CloseTimestamp: Originator:{ProcessName: ProcessExecFilePath: ProcessArgs:}}",
+	}, DiffSnapshots(a, b))
+}
@@ -0,0 +1,41 @@
+package mock_sensor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// AssertNoStaleSignals checks that every stored connection and endpoint
+// event arrived after the given time, typically that of the last Clear.
+// Any earlier event means the store was not properly reset and would leak
+// into the checks of the following phase. Process signals carry no arrival
+// time and are not checked.
+func (m *MockSensor) AssertNoStaleSignals(t *testing.T, before time.Time) bool {
+	m.networkMutex.Lock()
+	defer m.networkMutex.Unlock()
+
+	stale := []interface{}{}
+	for _, events := range m.connectionEvents {
+		for _, event := range events {
+			if event.Received.Before(before) {
+				stale = append(stale, event)
+			}
+		}
+	}
+
+	for _, events := range m.endpointEvents {
+		for _, event := range events {
+			if event.Received.Before(before) {
+				stale = append(stale, event)
+			}
+		}
+	}
+
+	if len(stale) > 0 {
+		return assert.Fail(t, "signals received before the store was cleared",
+			"stale: %+v", stale)
+	}
+	return true
+}
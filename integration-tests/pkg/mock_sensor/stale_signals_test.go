@@ -0,0 +1,29 @@
+package mock_sensor
+
+import (
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	sensorAPI "github.com/stackrox/rox/generated/internalapi/sensor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAssertNoStaleSignals(t *testing.T) {
+	m := NewMockSensor("test")
+	m.logger = log.New(io.Discard, "", 0)
+
+	m.pushConnection("abc", &sensorAPI.NetworkConnection{ContainerId: "abc"})
+	m.pushEndpoint("abc", &sensorAPI.NetworkEndpoint{ContainerId: "abc"})
+
+	cleared := time.Now()
+	assert.False(t, m.AssertNoStaleSignals(new(testing.T), cleared))
+
+	m.Clear()
+	assert.Empty(t, m.Connections("abc"))
+	assert.Empty(t, m.Endpoints("abc"))
+
+	m.pushConnection("abc", &sensorAPI.NetworkConnection{ContainerId: "abc"})
+	assert.True(t, m.AssertNoStaleSignals(t, cleared))
+}
@@ -0,0 +1,192 @@
+package mock_sensor
+
+import (
+	"sync"
+	"time"
+)
+
+// OverflowPolicy is what the MockSensor does with a new report once the
+// history of reports is full.
+type OverflowPolicy int
+
+const (
+	// DropOldest evicts the oldest report from the history, to make room
+	// for the new one
+	DropOldest OverflowPolicy = iota
+	// DropNewest leaves the new report out of the history
+	DropNewest
+	// Block stops reading from collector until there is room in the
+	// history, back-pressuring collector rather than losing any report
+	Block
+)
+
+// Metrics are counters about the store of the MockSensor.
+type Metrics struct {
+	// StoredReports is the number of reports in the history
+	StoredReports int
+	// DroppedReports is the number of reports left out of, or evicted
+	// from, the history since it was last cleared
+	DroppedReports int
+}
+
+// storeLimit bounds a history of reports, which grows with every report.
+// The MockSensor has one for the process reports, guarded by the process
+// mutex, and one for the connection and endpoint reports, guarded by the
+// network mutex.
+type storeLimit struct {
+	maxReports int
+	policy     OverflowPolicy
+	stored     int
+	dropped    int
+	// closed releases the reports blocked on a full history once the
+	// server stops
+	closed bool
+	// room is signaled whenever reports are removed from the history
+	room *sync.Cond
+}
+
+// SetStoreLimit bounds the number of process reports, and of connection and
+// endpoint reports, kept in the history (see ConnectionEvents and
+// EndpointEvents), applying the given policy to the reports received once it
+// is full. The latest state of each distinct connection and endpoint is
+// always kept, while a process is only kept as long as one of its reports
+// is. A limit of zero, the default, keeps every report.
+func (m *MockSensor) SetStoreLimit(maxReports int, policy OverflowPolicy) {
+	m.processMutex.Lock()
+	m.processLimit.set(maxReports, policy)
+	m.processMutex.Unlock()
+
+	m.networkMutex.Lock()
+	m.limit.set(maxReports, policy)
+	m.networkMutex.Unlock()
+}
+
+// Metrics returns the counters about the store of the MockSensor.
+func (m *MockSensor) Metrics() Metrics {
+	m.processMutex.Lock()
+	metrics := Metrics{
+		StoredReports:  m.processLimit.stored,
+		DroppedReports: m.processLimit.dropped,
+	}
+	m.processMutex.Unlock()
+
+	m.networkMutex.Lock()
+	defer m.networkMutex.Unlock()
+
+	metrics.StoredReports += m.limit.stored
+	metrics.DroppedReports += m.limit.dropped
+	return metrics
+}
+
+func (l *storeLimit) set(maxReports int, policy OverflowPolicy) {
+	l.maxReports = maxReports
+	l.policy = policy
+	l.room.Broadcast()
+}
+
+// reset empties the history, e.g. once the reports are cleared.
+func (l *storeLimit) reset() {
+	l.stored = 0
+	l.dropped = 0
+	l.room.Broadcast()
+}
+
+// close releases the reports blocked on a full history, or allows them to
+// block again.
+func (l *storeLimit) close(closed bool) {
+	l.closed = closed
+	l.room.Broadcast()
+}
+
+// reserve makes room for a new report in the history according to the
+// overflow policy, evicting reports with evictOldest, and returns whether the
+// report should be recorded. It must be called with the mutex of the limit
+// held, which is released while blocked.
+func (l *storeLimit) reserve(evictOldest func() bool) bool {
+	for l.maxReports > 0 && l.stored >= l.maxReports {
+		switch l.policy {
+		case DropNewest:
+			l.dropped++
+			return false
+		case DropOldest:
+			if !evictOldest() {
+				// nothing left to evict, which only happens if the
+				// history was emptied behind the limit's back
+				l.stored = 0
+				break
+			}
+			l.stored--
+			l.dropped++
+		case Block:
+			if l.closed {
+				l.dropped++
+				return false
+			}
+			l.room.Wait()
+		}
+	}
+
+	l.stored++
+	return true
+}
+
+// evictOldestReport removes the oldest connection or endpoint report from
+// the history, and returns whether there was one. It must be called with the
+// network mutex held.
+func (m *MockSensor) evictOldestReport() bool {
+	var oldest time.Time
+	oldestContainer := ""
+	oldestIsConnection := false
+
+	for containerID, events := range m.connectionEvents {
+		if len(events) > 0 && (oldestContainer == "" || events[0].Received.Before(oldest)) {
+			oldest, oldestContainer, oldestIsConnection = events[0].Received, containerID, true
+		}
+	}
+	for containerID, events := range m.endpointEvents {
+		if len(events) > 0 && (oldestContainer == "" || events[0].Received.Before(oldest)) {
+			oldest, oldestContainer, oldestIsConnection = events[0].Received, containerID, false
+		}
+	}
+
+	if oldestContainer == "" {
+		return false
+	}
+
+	if oldestIsConnection {
+		m.connectionEvents[oldestContainer] = m.connectionEvents[oldestContainer][1:]
+	} else {
+		m.endpointEvents[oldestContainer] = m.endpointEvents[oldestContainer][1:]
+	}
+	return true
+}
+
+// evictOldestProcess removes the oldest process report from the history,
+// along with its process if no other report of it is left, and returns
+// whether there was one. It must be called with the process mutex held.
+func (m *MockSensor) evictOldestProcess() bool {
+	var oldest time.Time
+	oldestContainer := ""
+
+	for containerID, instances := range m.processInstances {
+		if len(instances) > 0 && (oldestContainer == "" || instances[0].received.Before(oldest)) {
+			oldest, oldestContainer = instances[0].received, containerID
+		}
+	}
+
+	if oldestContainer == "" {
+		return false
+	}
+
+	instances := m.processInstances[oldestContainer]
+	evicted := instances[0].process
+	m.processInstances[oldestContainer] = instances[1:]
+
+	for _, instance := range instances[1:] {
+		if instance.process == evicted {
+			return true
+		}
+	}
+	delete(m.processes[oldestContainer], evicted)
+	return true
+}
@@ -0,0 +1,144 @@
+package mock_sensor
+
+import (
+	"io"
+	"log"
+	"testing"
+	"time"
+
+	sensorAPI "github.com/stackrox/rox/generated/internalapi/sensor"
+	"github.com/stackrox/rox/generated/storage"
+	"github.com/stretchr/testify/assert"
+)
+
+func newLimitedSensor(maxReports int, policy OverflowPolicy) *MockSensor {
+	m := NewMockSensor("test")
+	m.logger = log.New(io.Discard, "", 0)
+	m.SetStoreLimit(maxReports, policy)
+	return m
+}
+
+func pushConnections(m *MockSensor, ports ...uint32) {
+	for _, port := range ports {
+		m.pushConnection("abc", &sensorAPI.NetworkConnection{
+			ContainerId:   "abc",
+			RemoteAddress: &sensorAPI.NetworkAddress{Port: port},
+		})
+	}
+}
+
+func pushProcesses(m *MockSensor, names ...string) {
+	for _, name := range names {
+		m.pushProcess("abc", &storage.ProcessSignal{ContainerId: "abc", Name: name})
+	}
+}
+
+func processNames(m *MockSensor) []string {
+	names := []string{}
+	for _, process := range m.Processes("abc") {
+		names = append(names, process.Name)
+	}
+	return names
+}
+
+func TestStoreLimitDropOldest(t *testing.T) {
+	m := newLimitedSensor(2, DropOldest)
+	pushConnections(m, 1, 2, 3)
+
+	events := m.ConnectionEvents("abc")
+	assert.Len(t, events, 2)
+	assert.Equal(t, 2, events[0].Connection.RemotePort())
+	assert.Equal(t, 3, events[1].Connection.RemotePort())
+	assert.Len(t, m.Connections("abc"), 3)
+	assert.Equal(t, Metrics{StoredReports: 2, DroppedReports: 1}, m.Metrics())
+}
+
+func TestStoreLimitDropNewest(t *testing.T) {
+	m := newLimitedSensor(2, DropNewest)
+	pushConnections(m, 1, 2, 3)
+
+	events := m.ConnectionEvents("abc")
+	assert.Len(t, events, 2)
+	assert.Equal(t, 1, events[0].Connection.RemotePort())
+	assert.Equal(t, 2, events[1].Connection.RemotePort())
+	assert.Equal(t, Metrics{StoredReports: 2, DroppedReports: 1}, m.Metrics())
+}
+
+func TestStoreLimitBlock(t *testing.T) {
+	m := newLimitedSensor(2, Block)
+	pushConnections(m, 1, 2)
+
+	pushed := make(chan struct{})
+	go func() {
+		pushConnections(m, 3)
+		close(pushed)
+	}()
+
+	select {
+	case <-pushed:
+		t.Fatal("report pushed into a full store")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	m.Clear()
+	<-pushed
+
+	assert.Len(t, m.ConnectionEvents("abc"), 1)
+	assert.Equal(t, Metrics{StoredReports: 1}, m.Metrics())
+}
+
+func TestStoreLimitProcessesDropOldest(t *testing.T) {
+	m := newLimitedSensor(3, DropOldest)
+	pushProcesses(m, "a", "b", "a", "c")
+
+	// the oldest report of a is evicted, but not the process, which has
+	// another report left
+	assert.ElementsMatch(t, []string{"a", "b", "c"}, processNames(m))
+	assert.Len(t, m.processInstances["abc"], 3)
+
+	pushProcesses(m, "d")
+	assert.ElementsMatch(t, []string{"a", "c", "d"}, processNames(m))
+	assert.Equal(t, Metrics{StoredReports: 3, DroppedReports: 2}, m.Metrics())
+}
+
+func TestStoreLimitProcessesDropNewest(t *testing.T) {
+	m := newLimitedSensor(2, DropNewest)
+	pushProcesses(m, "a", "b", "c")
+
+	assert.ElementsMatch(t, []string{"a", "b"}, processNames(m))
+	assert.Len(t, m.processInstances["abc"], 2)
+	assert.Equal(t, Metrics{StoredReports: 2, DroppedReports: 1}, m.Metrics())
+}
+
+func TestStoreLimitProcessesBlock(t *testing.T) {
+	m := newLimitedSensor(1, Block)
+	pushProcesses(m, "a")
+
+	pushed := make(chan struct{})
+	go func() {
+		pushProcesses(m, "b")
+		close(pushed)
+	}()
+
+	select {
+	case <-pushed:
+		t.Fatal("report pushed into a full store")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	m.Clear()
+	<-pushed
+
+	assert.Equal(t, []string{"b"}, processNames(m))
+	assert.Equal(t, Metrics{StoredReports: 1}, m.Metrics())
+}
+
+func TestStoreLimitSeparateHistories(t *testing.T) {
+	m := newLimitedSensor(1, DropNewest)
+	pushProcesses(m, "a")
+	pushConnections(m, 1)
+
+	assert.Len(t, m.Processes("abc"), 1)
+	assert.Len(t, m.ConnectionEvents("abc"), 1)
+	assert.Equal(t, Metrics{StoredReports: 2}, m.Metrics())
+}
@@ -3,7 +3,11 @@ package types
 import "sort"
 
 type EndpointInfo struct {
-	Protocol       string
+	Protocol string
+	// SocketFamily distinguishes e.g. an IPv4 wildcard listener from an
+	// IPv6 one (SOCKET_FAMILY_IPV4, SOCKET_FAMILY_IPV6). It is only compared
+	// when set on both sides, so expectations can leave it empty.
+	SocketFamily   string
 	Address        ListenAddress
 	CloseTimestamp string
 	Originator     ProcessOriginator
@@ -15,6 +19,10 @@ func (n *EndpointInfo) IsActive() bool {
 }
 
 func (n *EndpointInfo) Less(other EndpointInfo) bool {
+	if n.Protocol != other.Protocol {
+		return n.Protocol < other.Protocol
+	}
+
 	addr1, addr2 := n.Address, other.Address
 
 	if !addr1.Equal(addr2) {
@@ -27,15 +35,29 @@ func (n *EndpointInfo) Less(other EndpointInfo) bool {
 		return process1.Less(process2)
 	}
 
-	return false
+	if n.SocketFamily != other.SocketFamily {
+		return n.SocketFamily < other.SocketFamily
+	}
+
+	return n.CloseTimestamp < other.CloseTimestamp
 }
 
 func (n *EndpointInfo) Equal(other EndpointInfo) bool {
-	return n.Address.Equal(other.Address) &&
+	return n.SameFamily(other) &&
+		n.Address.Equal(other.Address) &&
 		n.Originator.Equal(other.Originator) &&
 		n.IsActive() == other.IsActive()
 }
 
+// SameFamily returns whether two endpoints have the same socket family,
+// considering an unset family to match any other.
+func (n *EndpointInfo) SameFamily(other EndpointInfo) bool {
+	return n.SocketFamily == "" || other.SocketFamily == "" ||
+		n.SocketFamily == other.SocketFamily
+}
+
+// SortEndpoints orders endpoints by protocol, port and address, using
+// the originator, socket family and close timestamp to break ties.
 func SortEndpoints(endpoints []EndpointInfo) {
 	sort.Slice(endpoints, func(i, j int) bool { return endpoints[i].Less(endpoints[j]) })
 }
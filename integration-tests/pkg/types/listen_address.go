@@ -13,7 +13,13 @@ func (l *ListenAddress) Equal(other ListenAddress) bool {
 }
 
 func (l *ListenAddress) Less(other ListenAddress) bool {
-	return l.AddressData < other.AddressData ||
-		l.Port < other.Port ||
-		l.IpNetwork < other.IpNetwork
+	if l.Port != other.Port {
+		return l.Port < other.Port
+	}
+
+	if l.AddressData != other.AddressData {
+		return l.AddressData < other.AddressData
+	}
+
+	return l.IpNetwork < other.IpNetwork
 }
@@ -1,5 +1,10 @@
 package types
 
+import (
+	"net"
+	"strconv"
+)
+
 const (
 	NilTimestamp = "<nil>"
 )
@@ -16,3 +21,18 @@ func (n *NetworkInfo) IsActive() bool {
 	// no close timestamp means the connection is open, and active
 	return n.CloseTimestamp == NilTimestamp
 }
+
+// RemotePort returns the port of the remote address, or 0 if the address
+// has none, as in the remote address of a server-side connection.
+func (n *NetworkInfo) RemotePort() int {
+	_, port, err := net.SplitHostPort(n.RemoteAddress)
+	if err != nil {
+		return 0
+	}
+
+	p, err := strconv.Atoi(port)
+	if err != nil {
+		return 0
+	}
+	return p
+}
@@ -1,5 +1,7 @@
 package types
 
+import "sort"
+
 type ProcessInfo struct {
 	Name    string
 	ExePath string
@@ -9,6 +11,36 @@ type ProcessInfo struct {
 	Args    string
 }
 
+func (p *ProcessInfo) Less(other ProcessInfo) bool {
+	if p.Name != other.Name {
+		return p.Name < other.Name
+	}
+
+	if p.Pid != other.Pid {
+		return p.Pid < other.Pid
+	}
+
+	if p.ExePath != other.ExePath {
+		return p.ExePath < other.ExePath
+	}
+
+	if p.Args != other.Args {
+		return p.Args < other.Args
+	}
+
+	if p.Uid != other.Uid {
+		return p.Uid < other.Uid
+	}
+
+	return p.Gid < other.Gid
+}
+
+// SortProcesses orders processes by name and pid, using the remaining
+// fields to break ties.
+func SortProcesses(processes []ProcessInfo) {
+	sort.Slice(processes, func(i, j int) bool { return processes[i].Less(processes[j]) })
+}
+
 type ProcessLineage struct {
 	Name          string
 	ExePath       string
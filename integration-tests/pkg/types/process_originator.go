@@ -7,9 +7,15 @@ type ProcessOriginator struct {
 }
 
 func (p *ProcessOriginator) Less(other ProcessOriginator) bool {
-	return p.ProcessName < other.ProcessName ||
-		p.ProcessExecFilePath < other.ProcessExecFilePath ||
-		p.ProcessArgs < other.ProcessArgs
+	if p.ProcessName != other.ProcessName {
+		return p.ProcessName < other.ProcessName
+	}
+
+	if p.ProcessExecFilePath != other.ProcessExecFilePath {
+		return p.ProcessExecFilePath < other.ProcessExecFilePath
+	}
+
+	return p.ProcessArgs < other.ProcessArgs
 }
 
 func (p *ProcessOriginator) Equal(other ProcessOriginator) bool {
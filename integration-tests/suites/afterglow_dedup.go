@@ -0,0 +1,74 @@
+package suites
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/stackrox/collector/integration-tests/pkg/collector"
+	"github.com/stackrox/collector/integration-tests/pkg/config"
+	"github.com/stackrox/collector/integration-tests/pkg/types"
+)
+
+const (
+	afterglowDedupServer = "afterglow-dedup-server"
+	afterglowDedupClient = "afterglow-dedup-client"
+)
+
+// AfterglowDedupTestSuite checks the core afterglow guarantee: the same
+// connection, opened repeatedly within one afterglow period, is reported
+// once rather than once per open.
+type AfterglowDedupTestSuite struct {
+	IntegrationTestSuiteBase
+	AfterglowPeriod int
+	// Window over which the connections are opened, in seconds, shorter
+	// than the afterglow period
+	Window   int
+	serverIP string
+}
+
+func (s *AfterglowDedupTestSuite) SetupSuite() {
+	s.RegisterCleanup(afterglowDedupServer, afterglowDedupClient)
+
+	collectorOptions := collector.StartupOptions{
+		Config: map[string]any{
+			// the scrape interval is also the network reporting interval
+			"scrapeInterval": 2,
+		},
+		Env: map[string]string{
+			"ROX_AFTERGLOW_PERIOD": strconv.Itoa(s.AfterglowPeriod),
+			"ROX_ENABLE_AFTERGLOW": "true",
+		},
+	}
+
+	s.StartCollector(false, &collectorOptions)
+
+	serverImage := config.Images().ImageByKey("nginx")
+	clientImage := config.Images().QaImageByKey("qa-alpine-curl")
+	s.Require().NoError(s.Executor().PullImage(serverImage))
+	s.Require().NoError(s.Executor().PullImage(clientImage))
+
+	_, err := s.launchContainer(afterglowDedupServer, serverImage)
+	s.Require().NoError(err)
+
+	_, err = s.launchContainer(afterglowDedupClient, clientImage, "sleep", "300")
+	s.Require().NoError(err)
+
+	s.serverIP, err = s.getIPAddress(afterglowDedupServer)
+	s.Require().NoError(err)
+}
+
+func (s *AfterglowDedupTestSuite) TearDownSuite() {
+	s.StopCollector()
+	s.cleanupContainers(afterglowDedupServer, afterglowDedupClient)
+	s.WritePerfResults()
+}
+
+func (s *AfterglowDedupTestSuite) TestConnectionDeduped() {
+	s.Require().Less(s.Window, s.AfterglowPeriod)
+
+	serverAddress := fmt.Sprintf("%s:80", s.serverIP)
+	s.ExpectConnectionDedupedWithin(afterglowDedupClient, serverAddress, func(conn types.NetworkInfo) bool {
+		return conn.RemoteAddress == serverAddress && conn.Role == "ROLE_CLIENT" && conn.IsActive()
+	}, time.Duration(s.Window)*time.Second)
+}
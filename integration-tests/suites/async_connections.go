@@ -58,7 +58,8 @@ func (s *AsyncConnectionTestSuite) SetupSuite() {
 	s.serverIP, err = s.getIPAddress("server")
 	s.Require().NoError(err)
 
-	common.Sleep(5 * time.Second) // TODO use the endpoint declaration
+	err = s.WaitForCollectorToTrack(s.serverContainer, 30*time.Second)
+	s.Require().NoError(err)
 
 	target := s.serverIP
 
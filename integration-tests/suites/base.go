@@ -8,11 +8,14 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gonum/stat"
+	"github.com/hashicorp/go-multierror"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
+	"golang.org/x/exp/maps"
 
 	"github.com/stackrox/collector/integration-tests/pkg/collector"
 	"github.com/stackrox/collector/integration-tests/pkg/common"
@@ -31,8 +34,21 @@ const (
 	parentExecFilePathStr    = "ParentExecFilePath"
 
 	containerStatsName = "container-stats"
+	tcpdumpName        = "tcpdump"
 
 	defaultWaitTickSeconds = 5 * time.Second
+
+	// scrapeIntervalMargin is added to the scrape interval when waiting
+	// for events, to account for processing and reporting delays.
+	scrapeIntervalMargin = 5 * time.Second
+
+	// runtimeWatchdogThreshold is the number of consecutive failed executor
+	// calls, after which the container runtime is checked.
+	runtimeWatchdogThreshold = 3
+
+	// dedupConnections is the number of times the same connection is opened
+	// by ExpectConnectionDedupedWithin.
+	dedupConnections = 5
 )
 
 type IntegrationTestSuiteBase struct {
@@ -42,8 +58,23 @@ type IntegrationTestSuiteBase struct {
 	sensor    *mock_sensor.MockSensor
 	metrics   map[string]float64
 	stats     []ContainerStat
+	procStats []collector.ProcStats
 	start     time.Time
 	stop      time.Time
+	warmup    time.Duration
+	workload  *WorkloadParams
+	// collapsed stacks artifacts of the perf tools, by tool
+	flamegraphs map[string]string
+	// the collection method collector actually initialized
+	collectionMethod string
+	probeInfo        *collector.ProbeInfo
+	watchdog         *executor.RuntimeWatchdog
+	// set once the container runtime is found to be unavailable
+	runtimeErr error
+	// host directory of the /proc snapshot to mount as collector's /host/proc
+	procSnapshot string
+	// stops exposing the mock sensor on the host running collector
+	stopSensorExposure func()
 }
 
 type ContainerStat struct {
@@ -55,15 +86,21 @@ type ContainerStat struct {
 }
 
 type PerformanceResult struct {
-	TestName         string
-	Timestamp        string
-	InstanceType     string
-	VmConfig         string
-	CollectionMethod string
-	Metrics          map[string]float64
-	ContainerStats   []ContainerStat
-	LoadStartTs      string
-	LoadStopTs       string
+	TestName              string
+	Timestamp             string
+	InstanceType          string
+	VmConfig              string
+	CollectionMethod      string
+	ActualMethod          string
+	Metrics               map[string]float64
+	ContainerStats        []ContainerStat
+	CollectorProcessStats []collector.ProcStats
+	WarmupDuration        string
+	Workload              *WorkloadParams      `json:",omitempty"`
+	Flamegraphs           map[string]string    `json:",omitempty"`
+	Probes                *collector.ProbeInfo `json:",omitempty"`
+	LoadStartTs           string
+	LoadStopTs            string
 }
 
 // StartCollector will start the collector container and optionally
@@ -71,34 +108,40 @@ type PerformanceResult struct {
 func (s *IntegrationTestSuiteBase) StartCollector(disableGRPC bool, options *collector.StartupOptions) {
 	if !disableGRPC {
 		s.Sensor().Start()
+
+		if !config.HostInfo().IsK8s() {
+			stop, err := s.Executor().ExposeToHost(s.Sensor().Port())
+			s.Require().NoError(err)
+			s.stopSensorExposure = stop
+		}
+
+		// collector backs off when it fails to connect, so make sure
+		// it finds the sensor right away
+		s.Require().NoError(s.Sensor().WaitForListening(config.SensorStartupTimeout()))
+	}
+
+	if s.procSnapshot != "" {
+		options = withProcSnapshot(options, s.procSnapshot)
 	}
 
 	s.Require().NoError(s.Collector().Setup(options))
-	s.Require().NoError(s.Collector().Launch())
 
-	// Verify if the image we test has a health check. There are some CI
-	// configurations, where it's not the case. If something went wrong and we
-	// get an error, treat it as no health check was found for the sake of
-	// robustness.
-	hasHealthCheck, err := s.findContainerHealthCheck("collector",
-		s.Collector().ContainerID())
-
-	if hasHealthCheck && err == nil {
-		// Wait for collector to report healthy, includes initial setup and
-		// probes loading. It doesn't make sense to wait for very long, limit
-		// it to 1 min.
-		_, err := s.waitForContainerToBecomeHealthy(
-			"collector",
-			s.Collector().ContainerID(),
-			defaultWaitTickSeconds, 5*time.Minute)
-		s.Require().NoError(err)
-	} else {
-		fmt.Println("No HealthCheck found, do not wait for collector to become healthy")
+	if config.CollectorInfo().PreArguments != "" {
+		// fail early and clearly on invalid arguments, rather than with
+		// a crashing collector later on
+		s.Require().NoError(s.Collector().ValidateCollectorConfig())
+	}
 
-		// No way to figure out when all the services up and running, skip this
-		// phase.
+	s.Require().NoError(s.Collector().Launch())
+
+	if !config.HostInfo().IsK8s() {
+		s.Require().NoError(s.verifyHostMountPropagation())
 	}
 
+	// Wait for collector to report healthy, includes initial setup and
+	// probes loading.
+	s.Require().NoError(s.WaitForCollectorHealthy(5 * time.Minute))
+
 	// wait for the canary process to guarantee collector is started
 	selfCheckOk := s.Sensor().WaitProcessesN(
 		s.Collector().ContainerID(), 30*time.Second, 1, func() {
@@ -106,10 +149,162 @@ func (s *IntegrationTestSuiteBase) StartCollector(disableGRPC bool, options *col
 			// create at least one canary process to make sure everything is
 			// fine.
 			fmt.Println("Spawn a canary process")
-			_, err = s.execContainer("collector", []string{"echo"})
+			_, err := s.execContainer("collector", []string{"echo"})
 			s.Require().NoError(err)
 		})
 	s.Require().True(selfCheckOk)
+
+	method, err := s.Collector().ActualCollectionMethod()
+	if err != nil {
+		fmt.Printf("Unable to determine the collection method in use: %s\n", err)
+	} else if method != config.CollectionMethod() {
+		fmt.Printf("Collector uses the %s collection method rather than the configured %s\n", method, config.CollectionMethod())
+	}
+	s.collectionMethod = method
+
+	s.probeInfo = nil
+	if method == "ebpf" || method == "core-bpf" {
+		probes, err := s.Collector().CollectorProbeInfo()
+		if err != nil {
+			fmt.Printf("Unable to determine the probes collector loaded: %s\n", err)
+		} else {
+			for program, failure := range probes.Failures {
+				fmt.Printf("WARNING: collector failed to load probe %s: %s\n", program, failure)
+			}
+			s.probeInfo = &probes
+		}
+	}
+}
+
+// AssertProbesLoaded checks that no eBPF program failed to load when
+// collector started, and that the expected ones were loaded. Programs are
+// only named in collector's logs at trace level, so expecting specific ones
+// requires that log level.
+func (s *IntegrationTestSuiteBase) AssertProbesLoaded(expected ...string) {
+	if s.probeInfo == nil {
+		s.T().Skip("probe information is only available for eBPF collection methods")
+	}
+
+	s.Assert().Empty(s.probeInfo.Failures, "collector failed to load probes")
+	s.Assert().Empty(s.probeInfo.Missing(expected), "expected probes were not loaded: %v", s.probeInfo.Programs)
+}
+
+// MountProcSnapshot extracts a tarball of a captured /proc tree on the host
+// and mounts it as collector's /host/proc on the next StartCollector, so that
+// collector scrapes the snapshot deterministically, rather than the live
+// host. The tarball must be available on the host running the containers.
+// The returned cleanup removes the extracted tree, and must only be called
+// once collector is stopped.
+func (s *IntegrationTestSuiteBase) MountProcSnapshot(tarPath string) (cleanup func(), err error) {
+	output, err := s.Executor().Exec("mktemp", "-d", "/tmp/proc-snapshot-XXXXXX")
+	if err != nil {
+		return nil, err
+	}
+	dir := strings.TrimSpace(output)
+
+	cleanup = func() {
+		s.Executor().Exec("rm", "-rf", dir)
+		s.procSnapshot = ""
+	}
+
+	if _, err := s.Executor().Exec("tar", "-xf", tarPath, "-C", dir); err != nil {
+		cleanup()
+		return nil, fmt.Errorf("failed to extract /proc snapshot %s: %w", tarPath, err)
+	}
+
+	s.procSnapshot = dir
+	return cleanup, nil
+}
+
+// withProcSnapshot returns a copy of the options, with the /proc snapshot
+// mounted as collector's /host/proc.
+func withProcSnapshot(options *collector.StartupOptions, snapshotDir string) *collector.StartupOptions {
+	snapshotOptions := collector.StartupOptions{}
+	if options != nil {
+		snapshotOptions = *options
+	}
+
+	snapshotOptions.Mounts = map[string]string{}
+	if options != nil {
+		maps.Copy(snapshotOptions.Mounts, options.Mounts)
+	}
+	snapshotOptions.Mounts["/host/proc:ro"] = snapshotDir
+	return &snapshotOptions
+}
+
+// verifyHostMountPropagation checks that the host mounts of the collector
+// container have the expected propagation mode, without which collector
+// silently loses visibility into mounts made on the host after it started.
+// Hosts whose root mount is not shared can't propagate mounts at all, and
+// are not checked, which is logged along with the host's propagation mode.
+func (s *IntegrationTestSuiteBase) verifyHostMountPropagation() error {
+	rootPropagation, err := collector.HostRootMountPropagation(s.Executor())
+	if err != nil {
+		return err
+	}
+	if !collector.PropagationReachesContainers(rootPropagation) {
+		fmt.Printf("WARNING: not verifying the propagation of collector's host mounts, "+
+			"as the host root mount is %s rather than shared\n", rootPropagation)
+		return nil
+	}
+
+	mounts, err := s.Executor().GetContainerMountPropagation(s.Collector().ContainerID())
+	if err != nil {
+		return err
+	}
+
+	for dst, propagation := range mounts {
+		if strings.HasPrefix(dst, collector.HostMountPrefix) && propagation != collector.HostMountPropagation {
+			return fmt.Errorf("collector mount %s has propagation %q (expected %q)",
+				dst, propagation, collector.HostMountPropagation)
+		}
+	}
+	return nil
+}
+
+// WaitForCollectorHealthy waits for the collector container to pass its
+// health check, which queries collector's readiness endpoint rather than
+// relying on its logs. Images without the health check script fall back to
+// the logs, see collector.Manager.WaitForHealthy.
+func (s *IntegrationTestSuiteBase) WaitForCollectorHealthy(timeout time.Duration) error {
+	return s.Collector().WaitForHealthy(timeout)
+}
+
+// WaitForInitialScrape waits until the mock sensor has received the first
+// connections or endpoints collector reported after its initial scrape, see
+// collector.Manager.WaitForInitialScrape.
+func (s *IntegrationTestSuiteBase) WaitForInitialScrape(timeout time.Duration) error {
+	return s.Collector().WaitForInitialScrape(s.Sensor().HasNetworkInfo, timeout)
+}
+
+// ExpectCollectorConnectedToSensor waits for collector to establish its
+// connection to the mock sensor. This tells apart a collector that never
+// connected from one that connected but didn't report the expected signals.
+func (s *IntegrationTestSuiteBase) ExpectCollectorConnectedToSensor(timeout time.Duration) {
+	var sockets []executor.Socket
+	var err error
+
+	deadline := time.Now().Add(timeout)
+	for time.Now().Before(deadline) {
+		sockets, err = s.Executor().GetContainerSockets(s.Collector().ContainerID())
+		s.Require().NoError(err)
+
+		for _, socket := range sockets {
+			if socket.State == "ESTABLISHED" && socket.RemotePort == s.Sensor().Port() {
+				return
+			}
+		}
+		time.Sleep(time.Second)
+	}
+
+	s.Require().FailNow("collector is not connected to the mock sensor", "sockets: %+v", sockets)
+}
+
+// EffectiveCollectorConfig returns the configuration collector logged on
+// startup, i.e. as collector actually parsed it, keyed as collector logs it
+// (e.g. scrape_interval).
+func (s *IntegrationTestSuiteBase) EffectiveCollectorConfig() (map[string]any, error) {
+	return s.Collector().EffectiveCollectorConfig()
 }
 
 // StopCollector will tear down the collector container and stop
@@ -119,6 +314,10 @@ func (s *IntegrationTestSuiteBase) StopCollector() {
 	if s.sensor != nil {
 		s.sensor.Stop()
 	}
+	if s.stopSensorExposure != nil {
+		s.stopSensorExposure()
+		s.stopSensorExposure = nil
+	}
 }
 
 // Collector returns the current collector object, or initializes a new
@@ -148,6 +347,12 @@ func (s *IntegrationTestSuiteBase) Executor() executor.Executor {
 func (s *IntegrationTestSuiteBase) Sensor() *mock_sensor.MockSensor {
 	if s.sensor == nil {
 		s.sensor = mock_sensor.NewMockSensor(s.T().Name())
+		if !config.HostInfo().IsK8s() {
+			s.sensor.SetProcessAliveCheck(func(pid int) bool {
+				_, err := s.Executor().ExecWithoutRetry("test", "-d", fmt.Sprintf("/proc/%d", pid))
+				return err == nil
+			})
+		}
 	}
 	return s.sensor
 }
@@ -167,21 +372,57 @@ func (s *IntegrationTestSuiteBase) AddMetric(key string, value float64) {
 // to cleanup all containers started by a test / suite (provided as args)
 func (s *IntegrationTestSuiteBase) RegisterCleanup(containers ...string) {
 	s.T().Cleanup(func() {
-		// If the test is successful, this clean up function is still run
-		// but everything should be clean already, so this should not fail
-		// if resources are already gone.
-		containers = append(containers, containerStatsName)
-		s.cleanupContainers(containers...)
-
-		// StopCollector is safe when collector isn't running, but the container must exist.
-		// This will ensure that logs are still written even when test setup fails
-		exists, _ := s.Executor().ContainerExists(executor.ContainerFilter{Name: "collector"})
-		if exists {
-			s.StopCollector()
+		if err := s.cleanupSuite(containers, config.VerifyCleanup()); err != nil {
+			s.T().Errorf("Suite did not clean up after itself: %s", err)
+		}
+
+		if zipPath, err := s.BundleArtifacts(); err != nil {
+			fmt.Printf("Failed to bundle artifacts: %s\n", err)
+		} else {
+			fmt.Printf("Bundled artifacts in %s\n", zipPath)
 		}
 	})
 }
 
+// cleanupSuite removes the given containers and stops collector, then
+// verifies that nothing else is left behind if verify is set, before
+// removing any unregistered containers and networks.
+func (s *IntegrationTestSuiteBase) cleanupSuite(containers []string, verify bool) error {
+	// If the test is successful, this clean up function is still run
+	// but everything should be clean already, so this should not fail
+	// if resources are already gone.
+	containers = append(containers, containerStatsName)
+	s.cleanupContainers(containers...)
+
+	// StopCollector is safe when collector isn't running, but the container must exist.
+	// This will ensure that logs are still written even when test setup fails
+	exists, _ := s.Executor().ContainerExists(executor.ContainerFilter{Name: "collector"})
+	if exists {
+		s.StopCollector()
+	}
+
+	// Verified before the safety net below, which would otherwise
+	// remove the leaked resources first.
+	var err error
+	if verify {
+		err = s.VerifyCleanEnvironment()
+	}
+
+	// safety net for containers that were not registered
+	if err := s.Executor().CleanupTracked(); err != nil {
+		fmt.Printf("Failed to clean up tracked containers: %s\n", err)
+	}
+	s.cleanupNetworks()
+	return err
+}
+
+// BundleArtifacts collects the diagnostics of the test (collector logs, mock
+// sensor events, dmesg, perf results and data, core dumps) into a single
+// timestamped zip under the log directory, and returns its path.
+func (s *IntegrationTestSuiteBase) BundleArtifacts() (string, error) {
+	return common.BundleArtifacts(config.LogPath(), s.T().Name(), time.Now())
+}
+
 func (s *IntegrationTestSuiteBase) GetContainerStats() []ContainerStat {
 	if s.stats == nil {
 		s.stats = make([]ContainerStat, 0)
@@ -262,19 +503,80 @@ func (s *IntegrationTestSuiteBase) PrintContainerStats() {
 	}
 }
 
+// SampleCollectorProcessStats periodically records the resource usage of the
+// collector process, until the returned function is called. Samples that fail
+// (e.g. because collector is restarting) are skipped.
+func (s *IntegrationTestSuiteBase) SampleCollectorProcessStats(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				stats, err := s.Collector().CollectorProcessStats()
+				if err != nil {
+					fmt.Printf("Failed to sample collector process stats: %s\n", err)
+					continue
+				}
+				s.procStats = append(s.procStats, stats)
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+func (s *IntegrationTestSuiteBase) printCollectorProcessStats() {
+	if len(s.procStats) == 0 {
+		return
+	}
+
+	maxRSS, maxThreads, maxFDs := 0, 0, 0
+	for _, stats := range s.procStats {
+		maxRSS = max(maxRSS, stats.RSS)
+		maxThreads = max(maxThreads, stats.Threads)
+		maxFDs = max(maxFDs, stats.OpenFDs)
+	}
+
+	s.AddMetric("collector_process_rss_max", float64(maxRSS)/1024)
+	s.AddMetric("collector_process_threads_max", float64(maxThreads))
+	s.AddMetric("collector_process_fds_max", float64(maxFDs))
+
+	fmt.Printf("Collector process: max RSS %v MiB, max threads %d, max FDs %d\n",
+		float64(maxRSS)/1024, maxThreads, maxFDs)
+}
+
 func (s *IntegrationTestSuiteBase) WritePerfResults() {
 	s.PrintContainerStats()
+	s.printCollectorProcessStats()
 
 	perf := PerformanceResult{
-		TestName:         s.T().Name(),
-		Timestamp:        time.Now().Format("2006-01-02 15:04:05"),
-		InstanceType:     config.VMInfo().InstanceType,
-		VmConfig:         config.VMInfo().Config,
-		CollectionMethod: config.CollectionMethod(),
-		Metrics:          s.metrics,
-		ContainerStats:   s.GetContainerStats(),
-		LoadStartTs:      s.start.Format("2006-01-02 15:04:05"),
-		LoadStopTs:       s.stop.Format("2006-01-02 15:04:05"),
+		TestName:              s.T().Name(),
+		Timestamp:             time.Now().Format("2006-01-02 15:04:05"),
+		InstanceType:          config.VMInfo().InstanceType,
+		VmConfig:              config.VMInfo().Config,
+		CollectionMethod:      config.CollectionMethod(),
+		ActualMethod:          s.collectionMethod,
+		Metrics:               s.metrics,
+		ContainerStats:        s.GetContainerStats(),
+		CollectorProcessStats: s.procStats,
+		WarmupDuration:        s.warmup.String(),
+		Workload:              s.workload,
+		Flamegraphs:           s.flamegraphs,
+		Probes:                s.probeInfo,
+		LoadStartTs:           s.start.Format("2006-01-02 15:04:05"),
+		LoadStopTs:            s.stop.Format("2006-01-02 15:04:05"),
 	}
 
 	perfJson, _ := json.Marshal(perf)
@@ -292,13 +594,71 @@ func (s *IntegrationTestSuiteBase) WritePerfResults() {
 func (s *IntegrationTestSuiteBase) AssertProcessInfoEqual(expected, actual types.ProcessInfo) {
 	assert := assert.New(s.T())
 
-	assert.Equal(expected.Name, actual.Name)
-	assert.Equal(expected.ExePath, actual.ExePath)
-	assert.Equal(expected.Uid, actual.Uid)
-	assert.Equal(expected.Gid, actual.Gid)
+	diff := common.DiffProcess(expected, actual)
+	assert.Empty(diff, "process mismatch:\n%s", diff)
 	// Pid is non-deterministic, so just check that it is set
 	assert.True(actual.Pid > 0)
-	assert.Equal(expected.Args, actual.Args)
+}
+
+func (s *IntegrationTestSuiteBase) AssertEndpointInfoEqual(expected, actual types.EndpointInfo) {
+	diff := common.DiffEndpoint(expected, actual)
+	assert.Empty(s.T(), diff, "endpoint mismatch:\n%s", diff)
+}
+
+// ScrapeInterval returns the scrape interval collector has been configured
+// with, which also controls how often network events are reported.
+func (s *IntegrationTestSuiteBase) ScrapeInterval() time.Duration {
+	switch interval := s.Collector().Config()["scrapeInterval"].(type) {
+	case int:
+		return time.Duration(interval) * time.Second
+	case float64:
+		return time.Duration(interval * float64(time.Second))
+	}
+
+	s.FailNow("invalid scrapeInterval in collector configuration")
+	return 0
+}
+
+// ExpectConnectionWithinScrape waits for a connection matching the matcher to
+// be reported, allowing for one scrape interval (plus a margin) to pass.
+func (s *IntegrationTestSuiteBase) ExpectConnectionWithinScrape(containerID string, matcher mock_sensor.ConnectionMatcher) bool {
+	timeout := s.ScrapeInterval() + scrapeIntervalMargin
+	return s.Sensor().ExpectConnectionMatch(s.T(), containerID, timeout, matcher)
+}
+
+// ForEachScrapeInterval runs the scenario as a subtest for each of the scrape
+// intervals (in seconds). Each subtest gets its own collector and mock sensor,
+// configured with that interval on top of the provided options, which are
+// stopped once the scenario returns.
+func (s *IntegrationTestSuiteBase) ForEachScrapeInterval(intervals []int, options collector.StartupOptions, scenario func(interval time.Duration)) {
+	for _, interval := range intervals {
+		s.Run(fmt.Sprintf("scrapeInterval=%ds", interval), func() {
+			// start from scratch, so that the logs and events of each
+			// subtest are kept separately
+			s.collector = nil
+			s.sensor = nil
+
+			collectorConfig := map[string]any{}
+			maps.Copy(collectorConfig, options.Config)
+			collectorConfig["scrapeInterval"] = interval
+			options.Config = collectorConfig
+
+			s.StartCollector(false, &options)
+			defer s.StopCollector()
+
+			scenario(time.Duration(interval) * time.Second)
+		})
+	}
+}
+
+// AssertCollectorUptimeAtLeast fails the test if collector has been running
+// for less than the given duration, which indicates that it was restarted
+// at some point during the test. The collector container and pod are both
+// named "collector", which works with either executor.
+func (s *IntegrationTestSuiteBase) AssertCollectorUptimeAtLeast(d time.Duration) {
+	uptime, err := s.Executor().GetContainerUptime("collector")
+	s.Require().NoError(err)
+	assert.GreaterOrEqual(s.T(), uptime, d, "collector uptime is lower than expected, it may have restarted")
 }
 
 func (s *IntegrationTestSuiteBase) GetLogLines(containerName string) []string {
@@ -309,15 +669,99 @@ func (s *IntegrationTestSuiteBase) GetLogLines(containerName string) []string {
 }
 
 func (s *IntegrationTestSuiteBase) launchContainer(name string, args ...string) (string, error) {
-	cmd := []string{executor.RuntimeCommand, "run", "-d", "--name", name}
+	cmd := []string{executor.RuntimeCommand, "run", "-d", "--name", name,
+		"--label", executor.TestContainerLabel + "=true"}
 	cmd = append(cmd, args...)
 
 	output, err := s.Executor().Exec(cmd...)
+	s.checkRuntime(err)
 
 	outLines := strings.Split(output, "\n")
 	return outLines[len(outLines)-1], err
 }
 
+// LaunchReplicas starts n containers from the same configuration, named
+// after it with an index suffix (see replicaName), and returns their short
+// IDs in order.
+func (s *IntegrationTestSuiteBase) LaunchReplicas(baseConfig executor.ContainerStartConfig, n int) ([]string, error) {
+	ids := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		replica := baseConfig
+		replica.Name = replicaName(baseConfig.Name, i)
+
+		containerID, err := s.Executor().StartContainer(replica)
+		if err != nil {
+			return ids, fmt.Errorf("failed to launch replica %s: %w", replica.Name, err)
+		}
+		ids = append(ids, common.ContainerShortID(containerID))
+	}
+	return ids, nil
+}
+
+// ChurnContainers starts count containers from the same configuration, one
+// after the other, named like replicas (see replicaName), and kills and
+// removes each of them once it has run for the given lifetime. It returns
+// once every container is removed, with the short IDs of the containers
+// that were started, in order.
+func (s *IntegrationTestSuiteBase) ChurnContainers(baseConfig executor.ContainerStartConfig, count int, lifetime time.Duration) ([]string, error) {
+	var wg sync.WaitGroup
+	var mutex sync.Mutex
+	var result *multierror.Error
+
+	ids := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		container := baseConfig
+		container.Name = replicaName(baseConfig.Name, i)
+
+		containerID, err := s.Executor().StartContainer(container)
+		if err != nil {
+			mutex.Lock()
+			result = multierror.Append(result, fmt.Errorf("failed to launch %s: %w", container.Name, err))
+			mutex.Unlock()
+			continue
+		}
+		ids = append(ids, common.ContainerShortID(containerID))
+
+		wg.Add(1)
+		time.AfterFunc(lifetime, func() {
+			defer wg.Done()
+
+			s.Executor().KillContainer(container.Name)
+			if _, err := s.Executor().RemoveContainer(executor.ContainerFilter{Name: container.Name}); err != nil {
+				mutex.Lock()
+				result = multierror.Append(result, fmt.Errorf("failed to remove %s: %w", container.Name, err))
+				mutex.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+
+	return ids, result.ErrorOrNil()
+}
+
+// replicaName returns the name of the i-th replica launched by
+// LaunchReplicas.
+func replicaName(name string, i int) string {
+	return fmt.Sprintf("%s-%d", name, i)
+}
+
+// checkRuntime reports the result of an executor call to the runtime
+// watchdog. If the container runtime is found to be unavailable, the current
+// test and every later one fail with that root cause, instead of the
+// confusing errors of each executor call.
+func (s *IntegrationTestSuiteBase) checkRuntime(err error) {
+	if s.runtimeErr == nil {
+		if s.watchdog == nil {
+			s.watchdog = executor.NewRuntimeWatchdog(s.Executor(), runtimeWatchdogThreshold)
+		}
+		s.runtimeErr = s.watchdog.Observe(err)
+	}
+
+	if s.runtimeErr != nil {
+		s.Require().FailNow(s.runtimeErr.Error())
+	}
+}
+
 // Wait for a container to become a certain status.
 //   - tickSeconds -- how often to check for the status
 //   - timeoutThreshold -- the overall time limit for waiting,
@@ -370,80 +814,199 @@ func (s *IntegrationTestSuiteBase) waitForContainerStatus(
 	}
 }
 
-// Find a HealthCheck section in the specified container and verify it's what
-// we expect. This function would be used to wait until the health check
-// reports healthy, so be conservative and report true only if absolutely
-// certain.
-func (s *IntegrationTestSuiteBase) findContainerHealthCheck(
+func (s *IntegrationTestSuiteBase) waitForContainerToExit(
 	containerName string,
-	containerID string) (bool, error) {
+	containerID string,
+	tickSeconds time.Duration,
+	timeoutThreshold time.Duration) (bool, error) {
 
-	cmd := []string{
-		executor.RuntimeCommand, "inspect", "-f",
-		"'{{ .Config.Healthcheck }}'", containerID,
+	return s.waitForContainerStatus(containerName, containerID, tickSeconds,
+		timeoutThreshold, "status=exited")
+}
+
+func (s *IntegrationTestSuiteBase) execContainer(containerName string, command []string) (string, error) {
+	output, err := s.Executor().ExecContainer(containerName, command)
+	s.checkRuntime(err)
+	return output, err
+}
+
+// execContainerOpts runs a command in a running container, with control over
+// its environment, working directory, user and stdin.
+func (s *IntegrationTestSuiteBase) execContainerOpts(containerName string, opts executor.ExecOptions) (string, error) {
+	output, err := s.Executor().ExecContainerOpts(containerName, opts)
+	s.checkRuntime(err)
+	return output, err
+}
+
+func (s *IntegrationTestSuiteBase) execContainerShellScript(containerName string, shell string, script string, args ...string) (string, error) {
+	return s.execContainerOpts(containerName, executor.ExecOptions{
+		Cmd:   append([]string{shell, "-s"}, args...),
+		Stdin: script,
+	})
+}
+
+// trackingProcessName is the process WaitForCollectorToTrack spawns to
+// trigger a signal for a container.
+const trackingProcessName = "echo"
+
+// WaitForCollectorToTrack waits until collector has reported any signal
+// (process, connection or endpoint) for the container, which shows that the
+// container is in view. A trivial process is spawned in the container to
+// trigger a signal, so the container must be running.
+//
+// Each attempt adds a trackingProcessName process without arguments to the
+// container's reported processes, so this must not be used for containers
+// whose processes are counted, unless those are excluded from the count.
+func (s *IntegrationTestSuiteBase) WaitForCollectorToTrack(containerID string, timeout time.Duration) error {
+	containerID = common.ContainerShortID(containerID)
+
+	timer := time.After(timeout)
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		if len(s.Sensor().Processes(containerID)) > 0 ||
+			len(s.Sensor().Connections(containerID)) > 0 ||
+			len(s.Sensor().Endpoints(containerID)) > 0 {
+			return nil
+		}
+
+		_, err := s.Executor().ExecWithoutRetry(executor.RuntimeCommand, "exec", containerID, trackingProcessName)
+		if err != nil {
+			fmt.Printf("Failed to spawn a process in %s: %s\n", containerID, err)
+		}
+
+		select {
+		case <-timer:
+			return fmt.Errorf("timed out waiting for collector to track container %s", containerID)
+		case <-ticker.C:
+		}
 	}
+}
 
-	output, err := s.Executor().Exec(cmd...)
-	if err != nil {
-		return false, err
+// GenerateConnections opens exactly count new connections from the client
+// container to the target (anything curl accepts, e.g. host:port), one at a
+// time. Each connection is completed before waiting for interval and opening
+// the next one. Failed attempts are not retried, to avoid extra connections.
+func (s *IntegrationTestSuiteBase) GenerateConnections(clientContainer string, target string, count int, interval time.Duration) error {
+	for i := 0; i < count; i++ {
+		if i > 0 {
+			time.Sleep(interval)
+		}
+
+		_, err := s.Executor().ExecWithoutRetry(executor.RuntimeCommand, "exec", clientContainer,
+			"curl", "--silent", "--output", "/dev/null", target)
+		if err != nil {
+			return fmt.Errorf("connection %d of %d failed: %w", i+1, count, err)
+		}
 	}
+	return nil
+}
 
-	outLines := strings.Split(output, "\n")
-	lastLine := outLines[len(outLines)-1]
+// ExpectConnectionDedupedWithin opens the same connection from the client
+// container to the target repeatedly within the window, which must be
+// shorter than collector's afterglow period, and asserts collector reports
+// it exactly once, across a further report interval.
+func (s *IntegrationTestSuiteBase) ExpectConnectionDedupedWithin(clientContainer string, target string, matcher mock_sensor.ConnectionMatcher, window time.Duration) bool {
+	err := s.GenerateConnections(clientContainer, target, dedupConnections, window/dedupConnections)
+	s.Require().NoError(err)
 
-	// Clearly no HealthCheck section
-	if lastLine == "<nil>" {
-		return false, nil
+	clientID := s.Executor().ContainerID(executor.ContainerFilter{Name: clientContainer})
+	if !s.Sensor().ExpectConnectionMatch(s.T(), clientID, s.ScrapeInterval()+scrapeIntervalMargin, matcher) {
+		return false
 	}
 
-	// If doesn't contain an expected command, do not consider it to be a valid
-	// health check
-	if strings.Contains(lastLine, "CMD-SHELL /usr/local/bin/status-check.sh") {
-		return true, nil
-	} else {
-		return false, nil
+	// a connection that isn't deduplicated is reported again by the
+	// following report
+	common.Sleep(s.ScrapeInterval() + scrapeIntervalMargin)
+
+	return s.Assert().Equal(1, s.Sensor().CountConnections(clientID, matcher),
+		"connection reported more than once: %+v", s.Sensor().ConnectionEvents(clientID))
+}
+
+func (s *IntegrationTestSuiteBase) cleanupContainers(containers ...string) {
+	for _, container := range containers {
+		s.warnLeakedExecSessions(container)
+		s.Executor().KillContainer(container)
+		s.Executor().RemoveContainer(executor.ContainerFilter{Name: container})
 	}
 }
 
-func (s *IntegrationTestSuiteBase) waitForContainerToBecomeHealthy(
-	containerName string,
-	containerID string,
-	tickSeconds time.Duration,
-	timeoutThreshold time.Duration) (bool, error) {
+// warnLeakedExecSessions warns about commands exec'd in the container that
+// are still running, which may have skewed the measurements of the test.
+// The container may be gone already, so errors are ignored.
+func (s *IntegrationTestSuiteBase) warnLeakedExecSessions(container string) {
+	sessions, err := s.Executor().GetContainerExecSessions(container)
+	if err != nil {
+		return
+	}
 
-	return s.waitForContainerStatus(containerName, containerID, tickSeconds,
-		timeoutThreshold, "health=healthy")
+	for _, session := range sessions {
+		fmt.Printf("WARNING: exec'd process still running in %s: %d %s\n", container, session.Pid, session.Command)
+	}
 }
 
-func (s *IntegrationTestSuiteBase) waitForContainerToExit(
-	containerName string,
-	containerID string,
-	tickSeconds time.Duration,
-	timeoutThreshold time.Duration) (bool, error) {
+// cleanupNetworks removes any networks created by the tests, including
+// those leaked by earlier failed runs.
+func (s *IntegrationTestSuiteBase) cleanupNetworks() {
+	if config.HostInfo().IsK8s() {
+		return
+	}
 
-	return s.waitForContainerStatus(containerName, containerID, tickSeconds,
-		timeoutThreshold, "status=exited")
+	err := executor.RemoveNetworksByLabel(s.Executor(), executor.TestNetworkLabel)
+	if err != nil {
+		fmt.Printf("Failed to clean up test networks: %s\n", err)
+	}
 }
 
-func (s *IntegrationTestSuiteBase) execContainer(containerName string, command []string) (string, error) {
-	cmd := []string{executor.RuntimeCommand, "exec", containerName}
-	cmd = append(cmd, command...)
+// VerifyCleanEnvironment checks that no resources of the tests are left
+// behind, i.e. containers and networks labeled as created by the tests.
+// RegisterCleanup runs it once the registered containers are removed, but
+// before removing any others itself. Images are not checked, as the tests
+// only pull images shared by all suites and never build their own.
+func (s *IntegrationTestSuiteBase) VerifyCleanEnvironment() error {
+	if config.HostInfo().IsK8s() {
+		return nil
+	}
 
-	return s.Executor().Exec(cmd...)
-}
+	leaked := []string{}
+	containers, err := s.Executor().ListContainers("label=" + executor.TestContainerLabel)
+	if err != nil {
+		return fmt.Errorf("failed to list containers: %w", err)
+	}
+	for _, name := range containers {
+		leaked = append(leaked, "container "+name)
+	}
 
-func (s *IntegrationTestSuiteBase) execContainerShellScript(containerName string, shell string, script string, args ...string) (string, error) {
-	cmd := []string{executor.RuntimeCommand, "exec", "-i", containerName, shell, "-s"}
-	cmd = append(cmd, args...)
+	networks, err := s.Executor().ListNetworks("label=" + executor.TestNetworkLabel)
+	if err != nil {
+		return fmt.Errorf("failed to list networks: %w", err)
+	}
+	for _, network := range networks {
+		leaked = append(leaked, "network "+network.Name)
+	}
 
-	return s.Executor().ExecWithStdin(script, cmd...)
+	if len(leaked) > 0 {
+		return fmt.Errorf("resources left behind: %s", strings.Join(leaked, ", "))
+	}
+	return nil
 }
 
-func (s *IntegrationTestSuiteBase) cleanupContainers(containers ...string) {
+// CleanupAll stops and removes every named container. A failure for one
+// container (e.g. because it is already gone) does not prevent the others
+// from being cleaned up, and all errors are returned together.
+func (s *IntegrationTestSuiteBase) CleanupAll(containers ...string) error {
+	var result error
 	for _, container := range containers {
-		s.Executor().KillContainer(container)
-		s.Executor().RemoveContainer(executor.ContainerFilter{Name: container})
+		if _, err := s.Executor().StopContainer(container); err != nil {
+			result = multierror.Append(result, fmt.Errorf("failed to stop %s: %w", container, err))
+		}
+
+		if _, err := s.Executor().RemoveContainer(executor.ContainerFilter{Name: container}); err != nil {
+			result = multierror.Append(result, fmt.Errorf("failed to remove %s: %w", container, err))
+		}
 	}
+	return result
 }
 
 func (s *IntegrationTestSuiteBase) stopContainers(containers ...string) {
@@ -459,7 +1022,21 @@ func (s *IntegrationTestSuiteBase) removeContainers(containers ...string) {
 }
 
 func (s *IntegrationTestSuiteBase) containerLogs(containerName string) (string, error) {
-	return s.Executor().Exec(executor.RuntimeCommand, "logs", containerName)
+	logs, err := s.Executor().Exec(executor.RuntimeCommand, "logs", containerName)
+	s.checkRuntime(err)
+	return logs, err
+}
+
+// ContainerLogMatcher follows the logs of a running container so that a test
+// can register expected and forbidden patterns and wait for them while the
+// container runs. Waiting fails as soon as a forbidden pattern is logged.
+// The caller must Stop the matcher once done.
+func (s *IntegrationTestSuiteBase) ContainerLogMatcher(containerID string) (*common.LogMatcher, error) {
+	logs, err := s.Executor().FollowContainerLogs(containerID)
+	if err != nil {
+		return nil, err
+	}
+	return common.NewLogMatcher(logs), nil
 }
 
 func (s *IntegrationTestSuiteBase) getIPAddress(containerName string) (string, error) {
@@ -471,6 +1048,7 @@ func (s *IntegrationTestSuiteBase) getIPAddress(containerName string) (string, e
 	}
 
 	stdoutStderr, err := s.Executor().Exec(args...)
+	s.checkRuntime(err)
 	return strings.Replace(string(stdoutStderr), "'", "", -1), err
 }
 
@@ -500,6 +1078,41 @@ func (s *IntegrationTestSuiteBase) getPort(containerName string) (string, error)
 	return "", fmt.Errorf("no port mapping found: %v %v", rawString, portMap)
 }
 
+// PullImages pulls the images concurrently, up to the configured number at a
+// time, and returns the errors of all failed pulls. Each pull is retried on
+// transient failures.
+func (s *IntegrationTestSuiteBase) PullImages(refs ...string) error {
+	return pullConcurrently(s.Executor().PullImage, refs, config.ImagePullConcurrency())
+}
+
+// pullConcurrently calls pull for each of the images, with up to limit
+// calls in flight.
+func pullConcurrently(pull func(string) error, refs []string, limit int) error {
+	var result error
+	var resultMutex sync.Mutex
+	var wg sync.WaitGroup
+
+	slots := make(chan struct{}, limit)
+	for _, ref := range refs {
+		wg.Add(1)
+		slots <- struct{}{}
+
+		go func(ref string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			if err := pull(ref); err != nil {
+				resultMutex.Lock()
+				result = multierror.Append(result, fmt.Errorf("failed to pull %s: %w", ref, err))
+				resultMutex.Unlock()
+			}
+		}(ref)
+	}
+
+	wg.Wait()
+	return result
+}
+
 func (s *IntegrationTestSuiteBase) StartContainerStats() {
 	image := config.Images().QaImageByKey("performance-stats")
 	args := []string{"-v", executor.RuntimeSocket + ":/var/run/docker.sock", image}
@@ -511,6 +1124,70 @@ func (s *IntegrationTestSuiteBase) StartContainerStats() {
 	s.Require().NoError(err)
 }
 
+// CapturePackets captures the TCP traffic on the given container network for
+// the duration, using a tcpdump sidecar on the host, and returns the local
+// path of the resulting pcap file (see common.ParsePcapConnections), which is
+// copied back from the host into the test's logs. This blocks until the
+// capture is complete, so the workload must run concurrently.
+func (s *IntegrationTestSuiteBase) CapturePackets(network string, duration time.Duration) (string, error) {
+	iface, err := s.networkInterface(network)
+	if err != nil {
+		return "", err
+	}
+
+	image := config.Images().ImageByKey("netshoot")
+	if err := s.Executor().PullImage(image); err != nil {
+		return "", err
+	}
+
+	pcapPath := filepath.Join("/tmp", fmt.Sprintf("%s-%d.pcap", network, time.Now().Unix()))
+	containerID, err := s.launchContainer(tcpdumpName,
+		"--privileged", "--network", "host", "-v", "/tmp:/tmp", image,
+		"timeout", strconv.Itoa(int(duration.Seconds())),
+		"tcpdump", "-i", iface, "-U", "-w", pcapPath, "tcp")
+	if err != nil {
+		return "", err
+	}
+	defer s.cleanupContainers(tcpdumpName)
+
+	if _, err := s.waitForContainerToExit(tcpdumpName, containerID, time.Second, duration+30*time.Second); err != nil {
+		return "", err
+	}
+	defer s.Executor().Exec("rm", "-f", pcapPath)
+
+	if err := os.MkdirAll(config.LogPath(), os.ModePerm); err != nil {
+		return "", err
+	}
+	localPath := filepath.Join(config.LogPath(),
+		strings.ReplaceAll(s.T().Name(), "/", "_")+"-"+filepath.Base(pcapPath))
+	if _, err := s.Executor().CopyFromHost(pcapPath, localPath); err != nil {
+		return "", fmt.Errorf("failed to copy %s from the host: %w", pcapPath, err)
+	}
+
+	return localPath, nil
+}
+
+// networkInterface returns the name of the host bridge interface that
+// backs a container network.
+func (s *IntegrationTestSuiteBase) networkInterface(network string) (string, error) {
+	output, err := s.Executor().Exec(executor.RuntimeCommand, "network", "inspect", network,
+		"--format='{{.Id}} {{index .Options \"com.docker.network.bridge.name\"}}'")
+	if err != nil {
+		return "", err
+	}
+
+	fields := strings.Fields(strings.Trim(output, "'"))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("unable to inspect network %s", network)
+	}
+
+	if len(fields) > 1 && fields[1] != "<no value>" {
+		// explicitly named bridge, e.g. docker0 for the default network
+		return fields[1], nil
+	}
+	return "br-" + common.ContainerShortID(fields[0]), nil
+}
+
 func (s *IntegrationTestSuiteBase) waitForFileToBeDeleted(file string) error {
 	timer := time.After(10 * time.Second)
 	ticker := time.NewTicker(time.Second)
@@ -0,0 +1,178 @@
+package suites
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stackrox/collector/integration-tests/pkg/collector"
+	"github.com/stackrox/collector/integration-tests/pkg/executor"
+)
+
+type fakeContainerExecutor struct {
+	executor.Executor
+	containers map[string]bool
+}
+
+func (f *fakeContainerExecutor) StopContainer(name string) (string, error) {
+	if !f.containers[name] {
+		return "", fmt.Errorf("no such container: %s", name)
+	}
+	return "", nil
+}
+
+func (f *fakeContainerExecutor) RemoveContainer(cf executor.ContainerFilter) (string, error) {
+	if !f.containers[cf.Name] {
+		return "", fmt.Errorf("no such container: %s", cf.Name)
+	}
+	delete(f.containers, cf.Name)
+	return "", nil
+}
+
+func TestCleanupAllWithMissingContainer(t *testing.T) {
+	fake := &fakeContainerExecutor{
+		containers: map[string]bool{"first": true, "third": true},
+	}
+	s := IntegrationTestSuiteBase{executor: fake}
+
+	err := s.CleanupAll("first", "second", "third")
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "second")
+	assert.Empty(t, fake.containers, "remaining containers were not removed")
+}
+
+func TestWithProcSnapshot(t *testing.T) {
+	options := &collector.StartupOptions{
+		Mounts: map[string]string{"/data": "/tmp/data"},
+	}
+
+	snapshotOptions := withProcSnapshot(options, "/tmp/proc-snapshot")
+
+	assert.Equal(t, map[string]string{
+		"/data":         "/tmp/data",
+		"/host/proc:ro": "/tmp/proc-snapshot",
+	}, snapshotOptions.Mounts)
+	assert.Equal(t, map[string]string{"/data": "/tmp/data"}, options.Mounts)
+
+	assert.Equal(t, map[string]string{"/host/proc:ro": "/tmp/proc-snapshot"},
+		withProcSnapshot(nil, "/tmp/proc-snapshot").Mounts)
+}
+
+func TestPullConcurrently(t *testing.T) {
+	var mutex sync.Mutex
+	inFlight, maxInFlight := 0, 0
+
+	pull := func(ref string) error {
+		mutex.Lock()
+		inFlight++
+		maxInFlight = max(maxInFlight, inFlight)
+		mutex.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		mutex.Lock()
+		inFlight--
+		mutex.Unlock()
+
+		if ref == "broken" {
+			return fmt.Errorf("manifest unknown")
+		}
+		return nil
+	}
+
+	err := pullConcurrently(pull, []string{"a", "b", "broken", "c", "d", "e"}, 2)
+	assert.ErrorContains(t, err, "failed to pull broken: manifest unknown")
+	assert.Equal(t, 2, maxInFlight)
+
+	assert.NoError(t, pullConcurrently(pull, []string{"a", "b"}, 3))
+}
+
+func TestScaleWorkload(t *testing.T) {
+	processes := "restart_interval = 10\nduration = 60\n\n[workload]\ntype = \"processes\"\n" +
+		"arrival_rate = 200.0\ndeparture_rate = 200.0\nrandom_process = true\n"
+
+	scaled, err := scaleWorkload(processes, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, "restart_interval = 10\nduration = 60\n\n[workload]\ntype = \"processes\"\n"+
+		"arrival_rate = 600.0\ndeparture_rate = 600.0\nrandom_process = true\n", scaled)
+
+	scaled, err = scaleWorkload("[workload]\ntype = \"endpoints\"\nn_ports = 8000\nexponent = 1.4\n", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "[workload]\ntype = \"endpoints\"\nn_ports = 16000\nexponent = 1.4\n", scaled)
+
+	_, err = scaleWorkload("n_ports = many\n", 2)
+	assert.Error(t, err)
+}
+
+// fakeCleanupExecutor holds labeled test containers, of which those in
+// tracked were started through StartContainer.
+type fakeCleanupExecutor struct {
+	executor.Executor
+	containers map[string]bool
+	tracked    []string
+}
+
+func (f *fakeCleanupExecutor) GetContainerExecSessions(containerID string) ([]executor.ExecSession, error) {
+	return nil, nil
+}
+
+func (f *fakeCleanupExecutor) KillContainer(name string) (string, error) {
+	return "", nil
+}
+
+func (f *fakeCleanupExecutor) RemoveContainer(cf executor.ContainerFilter) (string, error) {
+	delete(f.containers, cf.Name)
+	return "", nil
+}
+
+func (f *fakeCleanupExecutor) ContainerExists(cf executor.ContainerFilter) (bool, error) {
+	return f.containers[cf.Name], nil
+}
+
+func (f *fakeCleanupExecutor) ListContainers(filter string) ([]string, error) {
+	names := []string{}
+	for name := range f.containers {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (f *fakeCleanupExecutor) ListNetworks(filter string) ([]executor.NetworkInfo, error) {
+	return nil, nil
+}
+
+func (f *fakeCleanupExecutor) CleanupTracked() error {
+	for _, name := range f.tracked {
+		delete(f.containers, name)
+	}
+	return nil
+}
+
+func TestCleanupSuite(t *testing.T) {
+	// a suite whose registered containers are still running at cleanup
+	clean := &fakeCleanupExecutor{
+		containers: map[string]bool{"nginx": true, "nginx-curl": true},
+	}
+	s := IntegrationTestSuiteBase{executor: clean}
+
+	assert.NoError(t, s.cleanupSuite([]string{"nginx", "nginx-curl"}, true))
+	assert.Empty(t, clean.containers)
+
+	leaky := &fakeCleanupExecutor{
+		containers: map[string]bool{"nginx": true, "unregistered": true},
+		tracked:    []string{"unregistered"},
+	}
+	s = IntegrationTestSuiteBase{executor: leaky}
+
+	err := s.cleanupSuite([]string{"nginx"}, true)
+	assert.ErrorContains(t, err, "container unregistered")
+	assert.Empty(t, leaky.containers, "the leaked container was not removed")
+
+	leaky.containers["unregistered"] = true
+	assert.NoError(t, s.cleanupSuite([]string{"nginx"}, false))
+	assert.Empty(t, leaky.containers)
+}
@@ -1,16 +1,23 @@
 package suites
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/shlex"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slices"
 
 	"github.com/stackrox/collector/integration-tests/pkg/common"
 	"github.com/stackrox/collector/integration-tests/pkg/config"
+	"github.com/stackrox/collector/integration-tests/pkg/executor"
 )
 
 type BenchmarkBaselineTestSuite struct {
@@ -22,6 +29,10 @@ type BenchmarkCollectorTestSuite struct {
 	workloads []string
 }
 
+// flamegraphDataDir is where the perf tools write their data, shared with
+// the host.
+const flamegraphDataDir = "/tmp"
+
 type BenchmarkTestSuiteBase struct {
 	IntegrationTestSuiteBase
 	perfContainers []string
@@ -137,6 +148,121 @@ func (b *BenchmarkTestSuiteBase) StopPerfTools() {
 
 	b.removeContainers(b.perfContainers...)
 	b.perfContainers = nil
+
+	benchmark_options := config.BenchmarksInfo()
+	tools := map[string]string{
+		"perf":     benchmark_options.PerfCommand,
+		"bpftrace": benchmark_options.BpftraceCommand,
+		"bcc":      benchmark_options.BccCommand,
+	}
+
+	for tool, command := range tools {
+		if command == "" {
+			continue
+		}
+
+		path, err := b.CollectFlamegraphData(tool)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			fmt.Printf("Failed to collect %s flamegraph data: %s\n", tool, err)
+			continue
+		}
+		fmt.Printf("Wrote %s flamegraph data to %s\n", tool, path)
+	}
+}
+
+// CollectFlamegraphData converts the profiling data a perf tool left in the
+// shared /tmp mount into collapsed stacks, ready to be rendered as a
+// flamegraph, and stores them alongside the perf results. perf is expected
+// to record to /tmp/perf.data, while bpftrace and bcc are expected to write
+// already folded stacks to /tmp/<tool>.folded. If the tool produced no data,
+// the returned error wraps fs.ErrNotExist.
+func (b *BenchmarkTestSuiteBase) CollectFlamegraphData(tool string) (path string, err error) {
+	var stacks []string
+
+	switch tool {
+	case "perf":
+		// perf script runs on the host, so the data need not be copied
+		dataPath, err := b.hostFlamegraphData("perf.data")
+		if err != nil {
+			return "", err
+		}
+
+		output, err := b.Executor().Exec(executor.RuntimeCommand, "run", "--rm",
+			"--privileged",
+			"-v", "/tmp:/tmp",
+			config.Images().QaImageByKey("performance-perf"),
+			"script", "-i", dataPath)
+		if err != nil {
+			return "", err
+		}
+
+		stacks, err = common.CollapsePerfScript(strings.NewReader(output))
+		if err != nil {
+			return "", err
+		}
+	case "bpftrace", "bcc":
+		data, err := b.copyFlamegraphData(tool + ".folded")
+		if err != nil {
+			return "", err
+		}
+		stacks = strings.Split(strings.TrimSpace(data), "\n")
+	default:
+		return "", fmt.Errorf("unsupported perf tool: %s", tool)
+	}
+
+	logFile, err := common.PrepareLog(b.T().Name(), tool+".folded")
+	if err != nil {
+		return "", err
+	}
+	defer logFile.Close()
+
+	_, err = logFile.WriteString(strings.Join(stacks, "\n") + "\n")
+	if err != nil {
+		return "", err
+	}
+
+	if b.flamegraphs == nil {
+		b.flamegraphs = map[string]string{}
+	}
+	b.flamegraphs[tool] = logFile.Name()
+	return logFile.Name(), nil
+}
+
+// hostFlamegraphData checks that a perf tool left the named file in the
+// shared /tmp mount, on the host running the containers, and returns its
+// path there. If there is no such file, the error wraps fs.ErrNotExist.
+func (b *BenchmarkTestSuiteBase) hostFlamegraphData(name string) (string, error) {
+	hostPath := filepath.Join(flamegraphDataDir, name)
+	if _, err := b.Executor().ExecWithoutRetry("test", "-f", hostPath); err != nil {
+		return "", fmt.Errorf("%s: %w", hostPath, fs.ErrNotExist)
+	}
+	return hostPath, nil
+}
+
+// copyFlamegraphData copies the named file a perf tool left in the shared
+// /tmp mount back from the host, and returns its content.
+func (b *BenchmarkTestSuiteBase) copyFlamegraphData(name string) (string, error) {
+	hostPath, err := b.hostFlamegraphData(name)
+	if err != nil {
+		return "", err
+	}
+
+	localFile, err := os.CreateTemp("", "flamegraph-*-"+name)
+	if err != nil {
+		return "", err
+	}
+	localFile.Close()
+	defer os.Remove(localFile.Name())
+
+	if _, err := b.Executor().CopyFromHost(hostPath, localFile.Name()); err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(localFile.Name())
+	return string(data), err
 }
 
 func (s *BenchmarkCollectorTestSuite) SetupSuite() {
@@ -149,7 +275,55 @@ func (s *BenchmarkCollectorTestSuite) SetupSuite() {
 	s.StartCollector(false, nil)
 }
 
-func (s *BenchmarkTestSuiteBase) SpinBerserker(workload string) (string, error) {
+// WorkloadParams control the berserker workloads, so that benchmark runs
+// are comparable.
+type WorkloadParams struct {
+	// Scale multiplies the amount of work, zero keeps the default
+	Scale int
+}
+
+// scaledWorkloadKeys are the settings of the berserker workloads which
+// control the amount of work, and are multiplied by the workload scale.
+var scaledWorkloadKeys = []string{"arrival_rate", "departure_rate", "n_ports"}
+
+// workloadParams returns the configured workload parameters.
+func workloadParams() WorkloadParams {
+	return WorkloadParams{
+		Scale: config.BenchmarksInfo().WorkloadScale,
+	}
+}
+
+// scaleWorkload multiplies the settings of a berserker workload
+// configuration which control the amount of work by the scale, keeping
+// floats as floats, which berserker requires for rates.
+func scaleWorkload(workload string, scale int) (string, error) {
+	lines := strings.Split(workload, "\n")
+	for i, line := range lines {
+		key, value, found := strings.Cut(line, "=")
+		if !found || !slices.Contains(scaledWorkloadKeys, strings.TrimSpace(key)) {
+			continue
+		}
+
+		value = strings.TrimSpace(value)
+		if strings.Contains(value, ".") {
+			f, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				return "", fmt.Errorf("invalid %s in workload: %w", strings.TrimSpace(key), err)
+			}
+			value = strconv.FormatFloat(f*float64(scale), 'f', 1, 64)
+		} else {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return "", fmt.Errorf("invalid %s in workload: %w", strings.TrimSpace(key), err)
+			}
+			value = strconv.Itoa(n * scale)
+		}
+		lines[i] = key + "= " + value
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+func (s *BenchmarkTestSuiteBase) SpinBerserker(workload string, params WorkloadParams) (string, error) {
 	benchmarkName := fmt.Sprintf("benchmark-%s", workload)
 	benchmarkImage := config.Images().QaImageByKey("performance-berserker")
 
@@ -159,7 +333,17 @@ func (s *BenchmarkTestSuiteBase) SpinBerserker(workload string) (string, error)
 	}
 
 	configFile := fmt.Sprintf("/etc/berserker/%s/workload.toml", workload)
-	benchmarkArgs := []string{benchmarkImage, configFile}
+	benchmarkArgs := []string{}
+	if params.Scale > 0 {
+		scaledFile, err := s.scaledWorkloadFile(benchmarkImage, configFile, params.Scale)
+		if err != nil {
+			return "", err
+		}
+		// the mount keeps the file available to the container once removed
+		defer s.Executor().Exec("rm", "-f", scaledFile)
+		benchmarkArgs = append(benchmarkArgs, "-v", scaledFile+":"+configFile+":ro")
+	}
+	benchmarkArgs = append(benchmarkArgs, benchmarkImage, configFile)
 
 	containerID, err := s.launchContainer(benchmarkName, benchmarkArgs...)
 	if err != nil {
@@ -170,15 +354,76 @@ func (s *BenchmarkTestSuiteBase) SpinBerserker(workload string) (string, error)
 	return containerID, nil
 }
 
+// scaledWorkloadFile writes a scaled copy of a workload configuration of the
+// berserker image to a file on the host, and returns its path.
+func (s *BenchmarkTestSuiteBase) scaledWorkloadFile(image string, configFile string, scale int) (string, error) {
+	workload, err := s.Executor().Exec(executor.RuntimeCommand, "run", "--rm", "--entrypoint", "cat", image, configFile)
+	if err != nil {
+		return "", err
+	}
+
+	scaled, err := scaleWorkload(workload, scale)
+	if err != nil {
+		return "", err
+	}
+
+	output, err := s.Executor().Exec("mktemp", "/tmp/berserker-XXXXXX")
+	if err != nil {
+		return "", err
+	}
+	path := strings.TrimSpace(output)
+
+	_, err = s.Executor().ExecWithStdin(scaled, "sh", "-c", "cat > "+path)
+	return path, err
+}
+
+// RunWarmup runs the benchmark workloads for the given duration and then
+// discards them, along with any stats gathered while they were running, so
+// that the measured run is not skewed by cold caches.
+func (s *BenchmarkTestSuiteBase) RunWarmup(duration time.Duration, params WorkloadParams) {
+	fmt.Printf("Warming up for %s\n", duration)
+
+	procContainerID, err := s.SpinBerserker("processes", params)
+	s.Require().NoError(err)
+
+	endpointsContainerID, err := s.SpinBerserker("endpoints", params)
+	s.Require().NoError(err)
+
+	common.Sleep(duration)
+
+	s.cleanupContainers(procContainerID, endpointsContainerID)
+	s.loadContainers = nil
+
+	// restart the stats container, so that only the measured run is reported
+	s.cleanupContainers(containerStatsName)
+	s.StartContainerStats()
+
+	s.warmup = duration
+}
+
 func (s *BenchmarkTestSuiteBase) RunCollectorBenchmark() {
-	procContainerID, err := s.SpinBerserker("processes")
+	params := workloadParams()
+	fmt.Printf("Workload scale %d\n", params.Scale)
+	s.workload = &params
+
+	if warmup := config.BenchmarksInfo().WarmupDuration; warmup > 0 {
+		s.RunWarmup(warmup, params)
+	}
+
+	procContainerID, err := s.SpinBerserker("processes", params)
 	s.Require().NoError(err)
 
-	endpointsContainerID, err := s.SpinBerserker("endpoints")
+	endpointsContainerID, err := s.SpinBerserker("endpoints", params)
 	s.Require().NoError(err)
 
 	s.start = time.Now().UTC()
 
+	// The baseline runs without collector, so there is nothing to sample
+	if s.collector != nil {
+		stopSampling := s.SampleCollectorProcessStats(5 * time.Second)
+		defer stopSampling()
+	}
+
 	// The assumption is that the benchmark is short, and to get better
 	// resolution into when relevant metrics start and stop, tick more
 	// frequently
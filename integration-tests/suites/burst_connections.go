@@ -0,0 +1,103 @@
+package suites
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/stackrox/collector/integration-tests/pkg/collector"
+	"github.com/stackrox/collector/integration-tests/pkg/common"
+	"github.com/stackrox/collector/integration-tests/pkg/config"
+)
+
+const (
+	burstServerName = "burst-server"
+	burstClientName = "burst-client"
+	burstFirstPort  = 9000
+)
+
+// BurstConnectionsTestSuite opens a burst of connections in well under a
+// second, each to a different port so that they are reported as distinct
+// connections, and checks that collector reports at least a minimum fraction
+// of them within the reporting window. This characterizes collector's drop
+// behavior under burst load, which steady workloads don't exercise.
+type BurstConnectionsTestSuite struct {
+	IntegrationTestSuiteBase
+	// Connections is the number of connections in the burst
+	Connections int
+	// MinReportedRatio is the fraction of the burst that must be reported
+	MinReportedRatio float64
+	// AfterglowPeriod in seconds, which together with the scrape interval
+	// bounds the time for the connections to be reported
+	AfterglowPeriod int
+
+	clientContainer string
+}
+
+func (s *BurstConnectionsTestSuite) SetupSuite() {
+	s.RegisterCleanup(burstServerName, burstClientName)
+	s.StartContainerStats()
+
+	collectorOptions := collector.StartupOptions{
+		Env: map[string]string{
+			"ROX_AFTERGLOW_PERIOD": strconv.Itoa(s.AfterglowPeriod),
+			"ROX_ENABLE_AFTERGLOW": "true",
+		},
+	}
+
+	s.StartCollector(false, &collectorOptions)
+
+	image := config.Images().QaImageByKey("qa-socat")
+	s.Require().NoError(s.Executor().PullImage(image))
+
+	lastPort := burstFirstPort + s.Connections - 1
+	serverScript := fmt.Sprintf(
+		"for port in $(seq %d %d); do socat -u TCP-LISTEN:$port,fork,reuseaddr OPEN:/dev/null & done; wait",
+		burstFirstPort, lastPort)
+	_, err := s.launchContainer(burstServerName, "--entrypoint", "/bin/sh", image, "-c", serverScript)
+	s.Require().NoError(err)
+
+	containerID, err := s.launchContainer(burstClientName, "--entrypoint", "/bin/sh", image, "-c", "sleep 300")
+	s.Require().NoError(err)
+	s.clientContainer = common.ContainerShortID(containerID)
+
+	s.Require().NoError(s.WaitForCollectorToTrack(s.clientContainer, 30*time.Second))
+}
+
+func (s *BurstConnectionsTestSuite) TearDownSuite() {
+	s.StopCollector()
+	s.cleanupContainers(burstServerName, burstClientName)
+	s.WritePerfResults()
+}
+
+func (s *BurstConnectionsTestSuite) TestBurstReportCompleteness() {
+	serverIP, err := s.getIPAddress(burstServerName)
+	s.Require().NoError(err)
+
+	lastPort := burstFirstPort + s.Connections - 1
+	// all connections are opened in parallel, to complete the burst as
+	// quickly as possible
+	burstScript := fmt.Sprintf(
+		"for port in $(seq %d %d); do echo burst | socat -u STDIN TCP4:%s:$port & done; wait",
+		burstFirstPort, lastPort, serverIP)
+
+	start := time.Now()
+	_, err = s.execContainer(burstClientName, []string{"/bin/sh", "-c", burstScript})
+	s.Require().NoError(err)
+	fmt.Printf("Opened %d connections in %s\n", s.Connections, time.Since(start))
+
+	// give collector the time to report every connection, but stop as soon
+	// as they all are
+	window := time.Duration(s.AfterglowPeriod)*time.Second + s.ScrapeInterval() + scrapeIntervalMargin
+	deadline := time.Now().Add(window)
+	for time.Now().Before(deadline) && s.Sensor().ConnectionCount(s.clientContainer) < s.Connections {
+		time.Sleep(time.Second)
+	}
+
+	reported := s.Sensor().ConnectionCount(s.clientContainer)
+	ratio := float64(reported) / float64(s.Connections)
+	s.AddMetric("burst_reported_ratio", ratio)
+
+	s.Assert().GreaterOrEqual(ratio, s.MinReportedRatio,
+		"collector reported %d of %d connections of the burst", reported, s.Connections)
+}
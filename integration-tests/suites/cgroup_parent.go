@@ -0,0 +1,51 @@
+package suites
+
+import (
+	"time"
+
+	"github.com/stackrox/collector/integration-tests/pkg/common"
+	"github.com/stackrox/collector/integration-tests/pkg/fixtures"
+	"github.com/stackrox/collector/integration-tests/pkg/types"
+)
+
+const cgroupParentContainer = "cgroup-parent"
+
+// CgroupParentTestSuite checks that collector resolves the container of
+// processes whose cgroup is nested under a custom parent, e.g. a systemd
+// slice or a K8s pod cgroup, rather than directly under the runtime's.
+type CgroupParentTestSuite struct {
+	IntegrationTestSuiteBase
+	// CgroupParent is valid with both the cgroupfs and systemd cgroup
+	// drivers if it is named after a slice
+	CgroupParent string
+	container    string
+}
+
+func (s *CgroupParentTestSuite) SetupSuite() {
+	s.RegisterCleanup(cgroupParentContainer)
+	s.StartCollector(false, nil)
+
+	container := fixtures.HTTPClient(cgroupParentContainer)
+	container.CgroupParent = s.CgroupParent
+	s.Require().NoError(s.Executor().PullImage(container.Image))
+
+	containerID, err := s.Executor().StartContainer(container)
+	s.Require().NoError(err)
+	s.container = common.ContainerShortID(containerID)
+
+	_, err = s.execContainer(cgroupParentContainer, []string{"/bin/uname"})
+	s.Require().NoError(err)
+}
+
+func (s *CgroupParentTestSuite) TearDownSuite() {
+	s.StopCollector()
+	s.cleanupContainers(cgroupParentContainer)
+	s.WritePerfResults()
+}
+
+func (s *CgroupParentTestSuite) TestProcessInNestedCgroup() {
+	s.Sensor().ExpectProcesses(s.T(), s.container, 30*time.Second, types.ProcessInfo{
+		Name:    "uname",
+		ExePath: "/bin/uname",
+	})
+}
@@ -0,0 +1,137 @@
+package suites
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/stackrox/collector/integration-tests/pkg/collector"
+	"github.com/stackrox/collector/integration-tests/pkg/common"
+	"github.com/stackrox/collector/integration-tests/pkg/config"
+	"github.com/stackrox/collector/integration-tests/pkg/mock_sensor"
+)
+
+const (
+	equivalenceServer = "method-equivalence-server"
+	equivalenceClient = "method-equivalence-client"
+)
+
+// CollectionMethodEquivalenceTestSuite runs the same scenario once per
+// collection method, and checks that collector reports the same processes,
+// connections and endpoints regardless of the method in use.
+type CollectionMethodEquivalenceTestSuite struct {
+	IntegrationTestSuiteBase
+	Methods []string
+}
+
+type methodSnapshots struct {
+	client mock_sensor.SignalSnapshot
+	server mock_sensor.SignalSnapshot
+}
+
+func (s *CollectionMethodEquivalenceTestSuite) SetupSuite() {
+	s.RegisterCleanup(equivalenceServer, equivalenceClient)
+
+	s.Require().NoError(s.PullImages(
+		config.Images().ImageByKey("nginx"),
+		config.Images().QaImageByKey("qa-alpine-curl"),
+	))
+}
+
+func (s *CollectionMethodEquivalenceTestSuite) TearDownSuite() {
+	s.cleanupContainers(equivalenceServer, equivalenceClient)
+	s.WritePerfResults()
+}
+
+func (s *CollectionMethodEquivalenceTestSuite) TestSameReports() {
+	s.Require().Len(s.Methods, 2, "exactly two collection methods are compared")
+
+	snapshots := make([]methodSnapshots, 0, len(s.Methods))
+	for _, method := range s.Methods {
+		snapshots = append(snapshots, s.runScenario(method))
+	}
+
+	diff := mock_sensor.DiffSnapshots(snapshots[0].client, snapshots[1].client)
+	diff = append(diff, mock_sensor.DiffSnapshots(snapshots[0].server, snapshots[1].server)...)
+
+	s.Empty(diff, "reports differ between %s (-) and %s (+):\n%s",
+		s.Methods[0], s.Methods[1], strings.Join(diff, "\n"))
+}
+
+// runScenario starts collector with the given collection method, has a client
+// container connect to a server container, and returns the normalized
+// signals reported for both.
+func (s *CollectionMethodEquivalenceTestSuite) runScenario(method string) methodSnapshots {
+	s.StartCollector(false, &collector.StartupOptions{
+		Config: map[string]any{
+			"scrapeInterval": 2,
+		},
+		Env: map[string]string{
+			"COLLECTION_METHOD": method,
+		},
+	})
+	defer func() {
+		s.StopCollector()
+		s.cleanupContainers(equivalenceServer, equivalenceClient)
+	}()
+
+	if s.collectionMethod != method {
+		s.T().Skipf("Collector is not running with %s (got %q), nothing to compare", method, s.collectionMethod)
+	}
+
+	serverID, err := s.launchContainer(equivalenceServer, config.Images().ImageByKey("nginx"))
+	s.Require().NoError(err)
+
+	clientID, err := s.launchContainer(equivalenceClient, config.Images().QaImageByKey("qa-alpine-curl"), "sleep", "300")
+	s.Require().NoError(err)
+
+	serverIP, err := s.getIPAddress(equivalenceServer)
+	s.Require().NoError(err)
+
+	clientIP, err := s.getIPAddress(equivalenceClient)
+	s.Require().NoError(err)
+
+	_, err = s.execContainer(equivalenceClient, []string{"curl", "--connect-timeout", "5", fmt.Sprintf("http://%s/", serverIP)})
+	s.Require().NoError(err)
+
+	common.Sleep(2*time.Second + scrapeIntervalMargin)
+
+	// container IPs may change from one run to the next, so they are
+	// replaced by the container names
+	names := strings.NewReplacer(serverIP, equivalenceServer, clientIP, equivalenceClient)
+
+	return methodSnapshots{
+		client: normalizeSnapshot(s.Sensor().Snapshot(clientID[0:12]), names),
+		server: normalizeSnapshot(s.Sensor().Snapshot(serverID[0:12]), names),
+	}
+}
+
+// normalizeSnapshot blanks out the fields that vary from one run to the
+// next regardless of the collection method, so that snapshots of separate
+// runs can be compared exactly.
+func normalizeSnapshot(snapshot mock_sensor.SignalSnapshot, names *strings.Replacer) mock_sensor.SignalSnapshot {
+	for i := range snapshot.Processes {
+		snapshot.Processes[i].Pid = 0
+	}
+
+	for i, conn := range snapshot.Connections {
+		conn.LocalAddress = names.Replace(conn.LocalAddress)
+		conn.RemoteAddress = names.Replace(conn.RemoteAddress)
+		conn.CloseTimestamp = activeMarker(conn.IsActive())
+		snapshot.Connections[i] = conn
+	}
+
+	for i, endpoint := range snapshot.Endpoints {
+		endpoint.CloseTimestamp = activeMarker(endpoint.IsActive())
+		snapshot.Endpoints[i] = endpoint
+	}
+
+	return snapshot
+}
+
+func activeMarker(active bool) string {
+	if active {
+		return "active"
+	}
+	return "closed"
+}
@@ -1,5 +1,14 @@
 package suites
 
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stackrox/collector/integration-tests/pkg/config"
+	"github.com/stackrox/collector/integration-tests/pkg/executor"
+)
+
 type CollectorStartupTestSuite struct {
 	IntegrationTestSuiteBase
 }
@@ -18,3 +27,36 @@ func (s *CollectorStartupTestSuite) TestCollectorRunning() {
 	s.Require().NoError(err)
 	s.Require().True(running)
 }
+
+func (s *CollectorStartupTestSuite) TestCollectionMethod() {
+	method, err := s.Collector().ActualCollectionMethod()
+	s.Require().NoError(err)
+	s.Require().Equal(config.CollectionMethod(), method, "collector does not use the configured collection method")
+}
+
+func (s *CollectorStartupTestSuite) TestConnectedToSensor() {
+	if config.HostInfo().IsK8s() {
+		s.T().Skip("sockets are not available on K8s")
+	}
+
+	s.ExpectCollectorConnectedToSensor(30 * time.Second)
+}
+
+func (s *CollectorStartupTestSuite) TestCapabilities() {
+	if config.HostInfo().IsK8s() {
+		s.T().Skip("capabilities are not available on K8s")
+	}
+
+	effective, privileged, err := s.Executor().GetContainerCapabilities(s.Collector().ContainerID())
+	s.Require().NoError(err)
+	s.Require().True(privileged, "collector is expected to be privileged")
+
+	// a privileged container has every capability the host kernel supports,
+	// and collector does not drop any of them
+	output, err := s.Executor().Exec("cat", "/proc/sys/kernel/cap_last_cap")
+	s.Require().NoError(err)
+	lastCap, err := strconv.Atoi(strings.TrimSpace(output))
+	s.Require().NoError(err)
+
+	s.Require().ElementsMatch(executor.AllCapabilities(lastCap), effective)
+}
@@ -8,7 +8,7 @@ import (
 
 	"github.com/stackrox/collector/integration-tests/pkg/collector"
 	"github.com/stackrox/collector/integration-tests/pkg/common"
-	"github.com/stackrox/collector/integration-tests/pkg/config"
+	"github.com/stackrox/collector/integration-tests/pkg/fixtures"
 	"github.com/stackrox/collector/integration-tests/pkg/types"
 	"github.com/stretchr/testify/assert"
 )
@@ -44,16 +44,16 @@ func (s *ConnectionsAndEndpointsTestSuite) SetupSuite() {
 
 	s.StartCollector(false, &collectorOptions)
 
-	socatImage := config.Images().QaImageByKey("qa-socat")
-
 	serverName := s.Server.Name
 	clientName := s.Client.Name
 
-	longContainerID, err := s.launchContainer(serverName, "--entrypoint", "/bin/sh", socatImage, "-c", "/bin/sleep 300")
-	s.Server.ContainerID = common.ContainerShortID(longContainerID)
+	// both containers are idle until the commands of the server and the
+	// client are exec'd in them
+	longContainerID, err := s.Executor().StartContainer(fixtures.SocatClient(serverName))
 	s.Require().NoError(err)
+	s.Server.ContainerID = common.ContainerShortID(longContainerID)
 
-	longContainerID, err = s.launchContainer(clientName, "--entrypoint", "/bin/sh", socatImage, "-c", "/bin/sleep 300")
+	longContainerID, err = s.Executor().StartContainer(fixtures.SocatClient(clientName))
 	s.Require().NoError(err)
 	s.Client.ContainerID = common.ContainerShortID(longContainerID)
 
@@ -100,14 +100,23 @@ func (s *ConnectionsAndEndpointsTestSuite) TestConnectionsAndEndpoints() {
 		assert.Equal(s.T(), expectedRemoteAddress, lastNetwork.RemoteAddress)
 		assert.Equal(s.T(), "ROLE_CLIENT", lastNetwork.Role)
 		assert.Equal(s.T(), lastExpectedNetwork.SocketFamily, lastNetwork.SocketFamily)
+
+		// beyond the last connection, each expected port must be reported
+		byPort := s.Sensor().ConnectionsByRemotePort(s.Client.ContainerID)
+		for _, expected := range s.Client.ExpectedNetwork {
+			expected.RemoteAddress = strings.Replace(expected.RemoteAddress, "SERVER_IP", s.Server.IP, -1)
+			assert.NotEmpty(s.T(), byPort[expected.RemotePort()],
+				"no connection reported to port %d: %+v", expected.RemotePort(), clientNetworks)
+		}
 	}
 
 	if s.Client.ExpectedEndpoints != nil {
 		fmt.Println("Expected client endpoint should be nil")
 	}
 
-	endpoints := s.Sensor().Endpoints(s.Client.ContainerID)
-	assert.Equal(s.T(), 0, len(endpoints))
+	// the client is not listening, so none of its sockets should be
+	// reported as endpoints across a full scrape
+	s.Sensor().ExpectNoEndpoints(s.T(), s.Client.ContainerID, s.ScrapeInterval())
 
 	// TODO If ExpectedNetwork is nil the test should check that it is actually nil
 	if s.Server.ExpectedNetwork != nil {
@@ -133,15 +142,47 @@ func (s *ConnectionsAndEndpointsTestSuite) TestConnectionsAndEndpoints() {
 	if s.Server.ExpectedEndpoints != nil {
 		assert.Equal(s.T(), len(s.Server.ExpectedEndpoints), len(serverEndpoints))
 
+		// serverEndpoints are already sorted by the sensor
 		types.SortEndpoints(s.Server.ExpectedEndpoints)
-		types.SortEndpoints(serverEndpoints)
 
 		for idx := range serverEndpoints {
 			assert.Equal(s.T(), s.Server.ExpectedEndpoints[idx].Protocol, serverEndpoints[idx].Protocol)
 			assert.Equal(s.T(), s.Server.ExpectedEndpoints[idx].Address, serverEndpoints[idx].Address)
 		}
+
+		expectedByPort := make(map[int]int)
+		for _, expected := range s.Server.ExpectedEndpoints {
+			expectedByPort[expected.Address.Port]++
+		}
+		endpointsByPort := s.Sensor().EndpointsByPort(s.Server.ContainerID)
+		for port, count := range expectedByPort {
+			assert.Len(s.T(), endpointsByPort[port], count, "unexpected endpoints on port %d", port)
+		}
+		assert.Len(s.T(), endpointsByPort, len(expectedByPort), "endpoints on unexpected ports")
 	} else {
 		assert.Equal(s.T(), 0, len(serverEndpoints))
 	}
 
+	// a forking socat keeps listening in the server after serving the
+	// client, while one that doesn't exits along with the socat of the
+	// client, once the message is sent. The shells and tools exec'd to
+	// drive the test are ignored.
+	expectedListeners := 0
+	if strings.Contains(s.Server.Cmd, "fork") {
+		expectedListeners = 1
+	}
+	assert.Equal(s.T(), expectedListeners, s.longLivedSocats(s.Server.ContainerID), "unexpected running socat in the server")
+	assert.Equal(s.T(), 0, s.longLivedSocats(s.Client.ContainerID), "unexpected running socat in the client")
+}
+
+// longLivedSocats returns the number of distinct socat processes still
+// running in the given container.
+func (s *ConnectionsAndEndpointsTestSuite) longLivedSocats(containerID string) int {
+	count := 0
+	for _, process := range s.Sensor().DistinctLongLivedProcesses(containerID, time.Second) {
+		if process.Name == "socat" {
+			count++
+		}
+	}
+	return count
 }
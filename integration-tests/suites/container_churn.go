@@ -0,0 +1,85 @@
+package suites
+
+import (
+	"time"
+
+	"github.com/stackrox/collector/integration-tests/pkg/common"
+	"github.com/stackrox/collector/integration-tests/pkg/config"
+	"github.com/stackrox/collector/integration-tests/pkg/executor"
+)
+
+const (
+	churnContainerName = "churn"
+
+	// the fraction of the churned containers whose processes must be
+	// reported, as the shortest lived ones may be gone before collector
+	// resolves them
+	churnMinReportedRatio = 0.8
+
+	// how much the RSS of collector may grow over the churn, in KiB
+	churnMaxRSSGrowth = 64 * 1024
+)
+
+// ContainerChurnTestSuite creates and destroys short-lived containers in a
+// tight loop, to stress the handling of container add and remove events,
+// which the suites with long-lived containers never exercise. It checks
+// that collector survives, does not leak memory, and reports the processes
+// of most of the ephemeral containers.
+type ContainerChurnTestSuite struct {
+	IntegrationTestSuiteBase
+	Count int
+	// Lifetime of each container, in seconds
+	Lifetime int
+}
+
+func (s *ContainerChurnTestSuite) SetupSuite() {
+	names := []string{}
+	for i := 0; i < s.Count; i++ {
+		names = append(names, replicaName(churnContainerName, i))
+	}
+	s.RegisterCleanup(names...)
+	s.StartContainerStats()
+
+	s.StartCollector(false, nil)
+
+	s.Require().NoError(s.Executor().PullImage(config.Images().ImageByKey("busybox")))
+}
+
+func (s *ContainerChurnTestSuite) TearDownSuite() {
+	s.StopCollector()
+	s.WritePerfResults()
+}
+
+func (s *ContainerChurnTestSuite) TestContainerChurn() {
+	stopSampling := s.SampleCollectorProcessStats(2 * time.Second)
+
+	ids, err := s.ChurnContainers(executor.ContainerStartConfig{
+		Name:       churnContainerName,
+		Image:      config.Images().ImageByKey("busybox"),
+		Entrypoint: []string{"sh"},
+		Command:    []string{"-c", "ls / > /dev/null; sleep 300"},
+	}, s.Count, time.Duration(s.Lifetime)*time.Second)
+
+	common.Sleep(s.ScrapeInterval() + scrapeIntervalMargin)
+	stopSampling()
+	s.Require().NoError(err)
+
+	running, err := s.Executor().IsContainerRunning("collector")
+	s.Require().NoError(err)
+	s.Require().True(running, "collector is not running after the churn")
+
+	if s.Assert().GreaterOrEqual(len(s.procStats), 2, "too few collector stats samples") {
+		growth := s.procStats[len(s.procStats)-1].RSS - s.procStats[0].RSS
+		s.Assert().LessOrEqual(growth, churnMaxRSSGrowth,
+			"collector RSS grew by %d KiB over the churn", growth)
+	}
+
+	reported := 0
+	for _, id := range ids {
+		if len(s.Sensor().Processes(id)) > 0 {
+			reported++
+		}
+	}
+	s.Assert().GreaterOrEqual(float64(reported), churnMinReportedRatio*float64(len(ids)),
+		"processes reported for %d of %d churned containers", reported, len(ids))
+}
@@ -0,0 +1,53 @@
+package suites
+
+import (
+	"regexp"
+	"time"
+
+	"github.com/stackrox/collector/integration-tests/pkg/collector"
+)
+
+// driverSetupError is the error collector logs when it fails to set up the
+// driver for its collection method.
+var driverSetupError = regexp.MustCompile(`Failed to setup \S+ driver\.`)
+
+// DebugfsUnavailableTestSuite launches collector without /sys/kernel/debug
+// mounted, as on locked-down hosts, and checks that it runs normally. Every
+// collection method is backed by the CO-RE BPF driver, which attaches its
+// programs without going through debugfs, so a driver setup failure (logged
+// as driverSetupError) is a regression.
+type DebugfsUnavailableTestSuite struct {
+	IntegrationTestSuiteBase
+}
+
+func (s *DebugfsUnavailableTestSuite) SetupSuite() {
+	s.RegisterCleanup()
+
+	s.Sensor().Start()
+	s.Require().NoError(s.Collector().Setup(&collector.StartupOptions{
+		WithoutDebugfs: true,
+	}))
+	s.Require().NoError(s.Collector().Launch())
+}
+
+func (s *DebugfsUnavailableTestSuite) TearDownSuite() {
+	s.Require().NoError(s.Collector().TearDown())
+	s.Sensor().Stop()
+	s.WritePerfResults()
+}
+
+func (s *DebugfsUnavailableTestSuite) TestMissingDebugfs() {
+	if err := s.WaitForCollectorHealthy(5 * time.Minute); err != nil {
+		logs, logsErr := s.containerLogs("collector")
+		s.Require().NoError(logsErr)
+		s.Require().NotRegexp(driverSetupError, logs,
+			"collector could not set up its driver without debugfs")
+		s.Require().NoError(err, "collector did not become healthy without debugfs")
+	}
+
+	reported := s.Sensor().WaitProcessesN(s.Collector().ContainerID(), 30*time.Second, 1, func() {
+		_, err := s.execContainer("collector", []string{"echo"})
+		s.Require().NoError(err)
+	})
+	s.Assert().True(reported, "collector did not report processes without debugfs")
+}
@@ -0,0 +1,118 @@
+package suites
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/stackrox/collector/integration-tests/pkg/common"
+	"github.com/stackrox/collector/integration-tests/pkg/config"
+	"github.com/stackrox/collector/integration-tests/pkg/executor"
+	"github.com/stackrox/collector/integration-tests/pkg/fixtures"
+	"github.com/stackrox/collector/integration-tests/pkg/types"
+)
+
+const (
+	dnsServerName = "dns-server"
+	dnsTargetName = "dns-target"
+	dnsClientName = "dns-client"
+
+	dnsDomain   = "collector.test"
+	dnsHostname = "target"
+)
+
+// corefile is the CoreDNS configuration, which resolves the target's
+// hostname to the IP address of the target container.
+const corefile = `. {
+	hosts {
+		%s %s.%s
+	}
+}
+`
+
+// DNSTestSuite checks that a connection made by name, through a controlled
+// DNS server, is reported with the resolved IP address.
+type DNSTestSuite struct {
+	IntegrationTestSuiteBase
+	configDir       string
+	targetIP        string
+	clientContainer string
+}
+
+func (s *DNSTestSuite) SetupSuite() {
+	s.RegisterCleanup(dnsServerName, dnsTargetName, dnsClientName)
+	s.StartContainerStats()
+	s.StartCollector(false, nil)
+
+	target := fixtures.NginxServer(dnsTargetName)
+	client := fixtures.HTTPClient(dnsClientName)
+	dnsImage := config.Images().ImageByKey("coredns")
+	s.Require().NoError(s.PullImages(target.Image, dnsImage, client.Image))
+
+	_, err := s.Executor().StartContainer(target)
+	s.Require().NoError(err)
+
+	s.targetIP, err = s.getIPAddress(dnsTargetName)
+	s.Require().NoError(err)
+
+	// the configuration is mounted from the host running the containers,
+	// and must be readable by coredns, which does not run as root
+	output, err := s.Executor().Exec("mktemp", "-d", "/tmp/coredns-XXXXXX")
+	s.Require().NoError(err)
+	s.configDir = strings.TrimSpace(output)
+	corefilePath := path.Join(s.configDir, "Corefile")
+	_, err = s.Executor().ExecWithStdin(fmt.Sprintf(corefile, s.targetIP, dnsHostname, dnsDomain),
+		"sh", "-c", "cat > "+corefilePath)
+	s.Require().NoError(err)
+	_, err = s.Executor().Exec("chmod", "0755", s.configDir)
+	s.Require().NoError(err)
+	_, err = s.Executor().Exec("chmod", "0644", corefilePath)
+	s.Require().NoError(err)
+
+	_, err = s.Executor().StartContainer(executor.ContainerStartConfig{
+		Name:    dnsServerName,
+		Image:   dnsImage,
+		Mounts:  map[string]string{"/etc/coredns": s.configDir},
+		Command: []string{"-conf", "/etc/coredns/Corefile"},
+	})
+	s.Require().NoError(err)
+
+	dnsIP, err := s.getIPAddress(dnsServerName)
+	s.Require().NoError(err)
+
+	client.DNS = []string{dnsIP}
+	client.DNSSearch = []string{dnsDomain}
+	containerID, err := s.Executor().StartContainer(client)
+	s.Require().NoError(err)
+	s.clientContainer = common.ContainerShortID(containerID)
+
+	// the connection is only meaningful if the name is resolved by our
+	// DNS server, so check the configuration took effect in the container
+	resolvConf, err := s.Executor().GetContainerResolvConf(dnsClientName)
+	s.Require().NoError(err)
+	nameservers, search := executor.ParseResolvConf(resolvConf)
+	s.Require().Equal([]string{dnsIP}, nameservers)
+	s.Require().Contains(search, dnsDomain)
+
+	err = s.WaitForCollectorToTrack(s.clientContainer, 30*time.Second)
+	s.Require().NoError(err)
+
+	// resolved through the search domain
+	_, err = s.execContainer(dnsClientName, fixtures.HTTPRequest(dnsHostname))
+	s.Require().NoError(err)
+}
+
+func (s *DNSTestSuite) TearDownSuite() {
+	s.StopCollector()
+	s.cleanupContainers(dnsServerName, dnsTargetName, dnsClientName)
+	s.Executor().Exec("rm", "-rf", s.configDir)
+	s.WritePerfResults()
+}
+
+func (s *DNSTestSuite) TestConnectionToResolvedAddress() {
+	s.ExpectConnectionWithinScrape(s.clientContainer, func(conn types.NetworkInfo) bool {
+		return conn.RemoteAddress == fmt.Sprintf("%s:%d", s.targetIP, fixtures.NginxPort) &&
+			conn.Role == "ROLE_CLIENT"
+	})
+}
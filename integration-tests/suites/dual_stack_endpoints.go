@@ -0,0 +1,67 @@
+package suites
+
+import (
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stackrox/collector/integration-tests/pkg/collector"
+	"github.com/stackrox/collector/integration-tests/pkg/common"
+	"github.com/stackrox/collector/integration-tests/pkg/config"
+)
+
+const dualStackContainerName = "dual-stack-server"
+
+// DualStackEndpointsTestSuite checks that IPv4 and IPv6 listeners on the
+// same port are reported as distinct endpoints, each with its own family.
+type DualStackEndpointsTestSuite struct {
+	IntegrationTestSuiteBase
+	container string
+}
+
+func (s *DualStackEndpointsTestSuite) SetupSuite() {
+	s.RegisterCleanup(dualStackContainerName)
+	s.StartContainerStats()
+
+	collectorOptions := collector.StartupOptions{
+		Env: map[string]string{
+			"ROX_PROCESSES_LISTENING_ON_PORT": "true",
+		},
+		Config: map[string]any{
+			"turnOffScrape": false,
+		},
+	}
+
+	s.StartCollector(false, &collectorOptions)
+
+	image := config.Images().QaImageByKey("qa-socat")
+	err := s.Executor().PullImage(image)
+	s.Require().NoError(err)
+
+	// IPv6 is disabled in containers by default
+	containerID, err := s.launchContainer(dualStackContainerName,
+		"--sysctl", "net.ipv6.conf.all.disable_ipv6=0",
+		"--entrypoint", "/bin/sh", image, "-c",
+		"socat TCP4-LISTEN:8080,reuseaddr,fork - & socat TCP6-LISTEN:8080,ipv6only=1,reuseaddr,fork - & wait")
+	s.Require().NoError(err)
+	s.container = common.ContainerShortID(containerID)
+}
+
+func (s *DualStackEndpointsTestSuite) TearDownSuite() {
+	s.StopCollector()
+	s.cleanupContainers(dualStackContainerName)
+	s.WritePerfResults()
+}
+
+func (s *DualStackEndpointsTestSuite) TestBothFamiliesReported() {
+	endpoints := s.Sensor().ExpectEndpointsN(s.T(), s.container, 30*time.Second, 2)
+
+	families := []string{}
+	for _, endpoint := range endpoints {
+		assert.Equal(s.T(), 8080, endpoint.Address.Port)
+		families = append(families, endpoint.SocketFamily)
+	}
+
+	assert.ElementsMatch(s.T(), []string{"SOCKET_FAMILY_IPV4", "SOCKET_FAMILY_IPV6"}, families)
+	assert.False(s.T(), endpoints[0].Equal(endpoints[1]), "IPv4 and IPv6 endpoints must not compare equal")
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/stackrox/collector/integration-tests/pkg/collector"
 	"github.com/stackrox/collector/integration-tests/pkg/common"
 	"github.com/stackrox/collector/integration-tests/pkg/config"
+	"github.com/stackrox/collector/integration-tests/pkg/types"
 )
 
 const (
@@ -31,7 +32,7 @@ func (s *DuplicateEndpointsTestSuite) killSocatProcess(port int) {
 }
 
 func (s *DuplicateEndpointsTestSuite) SetupSuite() {
-	s.RegisterCleanup("socat")
+	s.RegisterCleanup("socat", "socat-stable")
 	s.StartContainerStats()
 
 	collectorOptions := collector.StartupOptions{
@@ -49,7 +50,7 @@ func (s *DuplicateEndpointsTestSuite) SetupSuite() {
 
 func (s *DuplicateEndpointsTestSuite) TearDownSuite() {
 	s.StopCollector()
-	s.cleanupContainers("socat")
+	s.cleanupContainers("socat", "socat-stable")
 	s.WritePerfResults()
 }
 
@@ -110,3 +111,19 @@ func (s *DuplicateEndpointsTestSuite) TestDuplicateEndpoints() {
 	// additional final check to ensure there are no additional reports
 	s.Assert().Len(s.Sensor().Processes(containerID), 8, "Got more processes than expected")
 }
+
+// While TestDuplicateEndpoints checks that repeated opens of an endpoint are
+// deduplicated, this checks that a single long-lived endpoint isn't reported
+// again by each of the following scrapes.
+func (s *DuplicateEndpointsTestSuite) TestStableEndpointReportedOnce() {
+	image := config.Images().QaImageByKey("qa-socat")
+	containerID, err := s.launchContainer("socat-stable", image, "TCP-LISTEN:8080,fork", "STDOUT")
+	s.Require().NoError(err)
+	containerID = common.ContainerShortID(containerID)
+
+	s.Sensor().ExpectEndpointsN(s.T(), containerID, 2*gScrapeInterval*time.Second, 1)
+
+	s.Sensor().ExpectEndpointReportedOnce(s.T(), containerID, func(endpoint types.EndpointInfo) bool {
+		return endpoint.Address.Port == 8080 && endpoint.IsActive()
+	}, 3*gScrapeInterval*time.Second)
+}
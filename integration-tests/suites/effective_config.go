@@ -0,0 +1,128 @@
+package suites
+
+import (
+	"encoding/json"
+
+	"github.com/stackrox/collector/integration-tests/pkg/collector"
+	"github.com/stackrox/collector/integration-tests/pkg/config"
+)
+
+const (
+	effectiveScrapeInterval = 4
+	flagsScrapeInterval     = 6
+)
+
+// expectedProbes are the eBPF programs collector is expected to load for
+// each collection method: the syscall dispatchers, and the process exit and
+// scheduler switch tracepoints it always requests. This collector only
+// ships the CO-RE driver, which it also loads when ebpf is configured.
+var expectedProbes = map[string][]string{
+	config.CollectionMethodCoreBPF: {"sys_enter", "sys_exit", "sched_proc_exit", "sched_switch"},
+	config.CollectionMethodEBPF:    {"sys_enter", "sys_exit", "sched_proc_exit", "sched_switch"},
+}
+
+// EffectiveConfigTestSuite checks that the configuration passed to collector
+// actually took effect, by comparing it to the configuration collector logs
+// on startup.
+type EffectiveConfigTestSuite struct {
+	IntegrationTestSuiteBase
+}
+
+func (s *EffectiveConfigTestSuite) SetupSuite() {
+	s.RegisterCleanup()
+
+	collectorOptions := collector.StartupOptions{
+		Config: map[string]any{
+			// the loaded eBPF programs are only logged at trace level
+			"logLevel":       "trace",
+			"scrapeInterval": effectiveScrapeInterval,
+			"turnOffScrape":  false,
+		},
+		Env: map[string]string{
+			"ROX_ENABLE_AFTERGLOW": "false",
+		},
+	}
+
+	s.StartCollector(false, &collectorOptions)
+}
+
+func (s *EffectiveConfigTestSuite) TearDownSuite() {
+	s.StopCollector()
+}
+
+func (s *EffectiveConfigTestSuite) TestEffectiveConfig() {
+	effective, err := s.EffectiveCollectorConfig()
+	s.Require().NoError(err)
+
+	s.assertEffectiveValue(effective, "scrape_interval", effectiveScrapeInterval)
+	s.assertEffectiveValue(effective, "turn_off_scrape", false)
+	s.assertEffectiveValue(effective, "enable_afterglow", false)
+	s.assertEffectiveValue(effective, "collection_method", config.CollectionMethod())
+}
+
+func (s *EffectiveConfigTestSuite) TestProbesLoaded() {
+	s.AssertProbesLoaded(expectedProbes[s.collectionMethod]...)
+}
+
+// assertEffectiveValue checks a value of the effective configuration.
+// collector logs booleans as 0 or 1, so expected booleans are compared
+// as such if needed.
+func (s *IntegrationTestSuiteBase) assertEffectiveValue(effective map[string]any, key string, expected any) {
+	actual, ok := effective[key]
+	if !s.Assert().True(ok, "%s is missing from the effective configuration: %v", key, effective) {
+		return
+	}
+
+	if b, isBool := expected.(bool); isBool {
+		if _, isInt := actual.(int); isInt {
+			expected = 0
+			if b {
+				expected = 1
+			}
+		}
+	}
+	s.Assert().Equal(expected, actual, "%s did not take effect in collector", key)
+}
+
+// CollectorFlagsTestSuite checks that configuration passed on collector's
+// command line takes effect, replacing the configuration in the
+// environment rather than being merged with it.
+type CollectorFlagsTestSuite struct {
+	IntegrationTestSuiteBase
+}
+
+func (s *CollectorFlagsTestSuite) SetupSuite() {
+	s.RegisterCleanup()
+
+	flagsConfig, err := json.Marshal(map[string]any{
+		"logLevel":       config.CollectorInfo().LogLevel,
+		"scrapeInterval": flagsScrapeInterval,
+	})
+	s.Require().NoError(err)
+
+	// turnOffScrape is only set in the environment, and is expected to
+	// be dropped in favor of collector's default (false).
+	collectorOptions := collector.StartupOptions{
+		Config: map[string]any{
+			"scrapeInterval": effectiveScrapeInterval,
+			"turnOffScrape":  true,
+		},
+		CollectorFlags: map[string]string{
+			"collector-config": string(flagsConfig),
+		},
+	}
+
+	s.StartCollector(false, &collectorOptions)
+}
+
+func (s *CollectorFlagsTestSuite) TearDownSuite() {
+	s.StopCollector()
+}
+
+func (s *CollectorFlagsTestSuite) TestFlagsTakeEffect() {
+	effective, err := s.EffectiveCollectorConfig()
+	s.Require().NoError(err)
+
+	s.assertEffectiveValue(effective, "scrape_interval", flagsScrapeInterval)
+	s.assertEffectiveValue(effective, "turn_off_scrape", false)
+}
@@ -0,0 +1,99 @@
+package suites
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/stackrox/collector/integration-tests/pkg/collector"
+	"github.com/stackrox/collector/integration-tests/pkg/common"
+	"github.com/stackrox/collector/integration-tests/pkg/config"
+)
+
+const (
+	endpointRebindName = "endpoint-rebind"
+	endpointRebindPort = 9090
+)
+
+// rebindScript opens a listener on the port and closes it again, the given
+// number of times, keeping each state for the given number of seconds.
+const rebindScript = `for i in $(seq %d); do
+	socat TCP-LISTEN:%d,reuseaddr - &
+	pid=$!
+	sleep %d
+	kill $pid
+	wait $pid
+	sleep %[3]d
+done
+`
+
+// EndpointRebindTestSuite checks that a port bound and unbound repeatedly,
+// as fast as scraping can observe it, is reported as opened and closed once
+// per rebind, and does not leave a phantom endpoint behind: once the port is
+// no longer listened on, its last reported state must be closed.
+type EndpointRebindTestSuite struct {
+	IntegrationTestSuiteBase
+	Rebinds   int
+	container string
+}
+
+func (s *EndpointRebindTestSuite) SetupSuite() {
+	s.RegisterCleanup(endpointRebindName)
+	s.StartContainerStats()
+
+	collectorOptions := collector.StartupOptions{
+		Config: map[string]any{
+			"turnOffScrape": false,
+		},
+		Env: map[string]string{
+			"ROX_PROCESSES_LISTENING_ON_PORT": "true",
+			"ROX_ENABLE_AFTERGLOW":            "false",
+		},
+	}
+
+	s.StartCollector(false, &collectorOptions)
+
+	image := config.Images().QaImageByKey("qa-socat")
+	s.Require().NoError(s.Executor().PullImage(image))
+
+	containerID, err := s.launchContainer(endpointRebindName, "--entrypoint", "/bin/sh", image, "-c", "/bin/sleep 300")
+	s.Require().NoError(err)
+	s.container = common.ContainerShortID(containerID)
+
+	s.Require().NoError(s.WaitForCollectorToTrack(s.container, 30*time.Second))
+}
+
+func (s *EndpointRebindTestSuite) TearDownSuite() {
+	s.StopCollector()
+	s.cleanupContainers(endpointRebindName)
+	s.WritePerfResults()
+}
+
+func (s *EndpointRebindTestSuite) TestRapidRebind() {
+	// each state is kept for longer than a scrape interval, so that every
+	// open and close is observed by a scrape
+	hold := s.ScrapeInterval() + time.Second
+	_, err := s.execContainerShellScript(endpointRebindName, "/bin/sh",
+		fmt.Sprintf(rebindScript, s.Rebinds, endpointRebindPort, int(hold.Seconds())))
+	s.Require().NoError(err)
+
+	timeout := s.ScrapeInterval() + scrapeIntervalMargin
+	s.Sensor().ExpectEndpointClosed(s.T(), s.container, endpointRebindPort, timeout)
+
+	// a lingering endpoint would be reported open again by the next scrape
+	common.Sleep(timeout)
+	s.Sensor().ExpectEndpointClosed(s.T(), s.container, endpointRebindPort, 0)
+
+	s.checkEndpointLifecycle()
+}
+
+// checkEndpointLifecycle checks that the port was reported opened, then
+// closed, once per rebind.
+func (s *EndpointRebindTestSuite) checkEndpointLifecycle() {
+	lifecycle := s.Sensor().EndpointLifecycle(s.container, endpointRebindPort)
+	s.Require().Len(lifecycle, 2*s.Rebinds, "expected an open and a close per rebind: %+v", lifecycle)
+
+	for i := 0; i < len(lifecycle); i += 2 {
+		s.Assert().True(lifecycle[i].Endpoint.IsActive(), "rebind %d was not reported opened", i/2)
+		s.Assert().False(lifecycle[i+1].Endpoint.IsActive(), "rebind %d was not reported closed", i/2)
+	}
+}
@@ -0,0 +1,64 @@
+package suites
+
+import (
+	"time"
+
+	"github.com/stackrox/collector/integration-tests/pkg/collector"
+	"github.com/stackrox/collector/integration-tests/pkg/common"
+	"github.com/stackrox/collector/integration-tests/pkg/config"
+	"github.com/stackrox/collector/integration-tests/pkg/types"
+)
+
+const endpointRestartName = "endpoint-restart"
+
+// EndpointRestartTestSuite checks that the listening endpoint of a container
+// is reported again after the container is restarted, rather than only the
+// observation from before the restart being kept.
+type EndpointRestartTestSuite struct {
+	IntegrationTestSuiteBase
+	container string
+}
+
+func (s *EndpointRestartTestSuite) SetupSuite() {
+	s.RegisterCleanup(endpointRestartName)
+	s.StartContainerStats()
+
+	collectorOptions := collector.StartupOptions{
+		Config: map[string]any{
+			"turnOffScrape": false,
+		},
+		Env: map[string]string{
+			"ROX_PROCESSES_LISTENING_ON_PORT": "true",
+		},
+	}
+
+	s.StartCollector(false, &collectorOptions)
+
+	image := config.Images().QaImageByKey("qa-socat")
+	s.Require().NoError(s.Executor().PullImage(image))
+
+	// socat is the main process, so the listener is reopened on restart
+	containerID, err := s.launchContainer(endpointRestartName, image, "TCP-LISTEN:8080,fork", "STDOUT")
+	s.Require().NoError(err)
+	s.container = common.ContainerShortID(containerID)
+}
+
+func (s *EndpointRestartTestSuite) TearDownSuite() {
+	s.StopCollector()
+	s.cleanupContainers(endpointRestartName)
+	s.WritePerfResults()
+}
+
+func (s *EndpointRestartTestSuite) TestEndpointReportedAfterRestart() {
+	isListening := func(endpoint types.EndpointInfo) bool {
+		return endpoint.Address.Port == 8080 && endpoint.IsActive()
+	}
+
+	s.Sensor().ExpectEndpointAfter(s.T(), s.container, time.Time{}, 30*time.Second, isListening)
+
+	restarted := time.Now()
+	_, err := s.Executor().RestartContainer(endpointRestartName)
+	s.Require().NoError(err)
+
+	s.Sensor().ExpectEndpointAfter(s.T(), s.container, restarted, 30*time.Second, isListening)
+}
@@ -0,0 +1,80 @@
+package suites
+
+import (
+	"time"
+
+	"github.com/stackrox/collector/integration-tests/pkg/collector"
+	"github.com/stackrox/collector/integration-tests/pkg/common"
+	"github.com/stackrox/collector/integration-tests/pkg/config"
+	"github.com/stackrox/collector/integration-tests/pkg/types"
+)
+
+const execChainContainer = "exec-chain"
+
+// execChainScript starts a listener in each way a process can be reached
+// from a wrapper: a forked subshell that execs (8081), a new shell process
+// that execs (8082), and the wrapper itself exec'ing (8080), last, since it
+// replaces the wrapper.
+const execChainScript = "(exec socat TCP-LISTEN:8081,fork STDOUT) & " +
+	"sh -c 'exec socat TCP-LISTEN:8082,fork STDOUT' & " +
+	"exec socat TCP-LISTEN:8080,fork STDOUT"
+
+// ExecChainOriginatorTestSuite checks that a listening endpoint is
+// attributed to the process that opened it, i.e. the binary exec'd last,
+// even when it is reached through fork and exec chains from a wrapper
+// shell.
+type ExecChainOriginatorTestSuite struct {
+	IntegrationTestSuiteBase
+	containerID string
+}
+
+func (s *ExecChainOriginatorTestSuite) SetupSuite() {
+	s.RegisterCleanup(execChainContainer)
+	s.StartContainerStats()
+
+	collectorOptions := collector.StartupOptions{
+		Config: map[string]any{
+			"turnOffScrape": false,
+		},
+		Env: map[string]string{
+			"ROX_PROCESSES_LISTENING_ON_PORT": "true",
+		},
+	}
+
+	s.StartCollector(false, &collectorOptions)
+
+	image := config.Images().QaImageByKey("qa-socat")
+	s.Require().NoError(s.Executor().PullImage(image))
+
+	containerID, err := s.launchContainer(execChainContainer, "--entrypoint", "/bin/sh", image, "-c", execChainScript)
+	s.Require().NoError(err)
+	s.containerID = common.ContainerShortID(containerID)
+}
+
+func (s *ExecChainOriginatorTestSuite) TearDownSuite() {
+	s.StopCollector()
+	s.cleanupContainers(execChainContainer)
+	s.WritePerfResults()
+}
+
+func (s *ExecChainOriginatorTestSuite) TestOriginatorIsExecdBinary() {
+	// the wrapper and nested shells, and a socat per listener
+	processes := s.Sensor().ExpectProcessesN(s.T(), s.containerID, 30*time.Second, 5)
+
+	for _, port := range []int{8080, 8081, 8082} {
+		process, err := getProcessByPort(processes, port)
+		s.Require().NoError(err)
+		s.Require().Equal("socat", process.Name)
+
+		s.Sensor().ExpectEndpointOriginator(s.T(), s.containerID, port, 30*time.Second, types.ProcessOriginator{
+			ProcessName:         process.Name,
+			ProcessExecFilePath: process.ExePath,
+			ProcessArgs:         process.Args,
+		})
+	}
+}
+
+func (s *ExecChainOriginatorTestSuite) TestProcessesReportedBeforeEndpoints() {
+	s.Sensor().ExpectEndpointsN(s.T(), s.containerID, 30*time.Second, 3)
+	s.Sensor().AssertSignalCausality(s.T(), s.containerID)
+}
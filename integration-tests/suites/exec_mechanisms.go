@@ -0,0 +1,105 @@
+package suites
+
+import (
+	"time"
+
+	"github.com/stackrox/collector/integration-tests/pkg/common"
+	"github.com/stackrox/collector/integration-tests/pkg/config"
+	"github.com/stackrox/collector/integration-tests/pkg/types"
+)
+
+const (
+	execMechanismsName = "exec-mechanisms"
+	execLauncherPath   = "/usr/local/bin/launcher"
+)
+
+// execMechanism is a way of starting a process, with the exact processes
+// and lineage collector is expected to report for it. The launcher of the
+// qa-exec-mechanisms image starts the target with the given mode, passing
+// it a marker argument, which tells the processes of each mechanism apart
+// as they may run the same target.
+type execMechanism struct {
+	name     string
+	args     []string
+	expected []types.ProcessInfo
+	lineage  types.ProcessLineage
+}
+
+var execMechanisms = []execMechanism{
+	{
+		name: "fork+exec",
+		args: []string{execLauncherPath, "fork", "/usr/local/bin/target-dynamic", "mechanism-0"},
+		expected: []types.ProcessInfo{
+			{Name: "launcher", ExePath: execLauncherPath, Args: "fork /usr/local/bin/target-dynamic mechanism-0"},
+			{Name: "target-dynamic", ExePath: "/usr/local/bin/target-dynamic", Args: "mechanism-0"},
+		},
+		lineage: types.ProcessLineage{Name: "target-dynamic", ParentExePath: execLauncherPath},
+	},
+	{
+		name: "posix_spawn",
+		args: []string{execLauncherPath, "spawn", "/usr/local/bin/target-dynamic", "mechanism-1"},
+		expected: []types.ProcessInfo{
+			{Name: "launcher", ExePath: execLauncherPath, Args: "spawn /usr/local/bin/target-dynamic mechanism-1"},
+			{Name: "target-dynamic", ExePath: "/usr/local/bin/target-dynamic", Args: "mechanism-1"},
+		},
+		lineage: types.ProcessLineage{Name: "target-dynamic", ParentExePath: execLauncherPath},
+	},
+	{
+		name: "static fork+exec",
+		args: []string{execLauncherPath, "fork", "/usr/local/bin/target-static", "mechanism-2"},
+		expected: []types.ProcessInfo{
+			{Name: "launcher", ExePath: execLauncherPath, Args: "fork /usr/local/bin/target-static mechanism-2"},
+			{Name: "target-static", ExePath: "/usr/local/bin/target-static", Args: "mechanism-2"},
+		},
+		lineage: types.ProcessLineage{Name: "target-static", ParentExePath: execLauncherPath},
+	},
+	{
+		name: "static posix_spawn",
+		args: []string{execLauncherPath, "spawn", "/usr/local/bin/target-static", "mechanism-3"},
+		expected: []types.ProcessInfo{
+			{Name: "launcher", ExePath: execLauncherPath, Args: "spawn /usr/local/bin/target-static mechanism-3"},
+			{Name: "target-static", ExePath: "/usr/local/bin/target-static", Args: "mechanism-3"},
+		},
+		lineage: types.ProcessLineage{Name: "target-static", ParentExePath: execLauncherPath},
+	},
+}
+
+// ExecMechanismsTestSuite checks that the exe path and arguments of a
+// process are reported correctly however it was started, including for a
+// statically linked binary, which collector cannot resolve the way it does
+// dynamically linked ones.
+type ExecMechanismsTestSuite struct {
+	IntegrationTestSuiteBase
+	container string
+}
+
+func (s *ExecMechanismsTestSuite) SetupSuite() {
+	s.RegisterCleanup(execMechanismsName)
+	s.StartContainerStats()
+	s.StartCollector(false, nil)
+
+	image := config.Images().QaImageByKey("qa-exec-mechanisms")
+	s.Require().NoError(s.Executor().PullImage(image))
+
+	containerID, err := s.launchContainer(execMechanismsName, image)
+	s.Require().NoError(err)
+	s.container = common.ContainerShortID(containerID)
+}
+
+func (s *ExecMechanismsTestSuite) TearDownSuite() {
+	s.StopCollector()
+	s.cleanupContainers(execMechanismsName)
+	s.WritePerfResults()
+}
+
+func (s *ExecMechanismsTestSuite) TestExecMechanisms() {
+	for _, mechanism := range execMechanisms {
+		s.Run(mechanism.name, func() {
+			_, err := s.execContainer(execMechanismsName, mechanism.args)
+			s.Require().NoError(err)
+
+			s.Sensor().ExpectProcesses(s.T(), s.container, 30*time.Second, mechanism.expected...)
+			s.Sensor().ExpectLineages(s.T(), s.container, 30*time.Second, mechanism.lineage.Name, mechanism.lineage)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package suites
+
+import (
+	"time"
+
+	"github.com/stackrox/collector/integration-tests/pkg/common"
+	"github.com/stackrox/collector/integration-tests/pkg/config"
+	"github.com/stackrox/collector/integration-tests/pkg/types"
+)
+
+const executorReconnectContainer = "executor-reconnect"
+
+// ExecutorReconnectTestSuite drops the ssh connection tunneling the mock
+// sensor to the remote host while collector is running, as after a
+// transient network disruption, and checks that both the harness and
+// collector's reporting carry on once it is re-established. It only runs
+// against a remote host, the only one with a connection to break.
+type ExecutorReconnectTestSuite struct {
+	IntegrationTestSuiteBase
+	workloadContainer string
+}
+
+func (s *ExecutorReconnectTestSuite) SetupSuite() {
+	if !config.HostInfo().IsSSH() {
+		s.T().Skip("there is no ssh connection to break on a local host")
+	}
+
+	s.RegisterCleanup(executorReconnectContainer)
+	s.StartCollector(false, nil)
+
+	image := config.Images().QaImageByKey("qa-alpine-curl")
+	s.Require().NoError(s.Executor().PullImage(image))
+
+	containerID, err := s.launchContainer(executorReconnectContainer, image, "sleep", "300")
+	s.Require().NoError(err)
+	s.workloadContainer = common.ContainerShortID(containerID)
+}
+
+func (s *ExecutorReconnectTestSuite) TearDownSuite() {
+	s.StopCollector()
+	s.cleanupContainers(executorReconnectContainer)
+}
+
+func (s *ExecutorReconnectTestSuite) TestReconnect() {
+	_, err := s.execContainer(executorReconnectContainer, []string{"/bin/uname"})
+	s.Require().NoError(err)
+	s.Sensor().ExpectProcesses(s.T(), s.workloadContainer, 30*time.Second, types.ProcessInfo{
+		Name:    "uname",
+		ExePath: "/bin/uname",
+	})
+
+	// killing the tunnel closes the ssh connection, and with it the
+	// streams of collector to the mock sensor
+	s.stopSensorExposure()
+	s.stopSensorExposure = nil
+
+	s.Require().NoError(s.Executor().Reconnect())
+	stop, err := s.Executor().ExposeToHost(s.Sensor().Port())
+	s.Require().NoError(err)
+	s.stopSensorExposure = stop
+
+	running, err := s.Collector().IsRunning()
+	s.Require().NoError(err)
+	s.Require().True(running, "collector is not running after the reconnect")
+
+	// collector backs off before reconnecting to the sensor, and drops
+	// the signals until it has, so the process is run until it is reported
+	deadline := time.Now().Add(2 * time.Minute)
+	for !s.hasReportedDate() {
+		s.Require().True(time.Now().Before(deadline), "collector did not report after the reconnect")
+
+		_, err = s.execContainer(executorReconnectContainer, []string{"/bin/date"})
+		s.Require().NoError(err)
+		time.Sleep(5 * time.Second)
+	}
+}
+
+func (s *ExecutorReconnectTestSuite) hasReportedDate() bool {
+	for _, process := range s.Sensor().Processes(s.workloadContainer) {
+		if process.ExePath == "/bin/date" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package suites
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/stackrox/collector/integration-tests/pkg/collector"
+)
+
+// hostEtcCrash matches the log lines of a collector crash.
+var hostEtcCrash = regexp.MustCompile(`(?i)segmentation fault|panic|terminate called|Assertion .* failed`)
+
+// HostEtcTestSuite launches collector with files of the host's /etc removed
+// or corrupted, and checks that it keeps running and reporting rather than
+// crashing, and that it logs how it handled the alteration. Collector runs
+// at debug level, as some of these lines are only logged at that level.
+type HostEtcTestSuite struct {
+	IntegrationTestSuiteBase
+	// Remove and Replace are the alterations of /etc, see
+	// collector.StartupOptions.HostEtcRemove and HostEtcReplace
+	Remove  []string
+	Replace map[string]string
+	// HideUsrLib mounts an empty directory as the host's /usr/lib, so that
+	// collector cannot fall back to the files there (e.g. os-release)
+	HideUsrLib bool
+	// ExpectedLogs are the lines collector is expected to log
+	ExpectedLogs []string
+
+	emptyDir string
+}
+
+func (s *HostEtcTestSuite) SetupSuite() {
+	s.RegisterCleanup()
+
+	options := &collector.StartupOptions{
+		HostEtcRemove:  s.Remove,
+		HostEtcReplace: s.Replace,
+		Config: map[string]any{
+			"logLevel": "debug",
+		},
+	}
+
+	if s.HideUsrLib {
+		output, err := s.Executor().Exec("mktemp", "-d", "/tmp/collector-usr-lib-XXXXXX")
+		s.Require().NoError(err)
+		s.emptyDir = strings.TrimSpace(output)
+		options.Mounts = map[string]string{"/host/usr/lib:ro": s.emptyDir}
+	}
+
+	s.StartCollector(false, options)
+}
+
+func (s *HostEtcTestSuite) TearDownSuite() {
+	s.StopCollector()
+	if s.emptyDir != "" {
+		_, err := s.Executor().Exec("rm", "-rf", s.emptyDir)
+		s.Require().NoError(err)
+	}
+	s.WritePerfResults()
+}
+
+func (s *HostEtcTestSuite) TestAlteredHostEtc() {
+	// collector must keep reporting, not only get through startup
+	reported := s.Sensor().WaitProcessesN(s.Collector().ContainerID(), 30*time.Second, 2, func() {
+		_, err := s.execContainer("collector", []string{"echo"})
+		s.Require().NoError(err)
+	})
+	s.Assert().True(reported, "collector stopped reporting processes")
+
+	running, err := s.Collector().IsRunning()
+	s.Require().NoError(err)
+	s.Require().True(running, "collector exited")
+
+	logs, err := s.containerLogs("collector")
+	s.Require().NoError(err)
+	s.Assert().NotRegexp(hostEtcCrash, logs)
+
+	for _, line := range s.ExpectedLogs {
+		s.Assert().Contains(logs, line)
+	}
+}
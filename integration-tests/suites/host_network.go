@@ -0,0 +1,123 @@
+package suites
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/stackrox/collector/integration-tests/pkg/collector"
+	"github.com/stackrox/collector/integration-tests/pkg/common"
+	"github.com/stackrox/collector/integration-tests/pkg/fixtures"
+	"github.com/stackrox/collector/integration-tests/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	hostNetworkServerName = "host-network-server"
+	hostNetworkClientName = "host-network-client"
+
+	// hostNetworkPort is chosen to be unlikely in use on the host, since
+	// the server shares its network namespace
+	hostNetworkPort = 41234
+)
+
+// HostNetworkTestSuite checks that connections between two containers in
+// the host network namespace are attributed to the right container. As
+// both share the namespace with each other, the host and collector itself,
+// the socket alone does not tell which container it belongs to.
+type HostNetworkTestSuite struct {
+	IntegrationTestSuiteBase
+	hostIP          string
+	serverContainer string
+	clientContainer string
+}
+
+func (s *HostNetworkTestSuite) SetupSuite() {
+	s.RegisterCleanup(hostNetworkServerName, hostNetworkClientName)
+	s.StartContainerStats()
+
+	s.StartCollector(false, &collector.StartupOptions{
+		Env: map[string]string{
+			"ROX_ENABLE_AFTERGLOW": "false",
+		},
+	})
+
+	server := fixtures.SocatServer(hostNetworkServerName, hostNetworkPort)
+	server.NetworkMode = "host"
+
+	client := fixtures.SocatClient(hostNetworkClientName)
+	client.NetworkMode = "host"
+
+	s.Require().NoError(s.PullImages(server.Image))
+
+	containerID, err := s.Executor().StartContainer(server)
+	s.Require().NoError(err)
+	s.serverContainer = common.ContainerShortID(containerID)
+
+	containerID, err = s.Executor().StartContainer(client)
+	s.Require().NoError(err)
+	s.clientContainer = common.ContainerShortID(containerID)
+
+	// loopback connections are not reported, so the client must go
+	// through the host's external address
+	s.hostIP, err = s.getHostIP(hostNetworkClientName)
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.WaitForCollectorToTrack(s.clientContainer, 30*time.Second))
+
+	_, err = s.execContainer(hostNetworkClientName, []string{"/bin/sh", "-c",
+		fmt.Sprintf("echo hello | socat - TCP4:%s:%d", s.hostIP, hostNetworkPort)})
+	s.Require().NoError(err)
+}
+
+func (s *HostNetworkTestSuite) TearDownSuite() {
+	s.StopCollector()
+	s.cleanupContainers(hostNetworkServerName, hostNetworkClientName)
+	s.WritePerfResults()
+}
+
+// getHostIP returns the source address the host uses for outbound traffic,
+// as seen from a container in the host network.
+func (s *HostNetworkTestSuite) getHostIP(containerName string) (string, error) {
+	output, err := s.execContainer(containerName, []string{"ip", "-4", "route", "get", "1.1.1.1"})
+	if err != nil {
+		return "", err
+	}
+
+	fields := strings.Fields(output)
+	for i, field := range fields {
+		if field == "src" && i+1 < len(fields) {
+			return fields[i+1], nil
+		}
+	}
+	return "", fmt.Errorf("no source address in route: %q", output)
+}
+
+func (s *HostNetworkTestSuite) TestServerConnection() {
+	s.ExpectConnectionWithinScrape(s.serverContainer, func(conn types.NetworkInfo) bool {
+		return conn.LocalAddress == fmt.Sprintf(":%d", hostNetworkPort) &&
+			conn.RemoteAddress == s.hostIP &&
+			conn.Role == "ROLE_SERVER"
+	})
+
+	// the client side of the connection lives in the same namespace, but
+	// belongs to the other container
+	for _, conn := range s.Sensor().Connections(s.serverContainer) {
+		assert.NotEqual(s.T(), "ROLE_CLIENT", conn.Role, "client connection attributed to the server: %+v", conn)
+	}
+}
+
+func (s *HostNetworkTestSuite) TestClientConnection() {
+	s.ExpectConnectionWithinScrape(s.clientContainer, func(conn types.NetworkInfo) bool {
+		return conn.RemoteAddress == fmt.Sprintf("%s:%d", s.hostIP, hostNetworkPort) &&
+			conn.Role == "ROLE_CLIENT"
+	})
+
+	for _, conn := range s.Sensor().Connections(s.clientContainer) {
+		assert.NotEqual(s.T(), "ROLE_SERVER", conn.Role, "server connection attributed to the client: %+v", conn)
+	}
+
+	// the server is listening in the shared namespace, but it is not the
+	// client's endpoint
+	assert.Empty(s.T(), s.Sensor().EndpointsByPort(s.clientContainer)[hostNetworkPort])
+}
@@ -1,7 +1,6 @@
 package suites
 
 import (
-	"sort"
 	"time"
 
 	"github.com/stackrox/collector/integration-tests/pkg/collector"
@@ -71,13 +70,9 @@ func (s *ProcessListeningOnPortTestSuite) TestProcessListeningOnPort() {
 	processes := s.Sensor().ExpectProcessesN(s.T(), s.serverContainer, 30*time.Second, 2)
 	endpoints := s.Sensor().ExpectEndpointsN(s.T(), s.serverContainer, 30*time.Second, 4)
 
-	// sort by name to ensure processes[0] is the plop process (the other
-	// is the shell)
+	// processes are sorted by name, so processes[0] is the plop process
+	// (the other is the shell)
 	// All of these asserts check against the processes information of that program.
-	sort.Slice(processes, func(i, j int) bool {
-		return processes[i].Name < processes[j].Name
-	})
-
 	process := processes[0]
 
 	possiblePorts := []int{8081, 9091}
@@ -0,0 +1,74 @@
+package suites
+
+import (
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stackrox/collector/integration-tests/pkg/common"
+	"github.com/stackrox/collector/integration-tests/pkg/config"
+)
+
+const (
+	longCommandLineName = "long-command-line"
+
+	// processArgsLimit is the size of the buffer the driver copies the
+	// arguments of a new process into, including argv[0], the NUL byte
+	// terminating each argument and the one the buffer always ends with.
+	// Longer argument lists are truncated.
+	processArgsLimit = 4096
+
+	longCommandLineProcess = "echo"
+)
+
+// LongCommandLineTestSuite checks that a process with a multi-kilobyte
+// argument is reported, with its arguments truncated to the driver's buffer.
+// A single argument is used, so that the truncation point does not depend on
+// where the separators fall.
+type LongCommandLineTestSuite struct {
+	IntegrationTestSuiteBase
+	container string
+	arg       string
+}
+
+func (s *LongCommandLineTestSuite) SetupSuite() {
+	s.RegisterCleanup(longCommandLineName)
+	s.StartContainerStats()
+	s.StartCollector(false, nil)
+
+	image := config.Images().ImageByKey("busybox")
+	s.Require().NoError(s.Executor().PullImage(image))
+
+	containerID, err := s.launchContainer(longCommandLineName, image, "sleep", "300")
+	s.Require().NoError(err)
+	s.container = common.ContainerShortID(containerID)
+
+	s.arg = common.RepeatString("collector-", 4*processArgsLimit)
+	_, err = s.execContainer(longCommandLineName, []string{longCommandLineProcess, s.arg})
+	s.Require().NoError(err)
+}
+
+func (s *LongCommandLineTestSuite) TearDownSuite() {
+	s.StopCollector()
+	s.cleanupContainers(longCommandLineName)
+	s.WritePerfResults()
+}
+
+func (s *LongCommandLineTestSuite) TestArgsTruncated() {
+	// the container's sleep, and the echo with the long command line
+	processes := s.Sensor().ExpectProcessesN(s.T(), s.container, 30*time.Second, 2)
+
+	// "echo\0" comes first in the buffer, which ends with a NUL byte
+	expectedArgs := s.arg[:processArgsLimit-len(longCommandLineProcess)-2]
+	for _, process := range processes {
+		if process.Name != longCommandLineProcess {
+			continue
+		}
+
+		assert.Len(s.T(), process.Args, len(expectedArgs), "args were not truncated to the driver's buffer")
+		assert.Equal(s.T(), expectedArgs, process.Args)
+		return
+	}
+
+	s.Failf("process not found", "no echo process in %+v", processes)
+}
@@ -0,0 +1,76 @@
+package suites
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/stackrox/collector/integration-tests/pkg/collector"
+	"github.com/stackrox/collector/integration-tests/pkg/common"
+	"github.com/stackrox/collector/integration-tests/pkg/config"
+	"github.com/stackrox/collector/integration-tests/pkg/mock_sensor"
+	"github.com/stackrox/collector/integration-tests/pkg/types"
+)
+
+const (
+	loopbackContainerName = "loopback"
+	loopbackPort          = 8081
+)
+
+// LoopbackTestSuite connects to a server in the same container, once over
+// loopback and once over the container's own address. Collector does not
+// report connections with a loopback remote address, as they never leave
+// the container, so only the latter must be reported. The non-loopback
+// connection also shows that collector observed the container at all,
+// so that the absence of the loopback one is meaningful.
+type LoopbackTestSuite struct {
+	IntegrationTestSuiteBase
+	container string
+	ip        string
+}
+
+func (s *LoopbackTestSuite) SetupSuite() {
+	s.RegisterCleanup(loopbackContainerName)
+	s.StartContainerStats()
+
+	s.StartCollector(false, &collector.StartupOptions{
+		Env: map[string]string{
+			"ROX_ENABLE_AFTERGLOW": "false",
+		},
+	})
+
+	image := config.Images().QaImageByKey("qa-socat")
+	s.Require().NoError(s.PullImages(image))
+
+	containerID, err := s.launchContainer(loopbackContainerName, "--entrypoint", "/bin/sh", image, "-c",
+		fmt.Sprintf("socat TCP4-LISTEN:%d,reuseaddr,fork - & /bin/sleep 300", loopbackPort))
+	s.Require().NoError(err)
+	s.container = common.ContainerShortID(containerID)
+
+	s.ip, err = s.getIPAddress(loopbackContainerName)
+	s.Require().NoError(err)
+
+	s.Require().NoError(s.WaitForCollectorToTrack(s.container, 30*time.Second))
+
+	for _, address := range []string{"127.0.0.1", s.ip} {
+		_, err = s.execContainer(loopbackContainerName, []string{"/bin/sh", "-c",
+			fmt.Sprintf("echo hello | socat - TCP4:%s:%d", address, loopbackPort)})
+		s.Require().NoError(err)
+	}
+}
+
+func (s *LoopbackTestSuite) TearDownSuite() {
+	s.StopCollector()
+	s.cleanupContainers(loopbackContainerName)
+	s.WritePerfResults()
+}
+
+func (s *LoopbackTestSuite) TestLoopbackConnectionNotReported() {
+	s.ExpectConnectionWithinScrape(s.container, func(conn types.NetworkInfo) bool {
+		return conn.RemoteAddress == fmt.Sprintf("%s:%d", s.ip, loopbackPort) &&
+			conn.Role == "ROLE_CLIENT"
+	})
+
+	// both connections were made together, so the loopback one would
+	// have been reported alongside the other by now
+	s.Sensor().ExpectNoConnectionMatch(s.T(), s.container, s.ScrapeInterval(), mock_sensor.LoopbackConnection)
+}
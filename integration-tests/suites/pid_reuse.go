@@ -0,0 +1,155 @@
+package suites
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stackrox/collector/integration-tests/pkg/common"
+	"github.com/stackrox/collector/integration-tests/pkg/config"
+	"github.com/stackrox/collector/integration-tests/pkg/types"
+)
+
+//
+// When PIDs are reused, collector must not attribute a new process to the
+// container that previously owned the PID.
+//
+// This suite runs two containers: a target which runs a single 'uname'
+// process, and a churn container which spawns short-lived 'true' processes
+// until the PID of that 'uname' is allocated again. To make this happen in
+// a bounded time, pid_max is lowered for the duration of the suite. Every
+// process is distinctive to its container, so any misattribution is
+// visible in the reported signals.
+//
+
+const (
+	pidReuseChurnContainer  = "pid-reuse-churn"
+	pidReuseTargetContainer = "pid-reuse-target"
+
+	// pidReuseMax is the pid_max used by the suite, which bounds how many
+	// processes must be spawned before a PID is reused.
+	pidReuseMax     = 32768
+	pidReuseTimeout = 5 * time.Minute
+	pidMaxPath      = "/proc/sys/kernel/pid_max"
+)
+
+type PidReuseTestSuite struct {
+	IntegrationTestSuiteBase
+	churnContainer  string
+	targetContainer string
+	// originalPidMax is the pid_max to restore, if it was lowered
+	originalPidMax int
+	targetPid      int
+}
+
+func (s *PidReuseTestSuite) SetupSuite() {
+	s.RegisterCleanup(pidReuseChurnContainer, pidReuseTargetContainer)
+	s.StartContainerStats()
+	s.StartCollector(false, nil)
+
+	image := config.Images().QaImageByKey("qa-alpine-curl")
+	s.Require().NoError(s.Executor().PullImage(image))
+
+	// pid_max is not namespaced, so the churn container must be privileged
+	// to change it for the host
+	containerID, err := s.launchContainer(pidReuseChurnContainer, "--privileged", image, "sleep", "600")
+	s.Require().NoError(err)
+	s.churnContainer = common.ContainerShortID(containerID)
+
+	containerID, err = s.launchContainer(pidReuseTargetContainer, image, "sleep", "600")
+	s.Require().NoError(err)
+	s.targetContainer = common.ContainerShortID(containerID)
+
+	output, err := s.execContainer(pidReuseChurnContainer, []string{"cat", pidMaxPath})
+	s.Require().NoError(err)
+	pidMax, err := strconv.Atoi(strings.TrimSpace(output))
+	s.Require().NoError(err)
+	if pidMax > pidReuseMax {
+		s.setPidMax(pidReuseMax)
+		s.originalPidMax = pidMax
+	}
+
+	// Once pid_max is lowered, the next PIDs wrap around below it
+	_, err = s.execContainer(pidReuseTargetContainer, []string{"/bin/uname"})
+	s.Require().NoError(err)
+
+	s.Sensor().ExpectProcesses(s.T(), s.targetContainer, 30*time.Second, types.ProcessInfo{
+		Name:    "uname",
+		ExePath: "/bin/uname",
+	})
+	for _, process := range s.Sensor().Processes(s.targetContainer) {
+		if process.Name == "uname" {
+			s.targetPid = process.Pid
+		}
+	}
+	s.Require().NotZero(s.targetPid, "no PID reported for uname")
+
+	// true is a shell builtin, so use the full path to spawn actual processes.
+	// Spawn enough of them to cycle through the whole PID space twice.
+	churn := fmt.Sprintf("i=0; while [ $i -lt %d ]; do /bin/true; i=$((i+1)); done", 2*pidReuseMax)
+	_, err = s.execContainer(pidReuseChurnContainer, []string{"/bin/sh", "-c", churn + " > /dev/null 2>&1 &"})
+	s.Require().NoError(err)
+}
+
+func (s *PidReuseTestSuite) TearDownSuite() {
+	if s.originalPidMax != 0 {
+		s.setPidMax(s.originalPidMax)
+	}
+	s.StopCollector()
+	s.cleanupContainers(pidReuseChurnContainer, pidReuseTargetContainer)
+	s.WritePerfResults()
+}
+
+// setPidMax sets pid_max for the host, from the privileged churn container.
+func (s *PidReuseTestSuite) setPidMax(pidMax int) {
+	_, err := s.execContainer(pidReuseChurnContainer,
+		[]string{"/bin/sh", "-c", fmt.Sprintf("echo %d > %s", pidMax, pidMaxPath)})
+	s.Require().NoError(err)
+}
+
+// waitForReusedPid waits until a churn process is reported with the PID of
+// the target process.
+func (s *PidReuseTestSuite) waitForReusedPid() bool {
+	deadline := time.Now().Add(pidReuseTimeout)
+	for time.Now().Before(deadline) {
+		for _, process := range s.Sensor().Processes(s.churnContainer) {
+			if process.Pid == s.targetPid {
+				return true
+			}
+		}
+		common.Sleep(time.Second)
+	}
+	return false
+}
+
+func (s *PidReuseTestSuite) TestNoMisattributedProcesses() {
+	s.Require().True(s.waitForReusedPid(), "PID %d of uname was not reused by the churn container", s.targetPid)
+
+	for _, process := range s.Sensor().Processes(s.churnContainer) {
+		assert.NotEqual(s.T(), "uname", process.Name, "target process attributed to the churn container")
+		if process.Pid == s.targetPid {
+			assert.Equal(s.T(), "true", process.Name, "unexpected process with the reused PID")
+		}
+	}
+
+	for _, process := range s.Sensor().Processes(s.targetContainer) {
+		assert.NotEqual(s.T(), "true", process.Name, "churn process attributed to the target container")
+	}
+
+	for _, lineage := range s.Sensor().ProcessLineages(s.targetContainer) {
+		assert.NotEqual(s.T(), "true", lineage.Name, "churn process lineage attributed to the target container")
+	}
+
+	// Both instances of the PID are reported, each with its own start time
+	targetStarts := s.Sensor().ProcessStartTimes(s.targetContainer, s.targetPid)
+	churnStarts := s.Sensor().ProcessStartTimes(s.churnContainer, s.targetPid)
+	s.Require().Len(targetStarts, 1, "expected a single uname with PID %d", s.targetPid)
+	s.Require().NotEmpty(churnStarts)
+	for _, start := range churnStarts {
+		assert.True(s.T(), start.After(targetStarts[0]),
+			"reused PID %d reported with start time %s, not after the original %s", s.targetPid, start, targetStarts[0])
+	}
+}
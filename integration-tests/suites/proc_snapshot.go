@@ -0,0 +1,123 @@
+package suites
+
+import (
+	"archive/tar"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/stackrox/collector/integration-tests/pkg/collector"
+	"github.com/stackrox/collector/integration-tests/pkg/common"
+	"github.com/stackrox/collector/integration-tests/pkg/types"
+)
+
+// snapshotContainerID is the container the snapshot's process belongs to,
+// according to its cgroup.
+const snapshotContainerID = "5e1ec7ed5e1ec7ed5e1ec7ed5e1ec7ed5e1ec7ed5e1ec7ed5e1ec7ed5e1ec7ed"
+
+// snapshotProcess is the process collector is expected to scrape from the
+// snapshot.
+var snapshotProcess = types.ProcessInfo{
+	Name:    "snapshot-proc",
+	ExePath: "/usr/bin/snapshot-proc",
+	Uid:     0,
+	Gid:     0,
+	Args:    "--flag",
+}
+
+// ProcSnapshotScrapeTestSuite runs collector against a static /proc
+// snapshot, and checks that it scrapes exactly the known contents of the
+// snapshot. The snapshot can be a capture of a real host, or by default a
+// minimal one generated by the suite.
+type ProcSnapshotScrapeTestSuite struct {
+	IntegrationTestSuiteBase
+	// Snapshot is the path of the /proc tarball, generated if not set
+	Snapshot string
+
+	cleanupSnapshot func()
+}
+
+func (s *ProcSnapshotScrapeTestSuite) SetupSuite() {
+	s.RegisterCleanup()
+
+	if s.Snapshot == "" {
+		s.Snapshot = filepath.Join(os.TempDir(), "proc-snapshot.tar")
+		s.Require().NoError(writeProcSnapshot(s.Snapshot))
+	}
+
+	var err error
+	s.cleanupSnapshot, err = s.MountProcSnapshot(s.Snapshot)
+	s.Require().NoError(err)
+
+	collectorOptions := collector.StartupOptions{
+		Config: map[string]any{
+			"turnOffScrape": false,
+		},
+		Env: map[string]string{
+			// the snapshot has no hostname, see MissingProcScrapeTestSuite
+			"NODE_HOSTNAME": "collector-proc-snapshot-host",
+		},
+	}
+
+	s.StartCollector(false, &collectorOptions)
+}
+
+func (s *ProcSnapshotScrapeTestSuite) TearDownSuite() {
+	s.StopCollector()
+	if s.cleanupSnapshot != nil {
+		s.cleanupSnapshot()
+	}
+}
+
+func (s *ProcSnapshotScrapeTestSuite) TestScrapeMatchesSnapshot() {
+	containerID := common.ContainerShortID(snapshotContainerID)
+
+	s.Sensor().ExpectProcesses(s.T(), containerID, 30*time.Second, snapshotProcess)
+	s.Assert().Equal([]types.ProcessInfo{snapshotProcess}, s.Sensor().Processes(containerID))
+}
+
+// writeProcSnapshot writes a minimal /proc tarball, with a single process
+// running in snapshotContainerID, and what collector needs to start.
+func writeProcSnapshot(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	files := []struct {
+		name    string
+		content string
+	}{
+		{"stat", "btime 1695972922\n"},
+		{"1/mounts", "cgroup2 /sys/fs/cgroup cgroup2 rw,nosuid,nodev,noexec,relatime,nsdelegate 0 0\n"},
+		{"4242/status", "Name:\tsnapshot-proc\nUid:\t0\t0\t0\t0\nGid:\t0\t0\t0\t0\n"},
+		{"4242/cmdline", "/usr/bin/snapshot-proc\x00--flag\x00"},
+		{"4242/cgroup", "0::/system.slice/docker-" + snapshotContainerID + ".scope\n"},
+		{"4242/environ", ""},
+	}
+
+	archive := tar.NewWriter(f)
+	for _, dir := range []string{"1/", "4242/"} {
+		if err := archive.WriteHeader(&tar.Header{Typeflag: tar.TypeDir, Name: dir, Mode: 0755}); err != nil {
+			return err
+		}
+	}
+
+	for _, file := range files {
+		header := &tar.Header{Typeflag: tar.TypeReg, Name: file.name, Mode: 0444, Size: int64(len(file.content))}
+		if err := archive.WriteHeader(header); err != nil {
+			return err
+		}
+		if _, err := archive.Write([]byte(file.content)); err != nil {
+			return err
+		}
+	}
+
+	exe := &tar.Header{Typeflag: tar.TypeSymlink, Name: "4242/exe", Linkname: snapshotProcess.ExePath}
+	if err := archive.WriteHeader(exe); err != nil {
+		return err
+	}
+
+	return archive.Close()
+}
@@ -5,7 +5,8 @@ import (
 	"time"
 
 	"github.com/stackrox/collector/integration-tests/pkg/common"
-	"github.com/stackrox/collector/integration-tests/pkg/config"
+	"github.com/stackrox/collector/integration-tests/pkg/fixtures"
+	"github.com/stackrox/collector/integration-tests/pkg/mock_sensor"
 	"github.com/stackrox/collector/integration-tests/pkg/types"
 )
 
@@ -27,20 +28,15 @@ func (s *ProcessNetworkTestSuite) SetupSuite() {
 	s.StartContainerStats()
 	s.StartCollector(false, nil)
 
-	image_store := config.Images()
+	server := fixtures.NginxServer("nginx")
+	client := fixtures.HTTPClient("nginx-curl")
 
-	images := []string{
-		image_store.ImageByKey("nginx"),
-		image_store.QaImageByKey("qa-alpine-curl"),
-	}
-
-	for _, image := range images {
+	for _, image := range []string{server.Image, client.Image} {
 		err := s.executor.PullImage(image)
 		s.Require().NoError(err)
 	}
 
-	// invokes default nginx
-	containerID, err := s.launchContainer("nginx", image_store.ImageByKey("nginx"))
+	containerID, err := s.Executor().StartContainer(server)
 	s.Require().NoError(err)
 	s.serverContainer = common.ContainerShortID(containerID)
 
@@ -51,7 +47,7 @@ func (s *ProcessNetworkTestSuite) SetupSuite() {
 	s.Require().NoError(err)
 
 	// invokes another container
-	containerID, err = s.launchContainer("nginx-curl", image_store.QaImageByKey("qa-alpine-curl"), "sleep", "300")
+	containerID, err = s.Executor().StartContainer(client)
 	s.Require().NoError(err)
 	s.clientContainer = common.ContainerShortID(containerID)
 
@@ -135,7 +131,7 @@ func (s *ProcessNetworkTestSuite) TestProcessLineageInfo() {
 }
 
 func (s *ProcessNetworkTestSuite) TestNetworkFlows() {
-	s.Sensor().ExpectConnections(s.T(), s.serverContainer, 10*time.Second,
+	s.ExpectConnectionWithinScrape(s.serverContainer, mock_sensor.EqualConnection(
 		types.NetworkInfo{
 			LocalAddress:   fmt.Sprintf(":%s", s.serverPort),
 			RemoteAddress:  s.clientIP,
@@ -143,9 +139,9 @@ func (s *ProcessNetworkTestSuite) TestNetworkFlows() {
 			SocketFamily:   "SOCKET_FAMILY_UNKNOWN",
 			CloseTimestamp: types.NilTimestamp,
 		},
-	)
+	))
 
-	s.Sensor().ExpectConnections(s.T(), s.clientContainer, 10*time.Second,
+	s.ExpectConnectionWithinScrape(s.clientContainer, mock_sensor.EqualConnection(
 		types.NetworkInfo{
 			LocalAddress:   "",
 			RemoteAddress:  fmt.Sprintf("%s:%s", s.serverIP, s.serverPort),
@@ -153,5 +149,5 @@ func (s *ProcessNetworkTestSuite) TestNetworkFlows() {
 			SocketFamily:   "SOCKET_FAMILY_UNKNOWN",
 			CloseTimestamp: types.NilTimestamp,
 		},
-	)
+	))
 }
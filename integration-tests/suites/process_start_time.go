@@ -0,0 +1,65 @@
+package suites
+
+import (
+	"time"
+
+	"github.com/stackrox/collector/integration-tests/pkg/common"
+	"github.com/stackrox/collector/integration-tests/pkg/config"
+	"github.com/stackrox/collector/integration-tests/pkg/types"
+)
+
+const (
+	processStartTimeContainer = "process-start-time"
+
+	// processStartTimeTolerance allows for the runtime recording the start
+	// of a container slightly after its main process was spawned.
+	processStartTimeTolerance = time.Second
+)
+
+// ProcessStartTimeTestSuite checks that the start time collector reports
+// for a process matches the time at which it was spawned, according to the
+// clock of the host running it, which would be off by hours or decades with
+// timezone or epoch conversion bugs. The process is the main process of a
+// container, so that the runtime records when it started and finished.
+type ProcessStartTimeTestSuite struct {
+	IntegrationTestSuiteBase
+}
+
+func (s *ProcessStartTimeTestSuite) SetupSuite() {
+	s.RegisterCleanup(processStartTimeContainer)
+	s.StartCollector(false, nil)
+}
+
+func (s *ProcessStartTimeTestSuite) TearDownSuite() {
+	s.StopCollector()
+	s.cleanupContainers(processStartTimeContainer)
+	s.WritePerfResults()
+}
+
+func (s *ProcessStartTimeTestSuite) TestProcessStartTime() {
+	image := config.Images().QaImageByKey("qa-alpine-curl")
+	s.Require().NoError(s.Executor().PullImage(image))
+
+	containerID, err := s.launchContainer(processStartTimeContainer, image, "/bin/uname")
+	s.Require().NoError(err)
+	container := common.ContainerShortID(containerID)
+
+	exited, err := s.waitForContainerToExit(processStartTimeContainer, containerID, defaultWaitTickSeconds, 0)
+	s.Require().NoError(err)
+	s.Require().True(exited)
+
+	started, finished, err := s.Executor().GetContainerTimes(containerID)
+	s.Require().NoError(err)
+
+	s.Sensor().ExpectProcesses(s.T(), container, 30*time.Second, types.ProcessInfo{
+		Name:    "uname",
+		ExePath: "/bin/uname",
+	})
+
+	start, ok := s.Sensor().ProcessStartTime(container, "uname")
+	s.Require().True(ok, "no start time reported for the process")
+
+	s.Assert().WithinRange(start,
+		started.Add(-processStartTimeTolerance), finished.Add(processStartTimeTolerance),
+		"reported start time is not when the process was spawned")
+}
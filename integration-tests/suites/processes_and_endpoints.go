@@ -59,17 +59,11 @@ func (s *ProcessesAndEndpointsTestSuite) TestProcessesAndEndpoints() {
 
 	minEndpoints := common.Min(len(s.ExpectedEndpoints), len(endpoints))
 
-	types.SortEndpoints(endpoints)
+	// endpoints are already sorted by the sensor
+	types.SortEndpoints(s.ExpectedEndpoints)
 
 	for idx := 0; idx < minEndpoints; idx++ {
-		assert.Equal(s.T(), s.ExpectedEndpoints[idx].Protocol, endpoints[idx].Protocol)
-		assert.Equal(s.T(), s.ExpectedEndpoints[idx].Address.AddressData, endpoints[idx].Address.AddressData)
-		assert.Equal(s.T(), s.ExpectedEndpoints[idx].Address.Port, endpoints[idx].Address.Port)
-		assert.Equal(s.T(), s.ExpectedEndpoints[idx].Address.IpNetwork, endpoints[idx].Address.IpNetwork)
-		assert.Equal(s.T(), s.ExpectedEndpoints[idx].IsActive(), endpoints[idx].IsActive())
-		assert.Equal(s.T(), s.ExpectedEndpoints[idx].Originator.ProcessName, endpoints[idx].Originator.ProcessName)
-		assert.Equal(s.T(), s.ExpectedEndpoints[idx].Originator.ProcessExecFilePath, endpoints[idx].Originator.ProcessExecFilePath)
-		assert.Equal(s.T(), s.ExpectedEndpoints[idx].Originator.ProcessArgs, endpoints[idx].Originator.ProcessArgs)
+		s.AssertEndpointInfoEqual(s.ExpectedEndpoints[idx], endpoints[idx])
 	}
 
 	assert.ElementsMatch(s.T(), s.ExpectedProcesses, processes)
@@ -42,6 +42,12 @@ func (s *ProcfsScraperTestSuite) SetupSuite() {
 
 	s.StartCollector(false, &collectorOptions)
 
+	if !s.TurnOffScrape {
+		// the endpoint is only found by the initial scrape, so it must
+		// be complete before nginx is gone
+		s.Require().NoError(s.WaitForInitialScrape(time.Minute))
+	}
+
 	s.cleanupContainers("nginx")
 }
 
@@ -0,0 +1,134 @@
+package suites
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stackrox/collector/integration-tests/pkg/collector"
+	"github.com/stackrox/collector/integration-tests/pkg/common"
+	"github.com/stackrox/collector/integration-tests/pkg/config"
+	"github.com/stackrox/collector/integration-tests/pkg/mock_sensor"
+	"github.com/stackrox/collector/integration-tests/pkg/types"
+)
+
+const (
+	proxyHopClientName = "proxy-hop-client"
+	proxyHopProxyName  = "proxy-hop-proxy"
+	proxyHopServerName = "proxy-hop-server"
+
+	proxyHopPort = 8080
+)
+
+// proxyHopContainer is one of the containers of the chain.
+type proxyHopContainer struct {
+	id string
+	ip string
+}
+
+// ProxyHopTestSuite sends a request from a client to a server through a
+// TCP proxy, and checks that each container is reported with the address
+// of its direct peer: the server sees the proxy rather than the client, and
+// the proxy is both the server of the client and the client of the server.
+type ProxyHopTestSuite struct {
+	IntegrationTestSuiteBase
+	client proxyHopContainer
+	proxy  proxyHopContainer
+	server proxyHopContainer
+}
+
+func (s *ProxyHopTestSuite) SetupSuite() {
+	s.RegisterCleanup(proxyHopClientName, proxyHopProxyName, proxyHopServerName)
+	s.StartContainerStats()
+
+	s.StartCollector(false, &collector.StartupOptions{
+		Env: map[string]string{
+			"ROX_ENABLE_AFTERGLOW": "false",
+		},
+	})
+
+	serverImage := config.Images().ImageByKey("nginx")
+	proxyImage := config.Images().QaImageByKey("qa-socat")
+	clientImage := config.Images().QaImageByKey("qa-alpine-curl")
+	s.Require().NoError(s.PullImages(serverImage, proxyImage, clientImage))
+
+	s.server = s.launchProxyHopContainer(proxyHopServerName, serverImage)
+
+	// forwards every connection to the server, from its own address
+	s.proxy = s.launchProxyHopContainer(proxyHopProxyName, "--entrypoint", "socat", proxyImage,
+		fmt.Sprintf("TCP-LISTEN:%d,reuseaddr,fork", proxyHopPort),
+		fmt.Sprintf("TCP:%s:80", s.server.ip))
+
+	s.client = s.launchProxyHopContainer(proxyHopClientName, "--entrypoint", "/bin/sh", clientImage, "-c", "/bin/sleep 300")
+
+	s.Require().NoError(s.WaitForCollectorToTrack(s.client.id, 30*time.Second))
+
+	_, err := s.execContainer(proxyHopClientName, []string{"curl", "-s", "--fail",
+		fmt.Sprintf("http://%s:%d/", s.proxy.ip, proxyHopPort)})
+	s.Require().NoError(err)
+}
+
+func (s *ProxyHopTestSuite) TearDownSuite() {
+	s.StopCollector()
+	s.cleanupContainers(proxyHopClientName, proxyHopProxyName, proxyHopServerName)
+	s.WritePerfResults()
+}
+
+func (s *ProxyHopTestSuite) launchProxyHopContainer(name string, args ...string) proxyHopContainer {
+	containerID, err := s.launchContainer(name, args...)
+	s.Require().NoError(err)
+
+	ip, err := s.getIPAddress(name)
+	s.Require().NoError(err)
+
+	return proxyHopContainer{id: common.ContainerShortID(containerID), ip: ip}
+}
+
+func (s *ProxyHopTestSuite) TestClientSeesProxy() {
+	s.ExpectConnectionWithinScrape(s.client.id, func(conn types.NetworkInfo) bool {
+		return conn.RemoteAddress == fmt.Sprintf("%s:%d", s.proxy.ip, proxyHopPort) &&
+			conn.Role == "ROLE_CLIENT"
+	})
+}
+
+func (s *ProxyHopTestSuite) TestProxyDualRole() {
+	s.ExpectConnectionWithinScrape(s.proxy.id, func(conn types.NetworkInfo) bool {
+		return conn.LocalAddress == fmt.Sprintf(":%d", proxyHopPort) &&
+			conn.RemoteAddress == s.client.ip &&
+			conn.Role == "ROLE_SERVER"
+	})
+
+	s.ExpectConnectionWithinScrape(s.proxy.id, func(conn types.NetworkInfo) bool {
+		return conn.RemoteAddress == fmt.Sprintf("%s:80", s.server.ip) &&
+			conn.Role == "ROLE_CLIENT"
+	})
+}
+
+func (s *ProxyHopTestSuite) TestServerSeesProxy() {
+	s.ExpectConnectionWithinScrape(s.server.id, func(conn types.NetworkInfo) bool {
+		return conn.LocalAddress == ":80" &&
+			conn.RemoteAddress == s.proxy.ip &&
+			conn.Role == "ROLE_SERVER"
+	})
+
+	// the client is hidden behind the proxy
+	for _, conn := range s.Sensor().Connections(s.server.id) {
+		assert.NotEqual(s.T(), s.client.ip, conn.RemoteAddress, "server saw the client: %+v", conn)
+	}
+}
+
+func (s *ProxyHopTestSuite) TestTopology() {
+	// both hops are seen from both sides by the end of a scrape
+	common.Sleep(s.ScrapeInterval() + scrapeIntervalMargin)
+
+	graph := s.Sensor().NetworkGraph(map[string]string{
+		s.client.id: s.client.ip,
+		s.proxy.id:  s.proxy.ip,
+		s.server.id: s.server.ip,
+	})
+	assert.ElementsMatch(s.T(), []mock_sensor.NetworkEdge{
+		{Client: s.client.id, Server: s.proxy.id},
+		{Client: s.proxy.id, Server: s.server.id},
+	}, graph)
+}
@@ -8,6 +8,8 @@ import (
 	"github.com/stackrox/collector/integration-tests/pkg/collector"
 	"github.com/stackrox/collector/integration-tests/pkg/common"
 	"github.com/stackrox/collector/integration-tests/pkg/config"
+	"github.com/stackrox/collector/integration-tests/pkg/fixtures"
+	"github.com/stackrox/collector/integration-tests/pkg/types"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -53,28 +55,20 @@ func (s *RepeatedNetworkFlowTestSuite) SetupSuite() {
 
 	s.StartCollector(false, &collectorOptions)
 
-	image_store := config.Images()
-	scheduled_curls_image := image_store.QaImageByKey("qa-schedule-curls")
+	server := fixtures.NginxServer("nginx")
+	scheduled_curls_image := config.Images().QaImageByKey("qa-schedule-curls")
 
-	images := []string{
-		image_store.ImageByKey("nginx"),
-		scheduled_curls_image,
-	}
-
-	for _, image := range images {
-		err := s.executor.PullImage(image)
-		s.Require().NoError(err)
-	}
+	err := s.PullImages(server.Image, scheduled_curls_image)
+	s.Require().NoError(err)
 
-	// invokes default nginx
-	containerID, err := s.launchContainer("nginx", image_store.ImageByKey("nginx"))
+	containerID, err := s.Executor().StartContainer(server)
 	s.Require().NoError(err)
-	s.ServerContainer = containerID[0:12]
+	s.ServerContainer = common.ContainerShortID(containerID)
 
 	// invokes another container
 	containerID, err = s.launchContainer("nginx-curl", scheduled_curls_image, "sleep", "300")
 	s.Require().NoError(err)
-	s.ClientContainer = containerID[0:12]
+	s.ClientContainer = common.ContainerShortID(containerID)
 
 	s.ServerIP, err = s.getIPAddress("nginx")
 	s.Require().NoError(err)
@@ -84,17 +78,27 @@ func (s *RepeatedNetworkFlowTestSuite) SetupSuite() {
 
 	serverAddress := fmt.Sprintf("%s:%s", s.ServerIP, s.ServerPort)
 
-	numMetaIter := strconv.Itoa(s.NumMetaIter)
-	numIter := strconv.Itoa(s.NumIter)
-	sleepBetweenCurlTime := strconv.Itoa(s.SleepBetweenCurlTime)
-	sleepBetweenIterations := strconv.Itoa(s.SleepBetweenIterations)
-	_, err = s.execContainer("nginx-curl", []string{"/usr/bin/schedule-curls.sh", numMetaIter, numIter, sleepBetweenCurlTime, sleepBetweenIterations, serverAddress})
-
 	s.ClientIP, err = s.getIPAddress("nginx-curl")
 	s.Require().NoError(err)
 
-	totalTime := (s.SleepBetweenCurlTime*s.NumIter+s.SleepBetweenIterations)*s.NumMetaIter + s.AfterglowPeriod + 10
-	common.Sleep(time.Duration(totalTime) * time.Second)
+	sleepBetweenCurlTime := time.Duration(s.SleepBetweenCurlTime) * time.Second
+	for i := 0; i < s.NumMetaIter; i++ {
+		if i > 0 {
+			common.Sleep(time.Duration(s.SleepBetweenIterations) * time.Second)
+		}
+
+		err = s.GenerateConnections("nginx-curl", serverAddress, s.NumIter, sleepBetweenCurlTime)
+		s.Require().NoError(err)
+	}
+
+	// wait for the connections to outlive the afterglow period and be
+	// reported closed, which happens at the next scrape after that
+	closed := func(conn types.NetworkInfo) bool {
+		return !conn.IsActive()
+	}
+	timeout := time.Duration(s.AfterglowPeriod+2*s.ScrapeInterval+10) * time.Second
+	s.Require().True(s.Sensor().WaitConnectionsMatchingN(s.ServerContainer, timeout, s.ExpectedInactive, closed),
+		"connections were not reported closed within %s", timeout)
 }
 
 func (s *RepeatedNetworkFlowTestSuite) TearDownSuite() {
@@ -104,7 +108,11 @@ func (s *RepeatedNetworkFlowTestSuite) TearDownSuite() {
 }
 
 func (s *RepeatedNetworkFlowTestSuite) TestRepeatedNetworkFlow() {
-	networkInfos := s.Sensor().ExpectConnectionsN(s.T(), s.ServerContainer, 10*time.Second, s.ExpectedActive+s.ExpectedInactive)
+	// the count must not only be reached, but also not grow further with
+	// connections reported again after the afterglow period
+	s.Require().True(s.Sensor().ExpectConnectionsEventuallyExactly(s.T(), s.ServerContainer,
+		s.ExpectedActive+s.ExpectedInactive, 5*time.Second, 15*time.Second))
+	networkInfos := s.Sensor().Connections(s.ServerContainer)
 
 	observedActive := 0
 	observedInactive := 0
@@ -140,3 +148,18 @@ func (s *RepeatedNetworkFlowTestSuite) TestRepeatedNetworkFlow() {
 	actualClientEndpoint = networkInfos[0].LocalAddress
 	actualServerEndpoint = networkInfos[0].RemoteAddress
 }
+
+func (s *RepeatedNetworkFlowTestSuite) TestConnectionLifecycle() {
+	s.Sensor().ExpectConnectionsN(s.T(), s.ServerContainer, 10*time.Second, s.ExpectedActive+s.ExpectedInactive)
+
+	history := s.Sensor().ConnectionHistory(s.ServerContainer, func(conn types.NetworkInfo) bool {
+		return conn.LocalAddress == fmt.Sprintf(":%s", s.ServerPort) &&
+			conn.RemoteAddress == s.ClientIP
+	})
+	s.Require().NotEmpty(history)
+
+	if s.ExpectedActive > 0 {
+		assert.True(s.T(), history[0].Active, "connection was not first reported as active: %+v", history)
+	}
+	assert.False(s.T(), history[len(history)-1].Active, "connection was not reported as closed last: %+v", history)
+}
@@ -0,0 +1,80 @@
+package suites
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stackrox/collector/integration-tests/pkg/config"
+	"github.com/stackrox/collector/integration-tests/pkg/executor"
+)
+
+const (
+	replicaServerName = "replica-server"
+	replicaClientName = "replica-client"
+	replicaPort       = 8080
+)
+
+// ReplicaScaleTestSuite runs many identical socat servers and clients at
+// once, each client connecting to every server, and checks that collector
+// reports the flows of every container without dropping any.
+type ReplicaScaleTestSuite struct {
+	IntegrationTestSuiteBase
+	Replicas int
+	servers  []string
+	clients  []string
+}
+
+func (s *ReplicaScaleTestSuite) SetupSuite() {
+	names := []string{}
+	for i := 0; i < s.Replicas; i++ {
+		names = append(names, replicaName(replicaServerName, i), replicaName(replicaClientName, i))
+	}
+	s.RegisterCleanup(names...)
+	s.StartContainerStats()
+
+	s.StartCollector(false, nil)
+
+	image := config.Images().QaImageByKey("qa-socat")
+	s.Require().NoError(s.Executor().PullImage(image))
+
+	var err error
+	s.servers, err = s.LaunchReplicas(executor.ContainerStartConfig{
+		Name:    replicaServerName,
+		Image:   image,
+		Command: []string{"TCP-LISTEN:" + strconv.Itoa(replicaPort) + ",fork", "STDOUT"},
+	}, s.Replicas)
+	s.Require().NoError(err)
+
+	serverIPs := []string{}
+	for i := 0; i < s.Replicas; i++ {
+		ip, err := s.getIPAddress(replicaName(replicaServerName, i))
+		s.Require().NoError(err)
+		serverIPs = append(serverIPs, ip)
+	}
+
+	s.clients, err = s.LaunchReplicas(executor.ContainerStartConfig{
+		Name:       replicaClientName,
+		Image:      image,
+		Entrypoint: []string{"/bin/sh"},
+		Env:        map[string]string{"SERVERS": strings.Join(serverIPs, " ")},
+		Command: []string{"-c",
+			"for ip in $SERVERS; do echo hello | socat - TCP4:$ip:" + strconv.Itoa(replicaPort) + "; done; sleep 300"},
+	}, s.Replicas)
+	s.Require().NoError(err)
+}
+
+func (s *ReplicaScaleTestSuite) TearDownSuite() {
+	s.StopCollector()
+	s.cleanupContainers(s.servers...)
+	s.cleanupContainers(s.clients...)
+	s.WritePerfResults()
+}
+
+func (s *ReplicaScaleTestSuite) TestAllReplicasReported() {
+	timeout := 2 * time.Minute
+
+	// every server is connected to by every client, and vice versa
+	s.Sensor().ExpectConnectionsAcrossContainers(s.T(), s.servers, s.Replicas, timeout)
+	s.Sensor().ExpectConnectionsAcrossContainers(s.T(), s.clients, s.Replicas, timeout)
+}
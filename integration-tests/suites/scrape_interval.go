@@ -0,0 +1,99 @@
+package suites
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stackrox/collector/integration-tests/pkg/collector"
+	"github.com/stackrox/collector/integration-tests/pkg/common"
+	"github.com/stackrox/collector/integration-tests/pkg/config"
+	"github.com/stackrox/collector/integration-tests/pkg/types"
+)
+
+const (
+	scrapeIntervalServerName = "scrape-interval-server"
+	scrapeIntervalClientName = "scrape-interval-client"
+
+	// scrapeReportMargin is how long a report may take to reach the mock
+	// sensor once the scrape is done
+	scrapeReportMargin = time.Second
+)
+
+// ScrapeIntervalTestSuite checks that a connection is reported with every
+// scrape interval, and that the report latency is bounded by the interval.
+type ScrapeIntervalTestSuite struct {
+	IntegrationTestSuiteBase
+	serverIP        string
+	clientContainer string
+}
+
+func (s *ScrapeIntervalTestSuite) SetupSuite() {
+	s.RegisterCleanup(scrapeIntervalServerName, scrapeIntervalClientName)
+
+	serverImage := config.Images().ImageByKey("nginx")
+	clientImage := config.Images().QaImageByKey("qa-alpine-curl")
+	s.Require().NoError(s.Executor().PullImage(serverImage))
+	s.Require().NoError(s.Executor().PullImage(clientImage))
+
+	_, err := s.launchContainer(scrapeIntervalServerName, serverImage)
+	s.Require().NoError(err)
+
+	s.serverIP, err = s.getIPAddress(scrapeIntervalServerName)
+	s.Require().NoError(err)
+
+	containerID, err := s.launchContainer(scrapeIntervalClientName, clientImage, "sleep", "600")
+	s.Require().NoError(err)
+	s.clientContainer = common.ContainerShortID(containerID)
+}
+
+func (s *ScrapeIntervalTestSuite) TearDownSuite() {
+	s.cleanupContainers(scrapeIntervalServerName, scrapeIntervalClientName)
+	s.WritePerfResults()
+}
+
+func (s *ScrapeIntervalTestSuite) TestConnectionReported() {
+	options := collector.StartupOptions{
+		Config: map[string]any{
+			"turnOffScrape": false,
+		},
+	}
+
+	isServerConnection := func(conn types.NetworkInfo) bool {
+		return conn.RemoteAddress == fmt.Sprintf("%s:80", s.serverIP) &&
+			conn.Role == "ROLE_CLIENT"
+	}
+
+	s.ForEachScrapeInterval([]int{1, 2, 5}, options, func(interval time.Duration) {
+		err := s.WaitForCollectorToTrack(s.clientContainer, 30*time.Second)
+		s.Require().NoError(err)
+
+		sent := time.Now()
+		err = s.GenerateConnections(scrapeIntervalClientName, s.serverIP, 1, 0)
+		s.Require().NoError(err)
+
+		// the connection happened at some point while the command ran, which
+		// may take long with a remote executor, and is then reported on the
+		// first scrape after it is seen, so it never waits for longer than
+		// one interval after the command
+		commandLatency := time.Since(sent)
+		maxLatency := commandLatency + interval + scrapeReportMargin
+		s.AddMetric(fmt.Sprintf("command_latency_%s", interval), commandLatency.Seconds())
+
+		if !s.Sensor().ExpectConnectionMatch(s.T(), s.clientContainer, interval+scrapeReportMargin, isServerConnection) {
+			return
+		}
+
+		for _, event := range s.Sensor().ConnectionEvents(s.clientContainer) {
+			if isServerConnection(event.Connection) {
+				latency := event.Received.Sub(sent)
+				s.AddMetric(fmt.Sprintf("report_latency_%s", interval), latency.Seconds())
+
+				assert.LessOrEqual(s.T(), latency, maxLatency,
+					"connection reported later than the scrape interval allows, with a command latency of %s", commandLatency)
+				return
+			}
+		}
+	})
+}
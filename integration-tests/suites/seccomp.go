@@ -0,0 +1,105 @@
+package suites
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/stackrox/collector/integration-tests/pkg/common"
+	"github.com/stackrox/collector/integration-tests/pkg/config"
+	"github.com/stackrox/collector/integration-tests/pkg/executor"
+	"github.com/stackrox/collector/integration-tests/pkg/types"
+)
+
+const (
+	seccompServerName = "seccomp-server"
+	seccompClientName = "seccomp-client"
+)
+
+// seccompProfile denies a set of syscalls that are commonly blocked in
+// production profiles, none of which are needed by the workload itself.
+const seccompProfile = `{
+	"defaultAction": "SCMP_ACT_ALLOW",
+	"syscalls": [
+		{
+			"names": [
+				"ptrace", "personality", "mount", "umount2", "unshare",
+				"setns", "keyctl", "add_key", "request_key", "bpf",
+				"perf_event_open", "userfaultfd", "kexec_load", "reboot"
+			],
+			"action": "SCMP_ACT_ERRNO"
+		}
+	]
+}`
+
+// SeccompTestSuite checks that collector still reports processes and
+// connections from a workload confined by a restrictive seccomp profile.
+type SeccompTestSuite struct {
+	IntegrationTestSuiteBase
+	profilePath     string
+	serverIP        string
+	clientContainer string
+}
+
+func (s *SeccompTestSuite) SetupSuite() {
+	s.RegisterCleanup(seccompServerName, seccompClientName)
+	s.StartContainerStats()
+	s.StartCollector(false, nil)
+
+	// the profile is read by the runtime, on the host running the containers
+	output, err := s.Executor().Exec("mktemp", "/tmp/seccomp-XXXXXX")
+	s.Require().NoError(err)
+	s.profilePath = strings.TrimSpace(output)
+	_, err = s.Executor().ExecWithStdin(seccompProfile, "sh", "-c", "cat > "+s.profilePath)
+	s.Require().NoError(err)
+
+	serverImage := config.Images().ImageByKey("nginx")
+	clientImage := config.Images().QaImageByKey("qa-alpine-curl")
+	s.Require().NoError(s.Executor().PullImage(serverImage))
+	s.Require().NoError(s.Executor().PullImage(clientImage))
+
+	_, err = s.launchContainer(seccompServerName, serverImage)
+	s.Require().NoError(err)
+
+	s.serverIP, err = s.getIPAddress(seccompServerName)
+	s.Require().NoError(err)
+
+	containerID, err := s.Executor().StartContainer(executor.ContainerStartConfig{
+		Name:        seccompClientName,
+		Image:       clientImage,
+		Command:     []string{"sleep", "300"},
+		SecurityOpt: []string{"seccomp=" + s.profilePath},
+	})
+	s.Require().NoError(err)
+	s.clientContainer = common.ContainerShortID(containerID)
+
+	err = s.WaitForCollectorToTrack(s.clientContainer, 30*time.Second)
+	s.Require().NoError(err)
+
+	_, err = s.execContainer(seccompClientName, []string{"curl", "-s", s.serverIP})
+	s.Require().NoError(err)
+}
+
+func (s *SeccompTestSuite) TearDownSuite() {
+	s.StopCollector()
+	s.cleanupContainers(seccompServerName, seccompClientName)
+	s.Executor().Exec("rm", "-f", s.profilePath)
+	s.WritePerfResults()
+}
+
+func (s *SeccompTestSuite) TestProcessReported() {
+	s.Sensor().ExpectProcesses(s.T(), s.clientContainer, 30*time.Second, types.ProcessInfo{
+		Name:    "curl",
+		ExePath: "/usr/bin/curl",
+		Uid:     0,
+		Gid:     0,
+		Args:    fmt.Sprintf("-s %s", s.serverIP),
+	})
+}
+
+func (s *SeccompTestSuite) TestConnectionReported() {
+	s.ExpectConnectionWithinScrape(s.clientContainer, func(conn types.NetworkInfo) bool {
+		return conn.RemoteAddress == fmt.Sprintf("%s:80", s.serverIP) &&
+			conn.Role == "ROLE_CLIENT"
+	})
+}
@@ -0,0 +1,62 @@
+package suites
+
+import (
+	"time"
+
+	"github.com/stackrox/collector/integration-tests/pkg/collector"
+	"github.com/stackrox/collector/integration-tests/pkg/fixtures"
+	"github.com/stackrox/collector/integration-tests/pkg/mock_sensor"
+)
+
+// SelfExclusionTestSuite checks that collector doesn't report its own
+// activity, which would otherwise feed back into what it reports.
+type SelfExclusionTestSuite struct {
+	IntegrationTestSuiteBase
+}
+
+// selfExclusionServerName is a server whose endpoint the initial scrape
+// reports, showing that the scrape has completed.
+const selfExclusionServerName = "self-exclusion-nginx"
+
+func (s *SelfExclusionTestSuite) SetupSuite() {
+	s.RegisterCleanup(selfExclusionServerName)
+
+	server := fixtures.NginxServer(selfExclusionServerName)
+	s.Require().NoError(s.PullImages(server.Image))
+	_, err := s.Executor().StartContainer(server)
+	s.Require().NoError(err)
+
+	collectorOptions := collector.StartupOptions{
+		Config: map[string]any{
+			"turnOffScrape": false,
+		},
+		Env: map[string]string{
+			"ROX_PROCESSES_LISTENING_ON_PORT": "true",
+		},
+	}
+
+	s.StartCollector(false, &collectorOptions)
+}
+
+func (s *SelfExclusionTestSuite) TearDownSuite() {
+	s.StopCollector()
+	s.cleanupContainers(selfExclusionServerName)
+}
+
+func (s *SelfExclusionTestSuite) TestNoSelfReporting() {
+	collectorID := s.Collector().ContainerID()
+
+	// let collector scrape and report its own sockets, if it were to
+	s.Require().NoError(s.WaitForInitialScrape(time.Minute))
+
+	s.Assert().False(s.Sensor().HasSignalsFor(collectorID,
+		mock_sensor.ConnectionSignalType, mock_sensor.EndpointSignalType),
+		"collector reported its own connections %v or endpoints %v",
+		s.Sensor().Connections(collectorID), s.Sensor().Endpoints(collectorID))
+
+	// The canary processes exec'd by StartCollector in the collector
+	// container are reported, and are the only expected processes.
+	for _, process := range s.Sensor().Processes(collectorID) {
+		s.Assert().Equal("echo", process.Name, "collector reported its own process %+v", process)
+	}
+}
@@ -0,0 +1,91 @@
+package suites
+
+import (
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stackrox/collector/integration-tests/pkg/common"
+	"github.com/stackrox/collector/integration-tests/pkg/config"
+	"github.com/stackrox/collector/integration-tests/pkg/types"
+)
+
+//
+// Verifies that collector copes with a slow and erroring Sensor: it should
+// keep running, reconnect, and eventually deliver events once Sensor
+// recovers, rather than crashing or giving up.
+//
+
+type SensorFaultsTestSuite struct {
+	IntegrationTestSuiteBase
+	container string
+}
+
+func (s *SensorFaultsTestSuite) SetupSuite() {
+	s.RegisterCleanup("sensor-faults")
+	s.StartContainerStats()
+	s.StartCollector(false, nil)
+
+	image := config.Images().QaImageByKey("qa-alpine-curl")
+	s.Require().NoError(s.Executor().PullImage(image))
+
+	containerID, err := s.launchContainer("sensor-faults", image, "sleep", "300")
+	s.Require().NoError(err)
+	s.container = common.ContainerShortID(containerID)
+}
+
+func (s *SensorFaultsTestSuite) TearDownSuite() {
+	s.Sensor().ClearFaults()
+	s.StopCollector()
+	s.cleanupContainers("sensor-faults")
+	s.WritePerfResults()
+}
+
+func (s *SensorFaultsTestSuite) TestSlowAndErroringSensor() {
+	s.Sensor().InjectLatency(500 * time.Millisecond)
+	s.Sensor().InjectError(0.3)
+
+	_, err := s.execContainer("sensor-faults", []string{"/bin/sh", "-c", "/bin/ls"})
+	s.Require().NoError(err)
+
+	common.Sleep(10 * time.Second)
+
+	running, err := s.Executor().IsContainerRunning("collector")
+	s.Require().NoError(err)
+	assert.True(s.T(), running, "collector stopped running with a faulty sensor")
+
+	s.Sensor().ClearFaults()
+
+	_, err = s.execContainer("sensor-faults", []string{"/bin/sh", "-c", "/bin/uname"})
+	s.Require().NoError(err)
+
+	s.Sensor().ExpectProcesses(s.T(), s.container, 30*time.Second, types.ProcessInfo{
+		Name:    "uname",
+		ExePath: "/bin/uname",
+		Args:    "",
+	})
+}
+
+func (s *SensorFaultsTestSuite) TestRejectingSensor() {
+	s.Sensor().RejectAfter(0)
+
+	_, err := s.execContainer("sensor-faults", []string{"/bin/sh", "-c", "/bin/date"})
+	s.Require().NoError(err)
+
+	common.Sleep(10 * time.Second)
+
+	running, err := s.Executor().IsContainerRunning("collector")
+	s.Require().NoError(err)
+	assert.True(s.T(), running, "collector stopped running with a rejecting sensor")
+
+	s.Sensor().ClearFaults()
+
+	_, err = s.execContainer("sensor-faults", []string{"/bin/sh", "-c", "/bin/hostname"})
+	s.Require().NoError(err)
+
+	s.Sensor().ExpectProcesses(s.T(), s.container, 30*time.Second, types.ProcessInfo{
+		Name:    "hostname",
+		ExePath: "/bin/hostname",
+		Args:    "",
+	})
+}
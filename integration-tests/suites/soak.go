@@ -0,0 +1,99 @@
+package suites
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/stackrox/collector/integration-tests/pkg/collector"
+	"github.com/stackrox/collector/integration-tests/pkg/config"
+	"github.com/stackrox/collector/integration-tests/pkg/mock_sensor"
+)
+
+const (
+	defaultSoakDuration    = 5 * time.Minute
+	defaultSoakMaxRSSSlope = 512
+
+	soakSampleInterval = 10 * time.Second
+
+	// soakMaxReports bounds the reports kept by the mock sensor, which
+	// would otherwise grow for as long as the soak runs
+	soakMaxReports = 100000
+)
+
+// SoakTestSuite runs collector under steady berserker load for a long
+// time, sampling the RSS of the collector process, and fails if it trends
+// upward, to catch slow leaks that short benchmarks miss. The duration and
+// threshold are configurable, so that CI can run a short version and
+// nightly jobs a long one.
+type SoakTestSuite struct {
+	BenchmarkTestSuiteBase
+}
+
+func (s *SoakTestSuite) SetupSuite() {
+	s.RegisterCleanup("benchmark-processes", "benchmark-endpoints")
+	s.StartContainerStats()
+	s.Sensor().SetStoreLimit(soakMaxReports, mock_sensor.DropOldest)
+	s.StartCollector(false, nil)
+}
+
+func (s *SoakTestSuite) TearDownSuite() {
+	s.StopCollector()
+	s.cleanupContainers(s.loadContainers...)
+	s.WritePerfResults()
+}
+
+func (s *SoakTestSuite) TestSoak() {
+	duration := config.BenchmarksInfo().SoakDuration
+	if duration == 0 {
+		duration = defaultSoakDuration
+	}
+	maxSlope := float64(config.BenchmarksInfo().SoakMaxRSSSlope)
+	if maxSlope == 0 {
+		maxSlope = defaultSoakMaxRSSSlope
+	}
+
+	params := workloadParams()
+	s.workload = &params
+	fmt.Printf("Soaking for %s, workload scale %d\n", duration, params.Scale)
+
+	// the samples are recorded in the perf results, as a time series
+	stopSampling := s.SampleCollectorProcessStats(soakSampleInterval)
+
+	s.start = time.Now().UTC()
+	deadline := time.Now().Add(duration)
+	for time.Now().Before(deadline) {
+		s.runSoakIteration(params)
+	}
+	s.stop = time.Now().UTC()
+
+	stopSampling()
+
+	// a leak may show as collector being restarted, rather than as a trend
+	s.AssertCollectorUptimeAtLeast(duration)
+
+	slope, err := collector.RSSSlope(s.procStats)
+	s.Require().NoError(err)
+	s.AddMetric("collector_rss_slope_kib_per_min", slope)
+	s.AddMetric("sensor_dropped_reports", float64(s.Sensor().Metrics().DroppedReports))
+
+	s.Assert().LessOrEqual(slope, maxSlope,
+		"collector RSS grew by %.0f KiB/min over %s, which suggests a leak", slope, duration)
+}
+
+// runSoakIteration runs the berserker workloads to completion, so that they
+// can be restarted to keep the load steady.
+func (s *SoakTestSuite) runSoakIteration(params WorkloadParams) {
+	procContainerID, err := s.SpinBerserker("processes", params)
+	s.Require().NoError(err)
+
+	endpointsContainerID, err := s.SpinBerserker("endpoints", params)
+	s.Require().NoError(err)
+
+	_, err = s.waitForContainerToExit("berserker", procContainerID, time.Second, 0)
+	s.Require().NoError(err)
+	_, err = s.waitForContainerToExit("berserker", endpointsContainerID, time.Second, 0)
+	s.Require().NoError(err)
+
+	s.cleanupContainers(procContainerID, endpointsContainerID)
+	s.loadContainers = nil
+}
@@ -8,7 +8,7 @@ import (
 
 	"github.com/stackrox/collector/integration-tests/pkg/collector"
 	"github.com/stackrox/collector/integration-tests/pkg/common"
-	"github.com/stackrox/collector/integration-tests/pkg/config"
+	"github.com/stackrox/collector/integration-tests/pkg/fixtures"
 	"github.com/stackrox/collector/integration-tests/pkg/types"
 	"github.com/stretchr/testify/assert"
 )
@@ -44,11 +44,7 @@ func (s *SocatTestSuite) SetupSuite() {
 
 	s.StartCollector(false, &collectorOptions)
 
-	processImage := config.Images().QaImageByKey("qa-socat")
-
-	// the socat container only needs to exist long enough for use to run both
-	// socat commands. 300 seconds should be more than long enough.
-	containerID, err := s.launchContainer("socat", processImage, "TCP-LISTEN:80,fork", "STDOUT")
+	containerID, err := s.Executor().StartContainer(fixtures.SocatServer("socat", 80))
 	s.Require().NoError(err)
 
 	_, err = s.execContainer("socat", []string{"/bin/sh", "-c", "socat TCP-LISTEN:8080,fork STDOUT &"})
@@ -0,0 +1,89 @@
+package suites
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/stackrox/collector/integration-tests/pkg/collector"
+	"github.com/stackrox/collector/integration-tests/pkg/common"
+	"github.com/stackrox/collector/integration-tests/pkg/executor"
+	"github.com/stackrox/collector/integration-tests/pkg/fixtures"
+	"github.com/stackrox/collector/integration-tests/pkg/types"
+)
+
+const (
+	staticIPNetwork    = "static-ip-tests"
+	staticIPSubnet     = "172.31.250.0/24"
+	staticIPServerName = "static-ip-server"
+	staticIPServerIP   = "172.31.250.10"
+	staticIPClientName = "static-ip-client"
+	staticIPClientIP   = "172.31.250.20"
+)
+
+// StaticIPTestSuite assigns known addresses to a client and a server, so
+// that the connection between them can be checked against exact addresses
+// rather than the ones the runtime happened to assign.
+type StaticIPTestSuite struct {
+	IntegrationTestSuiteBase
+	serverContainer string
+	clientContainer string
+}
+
+func (s *StaticIPTestSuite) SetupSuite() {
+	s.RegisterCleanup(staticIPServerName, staticIPClientName)
+	s.StartContainerStats()
+
+	s.StartCollector(false, &collector.StartupOptions{
+		Env: map[string]string{
+			"ROX_ENABLE_AFTERGLOW": "false",
+		},
+	})
+
+	server := fixtures.NginxServer(staticIPServerName)
+	server.NetworkMode = staticIPNetwork
+	server.IPAddress = staticIPServerIP
+
+	client := fixtures.HTTPClient(staticIPClientName)
+	client.NetworkMode = staticIPNetwork
+	client.IPAddress = staticIPClientIP
+
+	s.Require().NoError(s.PullImages(server.Image, client.Image))
+
+	s.Require().NoError(s.Executor().CreateNetworkWithOptions(staticIPNetwork,
+		executor.NetworkOptions{Subnet: staticIPSubnet}))
+
+	containerID, err := s.Executor().StartContainer(server)
+	s.Require().NoError(err)
+	s.serverContainer = common.ContainerShortID(containerID)
+
+	containerID, err = s.Executor().StartContainer(client)
+	s.Require().NoError(err)
+	s.clientContainer = common.ContainerShortID(containerID)
+
+	s.Require().NoError(s.WaitForCollectorToTrack(s.clientContainer, 30*time.Second))
+
+	_, err = s.execContainer(staticIPClientName, fixtures.HTTPRequest(fmt.Sprintf("http://%s/", staticIPServerIP)))
+	s.Require().NoError(err)
+}
+
+func (s *StaticIPTestSuite) TearDownSuite() {
+	s.StopCollector()
+	s.cleanupContainers(staticIPServerName, staticIPClientName)
+	s.cleanupNetworks()
+	s.WritePerfResults()
+}
+
+func (s *StaticIPTestSuite) TestServerSeesClientAddress() {
+	s.ExpectConnectionWithinScrape(s.serverContainer, func(conn types.NetworkInfo) bool {
+		return conn.LocalAddress == fmt.Sprintf(":%d", fixtures.NginxPort) &&
+			conn.RemoteAddress == staticIPClientIP &&
+			conn.Role == "ROLE_SERVER"
+	})
+}
+
+func (s *StaticIPTestSuite) TestClientSeesServerAddress() {
+	s.ExpectConnectionWithinScrape(s.clientContainer, func(conn types.NetworkInfo) bool {
+		return conn.RemoteAddress == fmt.Sprintf("%s:%d", staticIPServerIP, fixtures.NginxPort) &&
+			conn.Role == "ROLE_CLIENT"
+	})
+}
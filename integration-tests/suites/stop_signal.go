@@ -0,0 +1,74 @@
+package suites
+
+import (
+	"time"
+
+	"github.com/stackrox/collector/integration-tests/pkg/config"
+	"github.com/stackrox/collector/integration-tests/pkg/executor"
+)
+
+const (
+	stopSignalName = "stop-signal"
+
+	// stopSignalScript only exits cleanly on SIGUSR1. As PID 1, the shell
+	// ignores SIGTERM, so a default stop times out and kills it.
+	stopSignalScript = "trap 'exit 0' USR1; while true; do sleep 1; done"
+
+	// stopSignalTimeout is well below the runtime's default stop timeout,
+	// after which the container would be killed.
+	stopSignalTimeout = 5 * time.Second
+)
+
+// StopSignalTestSuite checks that containers can be stopped with a signal
+// other than SIGTERM, for workloads that only shut down cleanly on it.
+type StopSignalTestSuite struct {
+	IntegrationTestSuiteBase
+}
+
+func (s *StopSignalTestSuite) SetupSuite() {
+	s.RegisterCleanup(stopSignalName)
+	s.Require().NoError(s.Executor().PullImage(config.Images().ImageByKey("busybox")))
+}
+
+func (s *StopSignalTestSuite) TearDownTest() {
+	s.cleanupContainers(stopSignalName)
+}
+
+func (s *StopSignalTestSuite) TestStopSignal() {
+	s.startWorkload("SIGUSR1")
+
+	start := time.Now()
+	_, err := s.Executor().StopContainer(stopSignalName)
+	s.Require().NoError(err)
+
+	s.assertStoppedGracefully(time.Since(start))
+}
+
+func (s *StopSignalTestSuite) TestStopContainerWithSignal() {
+	s.startWorkload("")
+
+	start := time.Now()
+	_, err := s.Executor().StopContainerWithSignal(stopSignalName, "SIGUSR1")
+	s.Require().NoError(err)
+
+	s.assertStoppedGracefully(time.Since(start))
+}
+
+func (s *StopSignalTestSuite) startWorkload(stopSignal string) {
+	_, err := s.Executor().StartContainer(executor.ContainerStartConfig{
+		Name:       stopSignalName,
+		Image:      config.Images().ImageByKey("busybox"),
+		Entrypoint: []string{"sh", "-c"},
+		Command:    []string{stopSignalScript},
+		StopSignal: stopSignal,
+	})
+	s.Require().NoError(err)
+}
+
+func (s *StopSignalTestSuite) assertStoppedGracefully(elapsed time.Duration) {
+	exitCode, signal, oomKilled, err := s.Executor().GetContainerExitReason(stopSignalName)
+	s.Require().NoError(err)
+	s.Assert().Zero(exitCode, "workload did not exit cleanly (%s)",
+		executor.DescribeExit(exitCode, signal, oomKilled))
+	s.Assert().Less(elapsed, stopSignalTimeout, "workload was not stopped by the signal")
+}
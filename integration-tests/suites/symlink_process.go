@@ -1,6 +1,7 @@
 package suites
 
 import (
+	"strings"
 	"time"
 
 	"github.com/stretchr/testify/assert"
@@ -72,3 +73,18 @@ func (s *SymbolicLinkProcessTestSuite) TestSymbolicLinkProcess() {
 	assert.Equal(s.T(), endpoints[0].Originator.ProcessArgs, lnProcess.Args)
 	assert.Equal(s.T(), 9092, endpoints[0].Address.Port)
 }
+
+// TestSymbolicLinkExePath checks that the exe path of the process, and of
+// the originator of its endpoint, is the target of the symlink it was
+// executed by.
+func (s *SymbolicLinkProcessTestSuite) TestSymbolicLinkExePath() {
+	output, err := s.execContainer("process-ports", []string{"readlink", "-f", "/plop"})
+	s.Require().NoError(err)
+	exePath := strings.TrimSpace(output)
+	s.Require().NotEqual("/plop", exePath, "plop is not a symlink")
+
+	s.Sensor().ExpectProcessExePath(s.T(), s.serverContainer, 10*time.Second, "plop", exePath)
+
+	endpoints := s.Sensor().ExpectEndpointsN(s.T(), s.serverContainer, 10*time.Second, 1)
+	assert.Equal(s.T(), exePath, endpoints[0].Originator.ProcessExecFilePath)
+}
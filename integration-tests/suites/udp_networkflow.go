@@ -0,0 +1,92 @@
+package suites
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/stackrox/collector/integration-tests/pkg/collector"
+	"github.com/stackrox/collector/integration-tests/pkg/common"
+	"github.com/stackrox/collector/integration-tests/pkg/config"
+)
+
+const (
+	udpServerName = "udp-server"
+	udpClientName = "udp-client"
+	udpPort       = 9090
+)
+
+// UdpNetworkFlow checks that datagram flows are reported, with the server
+// receiving through recvfrom and the client sending through sendto, and that
+// no UDP endpoint is reported, since collector only reports TCP listening
+// endpoints.
+type UdpNetworkFlow struct {
+	IntegrationTestSuiteBase
+	serverContainer string
+	clientContainer string
+	serverIP        string
+	clientIP        string
+}
+
+func (s *UdpNetworkFlow) SetupSuite() {
+	s.RegisterCleanup(udpServerName, udpClientName)
+	s.StartContainerStats()
+
+	collectorOptions := collector.StartupOptions{
+		Config: map[string]any{
+			"turnOffScrape": false,
+		},
+	}
+
+	s.StartCollector(false, &collectorOptions)
+
+	image := config.Images().QaImageByKey("qa-socat")
+	err := s.Executor().PullImage(image)
+	s.Require().NoError(err)
+
+	// socat's UDP-RECVFROM address reads each datagram with recvfrom
+	containerID, err := s.launchContainer(udpServerName, image,
+		fmt.Sprintf("UDP-RECVFROM:%d,fork", udpPort), "STDOUT")
+	s.Require().NoError(err)
+	s.serverContainer = common.ContainerShortID(containerID)
+
+	s.serverIP, err = s.getIPAddress(udpServerName)
+	s.Require().NoError(err)
+
+	// the client only needs to stay up for the test to exec into it
+	containerID, err = s.launchContainer(udpClientName, "--entrypoint", "/bin/sh", image, "-c", "sleep 300")
+	s.Require().NoError(err)
+	s.clientContainer = common.ContainerShortID(containerID)
+
+	s.clientIP, err = s.getIPAddress(udpClientName)
+	s.Require().NoError(err)
+
+	err = s.WaitForCollectorToTrack(s.serverContainer, 30*time.Second)
+	s.Require().NoError(err)
+}
+
+func (s *UdpNetworkFlow) TearDownSuite() {
+	s.StopCollector()
+	s.cleanupContainers(udpServerName, udpClientName)
+	s.WritePerfResults()
+}
+
+func (s *UdpNetworkFlow) TestRecvfrom() {
+	_, err := s.execContainer(udpClientName, []string{"/bin/sh", "-c",
+		fmt.Sprintf("echo hello | socat -u STDIN UDP-SENDTO:%s:%d", s.serverIP, udpPort)})
+	s.Require().NoError(err)
+
+	s.Sensor().ExpectUDPConnection(s.T(), s.clientContainer, s.clientIP, s.serverContainer, s.serverIP, 30*time.Second)
+}
+
+func (s *UdpNetworkFlow) TestEndpointsByProtocol() {
+	// the server's socket is bound for the whole suite, so it would be
+	// reported on any scrape
+	s.Sensor().ExpectNoEndpoints(s.T(), s.serverContainer, s.ScrapeInterval()+scrapeIntervalMargin)
+	s.Assert().Empty(s.Sensor().EndpointsByProtocol(s.serverContainer)["L4_PROTOCOL_UDP"],
+		"collector does not report UDP listening endpoints")
+
+	// the client only sends datagrams, so none of its sockets should be
+	// reported as endpoints across a full scrape
+	s.Sensor().ExpectNoEndpoints(s.T(), s.clientContainer, s.ScrapeInterval())
+	s.Assert().Empty(s.Sensor().EndpointsByProtocol(s.clientContainer))
+}